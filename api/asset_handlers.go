@@ -0,0 +1,245 @@
+package api
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+)
+
+// maxAssetUploadSize 单个上传附件的大小上限
+const maxAssetUploadSize = 100 << 20
+
+var (
+	assetService      *services.AssetService
+	assetBookmarkRepo *db.BookmarkRepository
+	pageArchiver      *services.PageArchiver
+)
+
+// SetAssetService 设置附件服务
+func SetAssetService(service *services.AssetService, bookmarkRepo *db.BookmarkRepository) {
+	assetService = service
+	assetBookmarkRepo = bookmarkRepo
+}
+
+// SetPageArchiver 设置网页快照服务
+func SetPageArchiver(archiver *services.PageArchiver) {
+	pageArchiver = archiver
+}
+
+// HandleBookmarkAssets 处理 /api/bookmarks/{id}/assets/ 下的所有请求 (Linkding 兼容)
+//
+//	GET    /api/bookmarks/{id}/assets/                     - 附件列表
+//	POST   /api/bookmarks/{id}/assets/upload/              - 上传附件 (multipart 字段 file)
+//	POST   /api/bookmarks/{id}/assets/snapshot/            - 后台生成网页快照
+//	GET    /api/bookmarks/{id}/assets/{asset_id}/          - 附件详情
+//	GET    /api/bookmarks/{id}/assets/{asset_id}/download/ - 下载附件
+//	DELETE /api/bookmarks/{id}/assets/{asset_id}/          - 删除附件
+func HandleBookmarkAssets(w http.ResponseWriter, r *http.Request) {
+	if assetService == nil {
+		http.Error(w, "附件服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	// 解析 {id}/assets/{rest}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bookmarks/"), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "assets" {
+		http.NotFound(w, r)
+		return
+	}
+	bookmarkID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		http.Error(w, "无效的ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := assetBookmarkRepo.GetActiveByID(bookmarkID); err != nil {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+
+	rest := parts[2:]
+	switch {
+	case len(rest) == 0:
+		if r.Method != "GET" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		listAssets(w, bookmarkID)
+
+	case len(rest) == 1 && rest[0] == "upload":
+		if r.Method != "POST" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		uploadAsset(w, r, bookmarkID)
+
+	case len(rest) == 1 && rest[0] == "snapshot":
+		if r.Method != "POST" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		createSnapshot(w, bookmarkID)
+
+	default:
+		assetID, err := strconv.Atoi(rest[0])
+		if err != nil || len(rest) > 2 || (len(rest) == 2 && rest[1] != "download") {
+			http.NotFound(w, r)
+			return
+		}
+		asset, err := assetService.Get(bookmarkID, assetID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "附件不存在", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("❌ 查询附件失败: %v", err)
+			http.Error(w, "查询失败", http.StatusInternalServerError)
+			return
+		}
+
+		switch {
+		case len(rest) == 2 && r.Method == "GET":
+			downloadAsset(w, r, asset)
+		case len(rest) == 1 && r.Method == "GET":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(asset)
+		case len(rest) == 1 && r.Method == "DELETE":
+			if err := assetService.Delete(asset); err != nil {
+				log.Printf("❌ 删除附件失败: %v", err)
+				http.Error(w, "删除失败", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// listAssets 返回书签的附件列表（linkding 分页格式）
+func listAssets(w http.ResponseWriter, bookmarkID int) {
+	assets, err := assetService.List(bookmarkID)
+	if err != nil {
+		log.Printf("❌ 查询附件失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":    len(assets),
+		"next":     nil,
+		"previous": nil,
+		"results":  assets,
+	})
+}
+
+// uploadAsset 流式保存上传的附件
+func uploadAsset(w http.ResponseWriter, r *http.Request, bookmarkID int) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAssetUploadSize)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "无效的表单数据", http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			http.Error(w, "缺少file字段", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "无效的表单数据", http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		name := part.FileName()
+		if name == "" {
+			name = "upload"
+		}
+		asset, err := assetService.Upload(bookmarkID, name, part.Header.Get("Content-Type"), part)
+		if err != nil {
+			log.Printf("❌ 上传附件失败: %v", err)
+			http.Error(w, "上传失败", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(asset)
+		return
+	}
+}
+
+// createSnapshot 创建待生成的网页快照附件，快照在后台生成
+func createSnapshot(w http.ResponseWriter, bookmarkID int) {
+	asset, err := pageArchiver.Submit(bookmarkID)
+	if err != nil {
+		log.Printf("❌ 创建网页快照失败: %v", err)
+		http.Error(w, "创建快照失败", http.StatusInternalServerError)
+		return
+	}
+	if asset == nil {
+		http.Error(w, "网页快照服务未启动", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(asset)
+}
+
+// downloadAsset 下载附件文件
+func downloadAsset(w http.ResponseWriter, r *http.Request, asset *models.BookmarkAsset) {
+	f, err := assetService.Open(asset)
+	if err != nil {
+		log.Printf("❌ 打开附件文件失败: %v", err)
+		http.Error(w, "附件文件不存在", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, "读取附件失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", asset.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", asset.DisplayName))
+
+	// 网页快照以 gzip 存储: 客户端支持时直接返回压缩内容，否则解压后返回
+	if assetService.IsCompressed(asset) {
+		w.Header().Set("Vary", "Accept-Encoding")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+			io.Copy(w, f)
+			return
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			log.Printf("❌ 解压附件失败: %v", err)
+			http.Error(w, "读取附件失败", http.StatusInternalServerError)
+			return
+		}
+		defer gz.Close()
+		io.Copy(w, gz)
+		return
+	}
+	http.ServeContent(w, r, "", stat.ModTime(), f)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"ai-bookmark-service/db"
+)
+
+// ParseBookmarkFilters 从查询参数构建书签过滤条件（列表、导出等接口共用）
+// q 支持搜索查询语言，语法错误时返回 *db.QueryError；sort 见 db.ParseBookmarkSort
+func ParseBookmarkFilters(query url.Values) (map[string]interface{}, error) {
+	filters := make(map[string]interface{})
+	if q := query.Get("q"); q != "" {
+		if _, err := db.ParseSearchQuery(q); err != nil {
+			return nil, err
+		}
+		filters["q"] = q
+	}
+	if query.Get("unread") == "true" {
+		filters["unread"] = true
+	}
+	if query.Get("shared") == "true" {
+		filters["shared"] = true
+	}
+	if sort := query.Get("sort"); sort != "" {
+		if _, err := db.ParseBookmarkSort(sort); err != nil {
+			return nil, err
+		}
+		filters["sort"] = sort
+	}
+	return filters, nil
+}
+
+// WriteFilterError 返回过滤参数错误，查询语法错误时附带出错记号和位置
+func WriteFilterError(w http.ResponseWriter, err error) {
+	var qerr *db.QueryError
+	if !errors.As(err, &qerr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":    "invalid_query",
+		"message":  qerr.Message,
+		"token":    qerr.Token,
+		"position": qerr.Position,
+	})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+)
+
+var bookmarkBulkService *services.BookmarkBulkService
+
+// SetBookmarkBulkService 设置书签批量操作服务
+func SetBookmarkBulkService(service *services.BookmarkBulkService) {
+	bookmarkBulkService = service
+}
+
+// POST /api/bookmarks/bulk/ - 批量操作书签
+// 请求体: {"ids": [1, 2] 或 "query": "tag:foo", "operation": "add_tags", "tags": [...], "folder_ids": [...]}
+func HandleBulkBookmarks(w http.ResponseWriter, r *http.Request) {
+	if bookmarkBulkService == nil {
+		http.Error(w, "批量操作服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	var req models.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+
+	report, err := bookmarkBulkService.Apply(&req)
+	if err != nil {
+		var reqErr *services.BulkRequestError
+		var queryErr *db.QueryError
+		switch {
+		case errors.As(err, &queryErr):
+			WriteFilterError(w, err)
+		case errors.As(err, &reqErr):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrAIDisabled):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			log.Printf("❌ 批量操作失败: %v", err)
+			http.Error(w, "批量操作失败", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+)
+
+var articleService *services.ArticleService
+
+// SetArticleService 设置书签正文服务
+func SetArticleService(service *services.ArticleService) {
+	articleService = service
+}
+
+// HandleBookmarkContent 处理书签正文（阅读模式）请求
+//
+//	GET  /api/bookmarks/{id}/content/                 - 正文 JSON，尚未提取时立即提取
+//	GET  /api/bookmarks/{id}/content/?format=markdown - 只返回 Markdown 正文
+//	GET  /api/bookmarks/{id}/content/?format=text     - 只返回纯文本正文
+//	POST /api/bookmarks/{id}/content/                 - 重新抓取并提取正文
+func HandleBookmarkContent(w http.ResponseWriter, r *http.Request) {
+	if articleService == nil {
+		http.Error(w, "正文服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bookmarks/"), "/"), "/")
+	if len(parts) != 2 || parts[1] != "content" {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		http.Error(w, "无效的ID", http.StatusBadRequest)
+		return
+	}
+
+	var article *models.Article
+	switch r.Method {
+	case "GET":
+		article, err = articleService.Get(id)
+	case "POST":
+		article, err = articleService.Extract(id)
+	default:
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ 提取书签正文失败 ID=%d: %v", id, err)
+		http.Error(w, "提取正文失败: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(article.Markdown))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(article.Text))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+)
+
+var duplicateFinder *services.DuplicateFinder
+
+// SetDuplicateFinder 设置重复书签查找服务
+func SetDuplicateFinder(finder *services.DuplicateFinder) {
+	duplicateFinder = finder
+}
+
+// HandleFindDuplicates 列出疑似重复的书签分组
+// GET /api/bookmarks/duplicates/?threshold=0.8 （threshold 为内容相似度阈值，0~1）
+func HandleFindDuplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := services.DefaultDuplicateThreshold
+	if s := r.URL.Query().Get("threshold"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 || v > 1 {
+			http.Error(w, "无效的 threshold 参数（应为 0~1）", http.StatusBadRequest)
+			return
+		}
+		threshold = v
+	}
+
+	groups, err := duplicateFinder.FindGroups(threshold)
+	if err != nil {
+		log.Printf("❌ 查找重复书签失败: %v", err)
+		http.Error(w, "查找失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":   len(groups),
+		"results": groups,
+	})
+}
+
+// HandleMergeDuplicates 合并重复书签，被合并的书签移入回收站
+// POST /api/bookmarks/duplicates/merge/ {"target_id": 1, "ids": [2, 3]}
+func HandleMergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	var req models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+
+	bookmark, err := duplicateFinder.Merge(&req)
+	var reqErr *services.DuplicateRequestError
+	switch {
+	case errors.As(err, &reqErr):
+		http.Error(w, reqErr.Message, http.StatusBadRequest)
+		return
+	case err == sql.ErrNoRows:
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("❌ 合并重复书签失败: %v", err)
+		http.Error(w, "合并失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookmark)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/services"
+)
+
+var bookmarkExporter *services.BookmarkExporter
+
+// SetBookmarkExporter 设置书签导出服务
+func SetBookmarkExporter(exporter *services.BookmarkExporter) {
+	bookmarkExporter = exporter
+}
+
+// GET /api/bookmarks/export/?format=html|json|csv|markdown - 导出书签
+// 支持与书签列表相同的过滤参数 (q 查询语言, unread, shared)
+func HandleExportBookmarks(w http.ResponseWriter, r *http.Request) {
+	if bookmarkExporter == nil {
+		http.Error(w, "导出服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = services.ExportFormatHTML
+	case "md":
+		format = services.ExportFormatMarkdown
+	}
+
+	info, ok := services.ExportFormatInfo[format]
+	if !ok {
+		http.Error(w, "不支持的导出格式: "+format, http.StatusBadRequest)
+		return
+	}
+
+	filters, err := ParseBookmarkFilters(r.URL.Query())
+	if err != nil {
+		WriteFilterError(w, err)
+		return
+	}
+	filename := fmt.Sprintf("bookmarks_%s.%s", time.Now().Format("2006-01-02"), info.Extension)
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// 响应头已发送，出错时只能记录日志并中断输出
+	if err := bookmarkExporter.Export(w, format, filters); err != nil {
+		log.Printf("❌ 导出书签失败: %v", err)
+	}
+}
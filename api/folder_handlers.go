@@ -150,20 +150,15 @@ func HandleGetFolderBookmarks(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// 分页参数
-	limit := 100
-	offset := 0
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
-	}
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
-	}
+	limit, offset := ParsePagination(r.URL.Query())
 	
-	bookmarks, total, err := folderRepo.GetBookmarks(id, limit, offset)
+	sort := r.URL.Query().Get("sort")
+	if _, err := db.ParseBookmarkSort(sort); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookmarks, total, err := folderRepo.GetBookmarks(id, limit, offset, sort)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/services"
+)
+
+var historyService *services.BookmarkHistoryService
+
+// SetBookmarkHistoryService 设置历史版本服务
+func SetBookmarkHistoryService(service *services.BookmarkHistoryService) {
+	historyService = service
+}
+
+// HandleBookmarkHistory 处理书签历史版本请求
+//
+//	GET  /api/bookmarks/{id}/history/                     - 历史版本及字段级差异（最新在前）
+//	POST /api/bookmarks/{id}/history/{revision_id}/revert/ - 回退到指定版本
+func HandleBookmarkHistory(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bookmarks/"), "/"), "/")
+	if len(parts) < 2 || parts[1] != "history" {
+		http.Error(w, "未找到", http.StatusNotFound)
+		return
+	}
+	bookmarkID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		http.Error(w, "无效的ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(parts) == 2:
+		if r.Method != "GET" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		listHistory(w, bookmarkID)
+	case len(parts) == 4 && parts[3] == "revert":
+		if r.Method != "POST" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		revisionID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			http.Error(w, "无效的版本ID", http.StatusBadRequest)
+			return
+		}
+		revertBookmark(w, bookmarkID, revisionID)
+	default:
+		http.Error(w, "未找到", http.StatusNotFound)
+	}
+}
+
+// listHistory 返回书签的历史版本
+func listHistory(w http.ResponseWriter, bookmarkID int) {
+	revisions, err := historyService.History(bookmarkID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 查询历史版本失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":   len(revisions),
+		"results": revisions,
+	})
+}
+
+// revertBookmark 回退书签到指定版本
+func revertBookmark(w http.ResponseWriter, bookmarkID, revisionID int) {
+	bookmark, err := historyService.Revert(bookmarkID, revisionID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "历史版本不存在", http.StatusNotFound)
+		return
+	}
+	if err == db.ErrDuplicateURL {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 回退书签失败: %v", err)
+		http.Error(w, "回退失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookmark)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"ai-bookmark-service/services"
+)
+
+var bookmarkImporter *services.BookmarkImporter
+
+// SetBookmarkImporter 设置书签导入服务
+func SetBookmarkImporter(importer *services.BookmarkImporter) {
+	bookmarkImporter = importer
+}
+
+// POST /api/bookmarks/import/ - 导入 Netscape HTML 书签文件
+// 支持 multipart/form-data（字段名 file）或直接以 text/html 作为请求体
+func HandleImportBookmarks(w http.ResponseWriter, r *http.Request) {
+	if bookmarkImporter == nil {
+		http.Error(w, "导入服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	var src io.Reader = r.Body
+	if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		// 流式读取上传文件，避免将大文件整体载入内存
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "无效的表单数据", http.StatusBadRequest)
+			return
+		}
+		src = nil
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, "无效的表单数据", http.StatusBadRequest)
+				return
+			}
+			if part.FormName() == "file" {
+				src = part
+				break
+			}
+		}
+		if src == nil {
+			http.Error(w, "缺少file字段", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := bookmarkImporter.ImportNetscape(src)
+	if err != nil {
+		log.Printf("❌ 导入书签失败: %v", err)
+		// 已导入的部分仍然返回报告
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  err.Error(),
+			"report": report,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/services"
+)
+
+var linkChecker *services.LinkChecker
+
+// SetLinkChecker 设置链接健康检查服务
+func SetLinkChecker(checker *services.LinkChecker) {
+	linkChecker = checker
+}
+
+// HandleLinks 处理链接健康检查请求
+//
+//	GET  /api/links/report/     - 链接健康报告: 统计数量，列出失效和已重定向的书签
+//	POST /api/links/check/      - 后台检查 {"ids": [1, 2]}，不传 ids 时检查最久未检查的一批书签
+//	GET  /api/links/{id}/       - 书签最近一次的检查结果
+//	POST /api/links/{id}/check/ - 立即检查书签并返回结果
+func HandleLinks(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/links"), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "report":
+		if r.Method != "GET" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		linkReport(w)
+	case len(parts) == 1 && parts[0] == "check":
+		if r.Method != "POST" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		submitLinkChecks(w, r)
+	default:
+		id, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) > 2 || (len(parts) == 2 && parts[1] != "check") {
+			http.Error(w, "未找到", http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 1 && r.Method == "GET":
+			getLinkCheck(w, id)
+		case len(parts) == 2 && r.Method == "POST":
+			checkLink(w, id)
+		default:
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// linkReport 返回链接健康报告
+func linkReport(w http.ResponseWriter) {
+	report, err := linkChecker.Report()
+	if err != nil {
+		log.Printf("❌ 生成链接健康报告失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// submitLinkChecks 将书签提交到后台检查
+func submitLinkChecks(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []int `json:"ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "无效的请求体", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var queued int
+	if len(req.IDs) > 0 {
+		queued = linkChecker.Submit(req.IDs)
+	} else {
+		queued = linkChecker.SubmitDue(time.Now())
+	}
+
+	log.Printf("🩺 提交链接检查: %d 个书签", queued)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"queued": queued,
+	})
+}
+
+// getLinkCheck 返回书签最近一次的检查结果
+func getLinkCheck(w http.ResponseWriter, id int) {
+	check, err := linkChecker.Get(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "该书签尚未检查", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 查询链接检查结果失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
+}
+
+// checkLink 立即检查书签链接
+func checkLink(w http.ResponseWriter, id int) {
+	check, err := linkChecker.Check(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 检查链接失败: %v", err)
+		http.Error(w, "检查失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
+}
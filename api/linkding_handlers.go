@@ -0,0 +1,171 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/services"
+)
+
+// linkdingVersion 对 linkding 客户端报告的兼容版本
+const linkdingVersion = "1.31.0"
+
+var (
+	linkdingTagRepo *db.TagRepository
+	webArchiver     *services.WebArchiver
+)
+
+// SetTagRepository 设置标签仓库
+func SetTagRepository(repo *db.TagRepository) {
+	linkdingTagRepo = repo
+}
+
+// SetWebArchiver 设置外部存档服务，用于在用户设置中报告存档集成状态
+func SetWebArchiver(archiver *services.WebArchiver) {
+	webArchiver = archiver
+}
+
+// HandleLinkdingTags 处理 /api/tags/ 和 /api/tags/{id}/ (Linkding 兼容)
+//
+//	GET  /api/tags/      - 分页获取标签
+//	POST /api/tags/      - 创建标签，已存在时返回现有标签
+//	GET  /api/tags/{id}/ - 获取单个标签
+func HandleLinkdingTags(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tags/"), "/")
+	if idStr != "" {
+		if r.Method != "GET" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		getTag(w, idStr)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		listTags(w, r)
+	case "POST":
+		createTag(w, r)
+	default:
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+	}
+}
+
+// listTags 分页获取标签
+func listTags(w http.ResponseWriter, r *http.Request) {
+	limit, offset := ParsePagination(r.URL.Query())
+	tags, total, err := linkdingTagRepo.ListPage(limit, offset)
+	if err != nil {
+		log.Printf("❌ 查询标签失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	next, previous := OffsetPageLinks(r, limit, offset, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":    total,
+		"next":     next,
+		"previous": previous,
+		"results":  tags,
+	})
+}
+
+// createTag 创建标签
+func createTag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "标签名称不能为空", http.StatusBadRequest)
+		return
+	}
+	if len(name) > 64 {
+		http.Error(w, "标签名称过长", http.StatusBadRequest)
+		return
+	}
+
+	id, err := linkdingTagRepo.GetOrCreate(name)
+	if err != nil {
+		log.Printf("❌ 创建标签失败: %v", err)
+		http.Error(w, "创建失败", http.StatusInternalServerError)
+		return
+	}
+	tag, err := linkdingTagRepo.GetByID(id)
+	if err != nil {
+		log.Printf("❌ 查询标签失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// getTag 获取单个标签
+func getTag(w http.ResponseWriter, idStr string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "无效的ID", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := linkdingTagRepo.GetByID(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "标签不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 查询标签失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+// GET /api/user/profile/ - 用户偏好设置 (Linkding 兼容)
+// LinkGenie 没有多用户和界面偏好设置，返回与功能相符的固定值
+func HandleUserProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	webArchiveIntegration := "disabled"
+	if webArchiver != nil && webArchiver.Enabled() {
+		webArchiveIntegration = "enabled"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"theme":                   "auto",
+		"bookmark_date_display":   "relative",
+		"bookmark_link_target":    "_blank",
+		"web_archive_integration": webArchiveIntegration,
+		"tag_search":              "lax",
+		"enable_sharing":          true,
+		"enable_public_sharing":   false,
+		"enable_favicons":         true,
+		"display_url":             false,
+		"permanent_notes":         false,
+		"search_preferences": map[string]string{
+			"sort":   "added_desc",
+			"shared": "off",
+			"unread": "off",
+		},
+		"version": linkdingVersion,
+	})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ai-bookmark-service/services"
+)
+
+var mediaCache *services.MediaCache
+
+// SetMediaCache 设置网站图标和预览图缓存
+func SetMediaCache(cache *services.MediaCache) {
+	mediaCache = cache
+}
+
+// HandleMedia 返回缓存的网站图标和预览图
+// GET /api/media/{favicons|previews}/{file}
+// 文件按内容哈希命名、内容不会变化，因此使用长期缓存
+func HandleMedia(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/media"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, contentType, err := mediaCache.Open(parts[0], parts[1])
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 打开缓存图片失败: %v", err)
+		http.Error(w, "读取失败", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, "读取失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+strings.TrimSuffix(parts[1], filepath.Ext(parts[1]))+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// SVG 可能包含脚本，禁止其在本站上下文中执行
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	http.ServeContent(w, r, "", stat.ModTime(), f)
+}
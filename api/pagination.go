@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// DefaultPageSize 未指定 limit 时的每页数量（与 linkding 一致）
+const DefaultPageSize = 100
+
+// MaxPageSize 每页数量上限，避免一次请求把整个书签库加载到内存
+const MaxPageSize = 1000
+
+// ParsePagination 解析 limit/offset 参数，limit 超过 MaxPageSize 时按上限处理
+func ParsePagination(query url.Values) (limit, offset int) {
+	limit, _ = strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = DefaultPageSize
+	} else if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	offset, _ = strconv.Atoi(query.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// OffsetPageLinks 生成 offset 分页的 next/previous 链接，没有对应页时为 nil
+func OffsetPageLinks(r *http.Request, limit, offset, count int) (next, previous interface{}) {
+	if offset+limit < count {
+		next = pageURL(r, func(q url.Values) {
+			q.Set("limit", strconv.Itoa(limit))
+			q.Set("offset", strconv.Itoa(offset+limit))
+		})
+	}
+	if offset > 0 {
+		previous = pageURL(r, func(q url.Values) {
+			q.Set("limit", strconv.Itoa(limit))
+			// 与 linkding 一致，回到第一页时省略 offset
+			if prev := offset - limit; prev > 0 {
+				q.Set("offset", strconv.Itoa(prev))
+			} else {
+				q.Del("offset")
+			}
+		})
+	}
+	return next, previous
+}
+
+// CursorPage 处理按 limit+1 条查询得到的键集分页结果
+// 返回截断后的当前页以及 next/previous 链接，没有对应页时为 nil
+func CursorPage(r *http.Request, cursor *db.BookmarkCursor, bookmarks []*models.Bookmark, limit int) ([]*models.Bookmark, interface{}, interface{}) {
+	hasMore := len(bookmarks) > limit
+	if hasMore {
+		// 多查的一条位于翻页方向的末端
+		if cursor.Backward {
+			bookmarks = bookmarks[1:]
+		} else {
+			bookmarks = bookmarks[:limit]
+		}
+	}
+	if len(bookmarks) == 0 {
+		return bookmarks, nil, nil
+	}
+
+	first, last := bookmarks[0], bookmarks[len(bookmarks)-1]
+	link := func(bm *models.Bookmark, backward bool) string {
+		c := &db.BookmarkCursor{DateAdded: bm.DateAdded, ID: bm.ID, Backward: backward}
+		return pageURL(r, func(q url.Values) {
+			q.Set("limit", strconv.Itoa(limit))
+			q.Set("cursor", c.Encode())
+			q.Del("offset")
+		})
+	}
+
+	var next, previous interface{}
+	if cursor.Backward {
+		if hasMore {
+			previous = link(first, true)
+		}
+		next = link(last, false)
+	} else {
+		if hasMore {
+			next = link(last, false)
+		}
+		if !cursor.IsZero() {
+			previous = link(first, true)
+		}
+	}
+	return bookmarks, next, previous
+}
+
+// pageURL 基于当前请求生成修改了查询参数的绝对 URL
+func pageURL(r *http.Request, modify func(url.Values)) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	q := r.URL.Query()
+	modify(q)
+	u := url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path, RawQuery: q.Encode()}
+	return u.String()
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+	"ai-bookmark-service/utils"
+)
+
+var (
+	scraperProfileRepo *db.ScraperProfileRepository
+	profileScraper     *services.ScraperService
+)
+
+// SetScraperProfiles 设置抓取配置仓库和使用这些配置的抓取服务
+func SetScraperProfiles(repo *db.ScraperProfileRepository, scraper *services.ScraperService) {
+	scraperProfileRepo = repo
+	profileScraper = scraper
+}
+
+// LoadScraperProfiles 从数据库加载抓取配置到抓取服务，启动时和每次修改后调用
+func LoadScraperProfiles() error {
+	profiles, err := scraperProfileRepo.List()
+	if err != nil {
+		return err
+	}
+	profileScraper.SetProfiles(profiles)
+	return nil
+}
+
+// HandleScraperProfiles 处理按域名的抓取配置请求
+//
+//	GET    /api/scraper/profiles/      - 列出所有抓取配置
+//	POST   /api/scraper/profiles/      - 创建抓取配置
+//	GET    /api/scraper/profiles/{id}/ - 获取抓取配置
+//	PUT    /api/scraper/profiles/{id}/ - 更新抓取配置（全部字段）
+//	DELETE /api/scraper/profiles/{id}/ - 删除抓取配置
+func HandleScraperProfiles(w http.ResponseWriter, r *http.Request) {
+	if scraperProfileRepo == nil || profileScraper == nil {
+		http.Error(w, "抓取配置服务未初始化", http.StatusInternalServerError)
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/scraper/profiles"), "/")
+	if rest == "" {
+		switch r.Method {
+		case "GET":
+			listScraperProfiles(w)
+		case "POST":
+			saveScraperProfile(w, r, 0)
+		default:
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(rest)
+	if err != nil {
+		http.Error(w, "未找到", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		getScraperProfile(w, id)
+	case "PUT":
+		saveScraperProfile(w, r, id)
+	case "DELETE":
+		deleteScraperProfile(w, id)
+	default:
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+	}
+}
+
+// listScraperProfiles 列出所有抓取配置
+func listScraperProfiles(w http.ResponseWriter) {
+	profiles, err := scraperProfileRepo.List()
+	if err != nil {
+		log.Printf("❌ 查询抓取配置失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":   len(profiles),
+		"results": profiles,
+	})
+}
+
+// getScraperProfile 获取单个抓取配置
+func getScraperProfile(w http.ResponseWriter, id int) {
+	profile, err := scraperProfileRepo.GetByID(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "抓取配置不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 查询抓取配置失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// saveScraperProfile 创建（id 为 0）或更新抓取配置，成功后重新加载到抓取服务
+func saveScraperProfile(w http.ResponseWriter, r *http.Request, id int) {
+	var profile models.ScraperProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "无效的请求体", http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateScraperProfile(&profile); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := profileScraper.CheckProxyURL(profile.ProxyURL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var saved *models.ScraperProfile
+	var err error
+	status := http.StatusOK
+	if id == 0 {
+		saved, err = scraperProfileRepo.Create(&profile)
+		status = http.StatusCreated
+	} else {
+		saved, err = scraperProfileRepo.Update(id, &profile)
+	}
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "抓取配置不存在", http.StatusNotFound)
+		return
+	case err == db.ErrScraperProfileExists:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("❌ 保存抓取配置失败: %v", err)
+		http.Error(w, "保存失败", http.StatusInternalServerError)
+		return
+	}
+
+	if err := LoadScraperProfiles(); err != nil {
+		log.Printf("⚠️ 重新加载抓取配置失败: %v", err)
+	}
+	log.Printf("🕷️ 抓取配置已保存: %s", saved.Domain)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// deleteScraperProfile 删除抓取配置，成功后重新加载到抓取服务
+func deleteScraperProfile(w http.ResponseWriter, id int) {
+	err := scraperProfileRepo.Delete(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "抓取配置不存在", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 删除抓取配置失败: %v", err)
+		http.Error(w, "删除失败", http.StatusInternalServerError)
+		return
+	}
+
+	if err := LoadScraperProfiles(); err != nil {
+		log.Printf("⚠️ 重新加载抓取配置失败: %v", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
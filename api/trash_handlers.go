@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/services"
+)
+
+var trashService *services.TrashService
+
+// SetTrashService 设置回收站服务
+func SetTrashService(service *services.TrashService) {
+	trashService = service
+}
+
+// HandleTrash 处理回收站请求
+//
+//	GET    /api/trash/              - 分页列出回收站中的书签（支持与书签列表相同的过滤参数）
+//	POST   /api/trash/purge/        - 立即清空回收站，?older_than_days=N 只清除删除超过 N 天的书签
+//	POST   /api/trash/{id}/restore/ - 恢复书签
+//	DELETE /api/trash/{id}/         - 彻底删除书签
+func HandleTrash(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/trash"), "/"), "/")
+
+	switch {
+	case parts[0] == "":
+		if r.Method != "GET" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		listTrash(w, r)
+	case len(parts) == 1 && parts[0] == "purge":
+		if r.Method != "POST" {
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+			return
+		}
+		purgeTrash(w, r)
+	default:
+		id, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) > 2 || (len(parts) == 2 && parts[1] != "restore") {
+			http.Error(w, "未找到", http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 2 && r.Method == "POST":
+			restoreBookmark(w, id)
+		case len(parts) == 1 && r.Method == "DELETE":
+			purgeBookmark(w, id)
+		default:
+			http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// listTrash 分页列出回收站中的书签
+func listTrash(w http.ResponseWriter, r *http.Request) {
+	filters, err := ParseBookmarkFilters(r.URL.Query())
+	if err != nil {
+		WriteFilterError(w, err)
+		return
+	}
+	limit, offset := ParsePagination(r.URL.Query())
+
+	bookmarks, total, err := trashService.List(limit, offset, filters)
+	if err != nil {
+		log.Printf("❌ 查询回收站失败: %v", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+
+	next, previous := OffsetPageLinks(r, limit, offset, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":    total,
+		"next":     next,
+		"previous": previous,
+		"results":  bookmarks,
+	})
+}
+
+// purgeTrash 立即清空回收站
+func purgeTrash(w http.ResponseWriter, r *http.Request) {
+	var before time.Time
+	if days := r.URL.Query().Get("older_than_days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			http.Error(w, "无效的 older_than_days 参数", http.StatusBadRequest)
+			return
+		}
+		before = time.Now().AddDate(0, 0, -n)
+	}
+
+	purged, err := trashService.PurgeBefore(before)
+	if err != nil {
+		log.Printf("❌ 清空回收站失败: %v", err)
+		http.Error(w, "清空失败", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🗑️ 清空回收站: %d 个书签", purged)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"purged": purged,
+	})
+}
+
+// restoreBookmark 恢复回收站中的书签
+func restoreBookmark(w http.ResponseWriter, id int) {
+	bookmark, err := trashService.Restore(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "回收站中不存在该书签", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 恢复书签失败: %v", err)
+		http.Error(w, "恢复失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookmark)
+}
+
+// purgeBookmark 彻底删除回收站中的书签
+func purgeBookmark(w http.ResponseWriter, id int) {
+	err := trashService.Purge(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "回收站中不存在该书签", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 彻底删除书签失败: %v", err)
+		http.Error(w, "删除失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
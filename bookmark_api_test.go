@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ai-bookmark-service/db"
+)
+
+func TestTrashedBookmarkIsNotFound(t *testing.T) {
+	h := newTestServer(t)
+
+	created := doJSON(t, h, "POST", "/api/bookmarks/", map[string]interface{}{"url": "https://example.com/trashed", "title": "原标题"}, http.StatusCreated)
+	id := int(created.(map[string]interface{})["id"].(float64))
+	path := fmt.Sprintf("/api/bookmarks/%d/", id)
+
+	if rec := doRequest(t, h, "DELETE", path, "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("删除书签状态码 = %d", rec.Code)
+	}
+
+	update := map[string]interface{}{"url": "https://example.com/trashed", "title": "新标题"}
+	for _, tc := range []struct {
+		method, path string
+		body         interface{}
+	}{
+		{"GET", path, nil},
+		{"PUT", path, update},
+		{"PATCH", path, map[string]interface{}{"title": "新标题"}},
+		{"PUT", "/api/bookmarks/9999/", update},
+		{"GET", path + "assets/", nil},
+		{"GET", path + "history/", nil},
+	} {
+		rec := doRequest(t, h, tc.method, tc.path, "application/json", jsonBody(t, tc.body))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s 状态码 = %d，期望 404: %s", tc.method, tc.path, rec.Code, rec.Body.String())
+		}
+	}
+
+	bm, err := bookmarkRepo.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bm.Title != "原标题" {
+		t.Errorf("回收站中的书签被修改: 标题 = %q", bm.Title)
+	}
+}
+
+func TestCanonicalDuplicates(t *testing.T) {
+	h := newTestServer(t)
+
+	original := "https://example.com/post?utm_source=feed"
+	first := doJSON(t, h, "POST", "/api/bookmarks/", map[string]interface{}{"url": original, "title": "旧标题"}, http.StatusCreated)
+	id := int(first.(map[string]interface{})["id"].(float64))
+
+	// 规范 URL 相同的重复提交更新原书签，但保留原始 URL
+	again := doJSON(t, h, "POST", "/api/bookmarks/", map[string]interface{}{"url": "https://EXAMPLE.com/post/?utm_medium=social", "title": "新标题"}, http.StatusCreated)
+	bm := again.(map[string]interface{})
+	if int(bm["id"].(float64)) != id || bm["url"] != original || bm["title"] != "新标题" {
+		t.Errorf("重复提交结果 = id %v url %v title %v", bm["id"], bm["url"], bm["title"])
+	}
+
+	other := doJSON(t, h, "POST", "/api/bookmarks/", map[string]interface{}{"url": "https://example.com/other"}, http.StatusCreated)
+	otherPath := fmt.Sprintf("/api/bookmarks/%d/", int(other.(map[string]interface{})["id"].(float64)))
+
+	for _, tc := range []struct {
+		method string
+		body   map[string]interface{}
+	}{
+		{"PUT", map[string]interface{}{"url": "https://example.com/post?fbclid=abc"}},
+		{"PATCH", map[string]interface{}{"url": "https://example.com/post/"}},
+		{"PATCH", map[string]interface{}{"url": original}},
+	} {
+		rec := doRequest(t, h, tc.method, otherPath, "application/json", jsonBody(t, tc.body))
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s %v 状态码 = %d，期望 409: %s", tc.method, tc.body["url"], rec.Code, rec.Body.String())
+		}
+	}
+
+	// URL 不变时不视为重复
+	doJSON(t, h, "PATCH", otherPath, map[string]interface{}{"url": "https://example.com/other", "title": "改名"}, http.StatusOK)
+}
+
+func TestListBookmarksInvalidQuery(t *testing.T) {
+	h := newTestServer(t)
+
+	rec := doRequest(t, h, "GET", "/api/bookmarks/?q="+url.QueryEscape("golang foo:bar"), "", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("状态码 = %d，期望 400: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("响应不是 JSON: %v: %s", err, rec.Body.String())
+	}
+	want := map[string]interface{}{
+		"error":    "invalid_query",
+		"message":  `未知的搜索操作符 "foo"`,
+		"token":    "foo:bar",
+		"position": float64(7),
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("响应 = %v，期望 %v", body, want)
+	}
+}
+
+// pageIDs 返回分页响应中的书签 ID 和 next/previous 链接
+func pageIDs(t *testing.T, h http.Handler, path string) (ids []int, next, previous string) {
+	t.Helper()
+	page := doJSON(t, h, "GET", path, nil, http.StatusOK).(map[string]interface{})
+	for _, item := range page["results"].([]interface{}) {
+		ids = append(ids, int(item.(map[string]interface{})["id"].(float64)))
+	}
+	next, _ = page["next"].(string)
+	previous, _ = page["previous"].(string)
+	return ids, next, previous
+}
+
+// requestPath 去掉分页链接中的协议和主机，检查其余过滤参数被保留
+func requestPath(t *testing.T, link string, keep url.Values) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for key, want := range keep {
+		if got := q[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("分页链接 %s 的 %s = %q，期望 %q", link, key, got, want)
+		}
+	}
+	return u.RequestURI()
+}
+
+func TestListBookmarksCursorPaging(t *testing.T) {
+	h := newTestServer(t)
+
+	// 7 个符合过滤条件的书签，其中 5 个添加时间相同；另有 1 个不符合条件
+	var want []int
+	for i := 0; i < 8; i++ {
+		create := map[string]interface{}{"url": fmt.Sprintf("https://example.com/%d", i), "unread": true, "tag_names": []string{"paging"}}
+		if i == 7 {
+			create["unread"] = false
+		}
+		created := doJSON(t, h, "POST", "/api/bookmarks/", create, http.StatusCreated)
+		id := int(created.(map[string]interface{})["id"].(float64))
+		dateAdded := "2025-01-01T00:00:00Z"
+		if i >= 5 {
+			dateAdded = fmt.Sprintf("2025-01-0%dT00:00:00Z", i-3)
+		}
+		if _, err := db.DB.Exec("UPDATE bookmarks SET date_added = ? WHERE id = ?", dateAdded, id); err != nil {
+			t.Fatal(err)
+		}
+		if i < 7 {
+			want = append(want, id)
+		}
+	}
+	// 期望顺序: 添加时间倒序，时间相同时 ID 倒序
+	want = []int{want[6], want[5], want[4], want[3], want[2], want[1], want[0]}
+
+	keep := url.Values{"q": {"tag:paging"}, "unread": {"true"}, "limit": {"3"}}
+	path := "/api/bookmarks/?limit=3&unread=true&cursor=&q=" + url.QueryEscape("tag:paging")
+
+	var got []int
+	var pages []string
+	for path != "" {
+		if len(pages) > 10 {
+			t.Fatal("分页没有结束")
+		}
+		pages = append(pages, path)
+		ids, next, _ := pageIDs(t, h, path)
+		got = append(got, ids...)
+		path = ""
+		if next != "" {
+			path = requestPath(t, next, keep)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("向后翻页得到 %v，期望 %v", got, want)
+	}
+	if len(pages) != 3 {
+		t.Errorf("共 %d 页，期望 3 页", len(pages))
+	}
+
+	// 从最后一页沿 previous 链接翻回第一页
+	ids, _, previous := pageIDs(t, h, pages[len(pages)-1])
+	back := ids
+	for previous != "" {
+		ids, _, previous = pageIDs(t, h, requestPath(t, previous, keep))
+		back = append(ids, back...)
+	}
+	if !reflect.DeepEqual(back, want) {
+		t.Errorf("向前翻页得到 %v，期望 %v", back, want)
+	}
+}
+
+func TestListBookmarksPaginationLimits(t *testing.T) {
+	h := newTestServer(t)
+	for i := 0; i < 2; i++ {
+		doJSON(t, h, "POST", "/api/bookmarks/", map[string]interface{}{"url": fmt.Sprintf("https://example.com/%d", i)}, http.StatusCreated)
+	}
+
+	// limit 超过上限时按 1000 处理
+	_, _, previous := pageIDs(t, h, "/api/bookmarks/?limit=5000&offset=1")
+	if u, err := url.Parse(previous); err != nil || u.Query().Get("limit") != "1000" {
+		t.Errorf("previous = %q，期望 limit=1000", previous)
+	}
+
+	// 游标分页只支持按添加时间倒序
+	for _, sort := range []string{"title", "added_asc", "modified"} {
+		rec := doRequest(t, h, "GET", "/api/bookmarks/?cursor=&sort="+sort, "", nil)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "游标分页") {
+			t.Errorf("cursor 与 sort=%s 同时使用: %d %s，期望 400", sort, rec.Code, rec.Body.String())
+		}
+	}
+	for _, sort := range []string{"added", "added_desc"} {
+		if rec := doRequest(t, h, "GET", "/api/bookmarks/?cursor=&sort="+sort, "", nil); rec.Code != http.StatusOK {
+			t.Errorf("cursor 与 sort=%s 同时使用的状态码 = %d，期望 200", sort, rec.Code)
+		}
+	}
+	if rec := doRequest(t, h, "GET", "/api/bookmarks/?cursor=invalid", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效游标的状态码 = %d，期望 400", rec.Code)
+	}
+}
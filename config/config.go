@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -12,17 +13,37 @@ import (
 
 // Config 应用配置
 type Config struct {
-	AIEnabled        bool
-	EnableAsyncAI    bool
-	AIAPIKey         string
-	AIEndpoint       string
-	AIModel          string
-	APIToken         string
-	DBPath           string
-	RateLimitEnabled bool
-	RateLimitPerIP   int
-	RateLimitBurst   int
-	AIWorkerCount    int
+	AIEnabled              bool
+	EnableAsyncAI          bool
+	AIAPIKey               string
+	AIEndpoint             string
+	AIModel                string
+	APIToken               string
+	DBPath                 string
+	RateLimitEnabled       bool
+	RateLimitPerIP         int
+	RateLimitBurst         int
+	AIWorkerCount          int
+	AssetsDir              string // 书签附件（上传文件、网页快照）存储目录
+	MediaDir               string // 网站图标和预览图缓存目录
+	PublicURL              string // 服务对外访问地址，用于生成缓存图片的绝对地址，为空时使用相对路径
+	AutoSnapshot           bool   // 新建书签时自动保存网页快照
+	SiteDefaultTags        bool   // 为 GitHub、YouTube、arXiv 等网站的书签自动添加默认标签
+	ScraperTimeout         int    // 抓取网页的超时时间（秒）
+	ScraperCheckTimeout    int    // 扩展检查接口预填充元数据时的抓取超时（秒）
+	ScraperHostConcurrency int    // 同一主机同时进行的抓取请求数
+	ScraperRespectRobots   bool   // 抓取网页前检查 robots.txt
+	ScraperCacheTTL        int    // 网页元数据缓存时间（分钟），0 表示不缓存
+	ScraperAllowedHosts    string // 允许抓取的内网主机（逗号分隔的主机名、*.域名、IP 或 CIDR），默认禁止访问本机和内网地址
+	ScraperMaxRedirects    int    // 抓取时最多跟随的重定向次数
+	ScraperMaxBodyMB       int    // 抓取时单个响应的最大大小（MB）
+	CanonicalRules         string // 按域名的 URL 规范化规则（JSON），补充或覆盖内置规则
+	ArchiveProvider        string // 外部存档服务: none | wayback | generic
+	ArchiveEndpoint        string // generic 存档服务的提交地址
+	ArchiveAPIKey          string // 存档服务凭据（wayback 为 "access:secret" 形式的 S3 密钥）
+	TrashRetention         int    // 回收站保留天数，超期自动清除，0 表示不自动清除
+	LinkCheckHours         int    // 链接健康检查间隔（小时），0 表示不自动检查
+	LinkCheckWorkers       int    // 链接健康检查并发数
 }
 
 // Load 加载配置（从 .env 文件和环境变量）
@@ -31,19 +52,41 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		AIEnabled:        getEnvBool("AI_ENABLED", false),
-		EnableAsyncAI:    getEnvBool("ENABLE_ASYNC_AI", true),
-		AIAPIKey:         getEnv("AI_API_KEY", ""),
-		AIEndpoint:       getEnv("AI_ENDPOINT", "https://api.openai.com/v1/chat/completions"),
-		AIModel:          getEnv("AI_MODEL", "gpt-3.5-turbo"),
-		APIToken:         getEnv("API_TOKEN", "your-secret-token-here"),
-		DBPath:           parseDBPath(getEnv("DATABASE_URL", "bookmarks.db")),
-		RateLimitEnabled: getEnvBool("RATE_LIMIT_ENABLED", true),
-		RateLimitPerIP:   getEnvInt("RATE_LIMIT_PER_IP", 60),
-		RateLimitBurst:   getEnvInt("RATE_LIMIT_BURST", 10),
-		AIWorkerCount:    getEnvInt("AI_WORKER_COUNT", 5),
+		AIEnabled:              getEnvBool("AI_ENABLED", false),
+		EnableAsyncAI:          getEnvBool("ENABLE_ASYNC_AI", true),
+		AIAPIKey:               getEnv("AI_API_KEY", ""),
+		AIEndpoint:             getEnv("AI_ENDPOINT", "https://api.openai.com/v1/chat/completions"),
+		AIModel:                getEnv("AI_MODEL", "gpt-3.5-turbo"),
+		APIToken:               getEnv("API_TOKEN", "your-secret-token-here"),
+		DBPath:                 parseDBPath(getEnv("DATABASE_URL", "bookmarks.db")),
+		RateLimitEnabled:       getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitPerIP:         getEnvInt("RATE_LIMIT_PER_IP", 60),
+		RateLimitBurst:         getEnvInt("RATE_LIMIT_BURST", 10),
+		AIWorkerCount:          getEnvInt("AI_WORKER_COUNT", 5),
+		PublicURL:              getEnv("PUBLIC_URL", ""),
+		AutoSnapshot:           getEnvBool("AUTO_SNAPSHOT", true),
+		SiteDefaultTags:        getEnvBool("SITE_DEFAULT_TAGS", true),
+		ScraperTimeout:         getEnvInt("SCRAPER_TIMEOUT", 20),
+		ScraperCheckTimeout:    getEnvInt("SCRAPER_CHECK_TIMEOUT", 5),
+		ScraperHostConcurrency: getEnvInt("SCRAPER_HOST_CONCURRENCY", 2),
+		ScraperRespectRobots:   getEnvBool("SCRAPER_RESPECT_ROBOTS", false),
+		ScraperCacheTTL:        getEnvInt("SCRAPER_CACHE_TTL_MINUTES", 10),
+		ScraperAllowedHosts:    getEnv("SCRAPER_ALLOWED_HOSTS", ""),
+		ScraperMaxRedirects:    getEnvInt("SCRAPER_MAX_REDIRECTS", 5),
+		ScraperMaxBodyMB:       getEnvInt("SCRAPER_MAX_BODY_MB", 10),
+		CanonicalRules:         getEnv("CANONICAL_RULES", ""),
+		ArchiveProvider:        strings.ToLower(getEnv("ARCHIVE_PROVIDER", "none")),
+		ArchiveEndpoint:        getEnv("ARCHIVE_ENDPOINT", ""),
+		ArchiveAPIKey:          getEnv("ARCHIVE_API_KEY", ""),
+		TrashRetention:         getEnvInt("TRASH_RETENTION_DAYS", 30),
+		LinkCheckHours:         getEnvInt("LINK_CHECK_INTERVAL_HOURS", 168),
+		LinkCheckWorkers:       getEnvInt("LINK_CHECK_WORKERS", 4),
 	}
 
+	// 附件默认与数据库放在同一目录，便于一起持久化
+	cfg.AssetsDir = getEnv("ASSETS_DIR", filepath.Join(filepath.Dir(cfg.DBPath), "assets"))
+	cfg.MediaDir = getEnv("MEDIA_DIR", filepath.Join(filepath.Dir(cfg.DBPath), "media"))
+
 	return cfg, nil
 }
 
@@ -150,5 +193,25 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("RATE_LIMIT_PER_IP 必须大于 0")
 	}
 
+	if c.ScraperTimeout <= 0 || c.ScraperCheckTimeout <= 0 {
+		return fmt.Errorf("SCRAPER_TIMEOUT 和 SCRAPER_CHECK_TIMEOUT 必须大于 0")
+	}
+	if c.ScraperHostConcurrency <= 0 {
+		return fmt.Errorf("SCRAPER_HOST_CONCURRENCY 必须大于 0")
+	}
+	if c.ScraperMaxRedirects < 0 || c.ScraperMaxBodyMB <= 0 {
+		return fmt.Errorf("SCRAPER_MAX_REDIRECTS 不能小于 0，SCRAPER_MAX_BODY_MB 必须大于 0")
+	}
+
+	switch c.ArchiveProvider {
+	case "none", "wayback":
+	case "generic":
+		if c.ArchiveEndpoint == "" {
+			return fmt.Errorf("ARCHIVE_PROVIDER=generic 时必须设置 ARCHIVE_ENDPOINT")
+		}
+	default:
+		return fmt.Errorf("不支持的 ARCHIVE_PROVIDER: %s（可选 none、wayback、generic）", c.ArchiveProvider)
+	}
+
 	return nil
 }
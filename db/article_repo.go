@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// ArticleRepository 书签正文（阅读模式内容）数据库操作
+type ArticleRepository struct {
+	db *sql.DB
+}
+
+// NewArticleRepository 创建正文仓库
+func NewArticleRepository() *ArticleRepository {
+	return &ArticleRepository{db: DB}
+}
+
+// Save 保存书签正文，已存在时覆盖
+func (r *ArticleRepository) Save(article *models.Article) error {
+	article.DateExtracted = time.Now().UTC()
+	_, err := r.db.Exec(`
+		INSERT OR REPLACE INTO bookmark_contents (bookmark_id, title, byline, excerpt, text, markdown, word_count, date_extracted)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		article.BookmarkID, article.Title, article.Byline, article.Excerpt, article.Text, article.Markdown, article.WordCount,
+		article.DateExtracted.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("保存书签正文失败: %w", err)
+	}
+	return nil
+}
+
+// Get 获取书签正文，未提取时返回 sql.ErrNoRows
+func (r *ArticleRepository) Get(bookmarkID int) (*models.Article, error) {
+	a := &models.Article{BookmarkID: bookmarkID}
+	err := r.db.QueryRow(
+		"SELECT title, byline, excerpt, text, markdown, word_count, date_extracted FROM bookmark_contents WHERE bookmark_id = ?",
+		bookmarkID,
+	).Scan(&a.Title, &a.Byline, &a.Excerpt, &a.Text, &a.Markdown, &a.WordCount, &a.DateExtracted)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// AssetRepository 书签附件数据库操作
+type AssetRepository struct {
+	db *sql.DB
+}
+
+// NewAssetRepository 创建附件仓库
+func NewAssetRepository() *AssetRepository {
+	return &AssetRepository{db: DB}
+}
+
+// assetColumns 附件查询列，与 scanAsset 对应
+const assetColumns = "id, bookmark_id, asset_type, content_type, display_name, file, file_size, status, date_created"
+
+// Create 创建附件记录
+func (r *AssetRepository) Create(asset *models.BookmarkAsset) (*models.BookmarkAsset, error) {
+	now := time.Now().UTC()
+	result, err := r.db.Exec(
+		"INSERT INTO bookmark_assets (bookmark_id, asset_type, content_type, display_name, file, file_size, status, date_created) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		asset.BookmarkID, asset.AssetType, asset.ContentType, asset.DisplayName, asset.File, asset.FileSize, asset.Status, now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建附件失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取附件ID失败: %w", err)
+	}
+
+	created := *asset
+	created.ID = int(id)
+	created.DateCreated = now
+	return &created, nil
+}
+
+// Update 更新附件的文件信息和状态
+func (r *AssetRepository) Update(asset *models.BookmarkAsset) error {
+	_, err := r.db.Exec(
+		"UPDATE bookmark_assets SET content_type = ?, display_name = ?, file = ?, file_size = ?, status = ? WHERE id = ?",
+		asset.ContentType, asset.DisplayName, asset.File, asset.FileSize, asset.Status, asset.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("更新附件失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 获取书签下的指定附件
+func (r *AssetRepository) GetByID(bookmarkID, id int) (*models.BookmarkAsset, error) {
+	row := r.db.QueryRow("SELECT "+assetColumns+" FROM bookmark_assets WHERE id = ? AND bookmark_id = ?", id, bookmarkID)
+	return scanAsset(row)
+}
+
+// ListByBookmark 获取书签的所有附件（按创建时间倒序）
+func (r *AssetRepository) ListByBookmark(bookmarkID int) ([]*models.BookmarkAsset, error) {
+	rows, err := r.db.Query("SELECT "+assetColumns+" FROM bookmark_assets WHERE bookmark_id = ? ORDER BY date_created DESC, id DESC", bookmarkID)
+	if err != nil {
+		return nil, fmt.Errorf("查询附件失败: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []*models.BookmarkAsset{}
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
+
+// Delete 删除附件记录
+func (r *AssetRepository) Delete(id int) error {
+	if _, err := r.db.Exec("DELETE FROM bookmark_assets WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除附件失败: %w", err)
+	}
+	return nil
+}
+
+// scanAsset 扫描单条附件记录
+func scanAsset(row interface{ Scan(...interface{}) error }) (*models.BookmarkAsset, error) {
+	var a models.BookmarkAsset
+	err := row.Scan(&a.ID, &a.BookmarkID, &a.AssetType, &a.ContentType, &a.DisplayName, &a.File, &a.FileSize, &a.Status, &a.DateCreated)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
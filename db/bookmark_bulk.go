@@ -0,0 +1,151 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// ListIDs 返回符合过滤条件的全部书签ID（按添加时间倒序）
+func (r *BookmarkRepository) ListIDs(filters map[string]interface{}) ([]int, error) {
+	join, where, args, err := buildBookmarkFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT b.id FROM bookmarks b"+join+where+" ORDER BY b.date_added DESC, b.id DESC", args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询书签ID失败: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// BulkApply 在单个事务中对每个书签执行 fn
+// 每个书签使用独立的 SAVEPOINT，单个失败只回滚该书签的修改
+func (r *BookmarkRepository) BulkApply(ids []int, fn func(tx *sql.Tx, id int) error) ([]*models.BulkItemResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]*models.BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		res := &models.BulkItemResult{ID: id, Status: models.BulkStatusOK}
+
+		var exists int
+		err := tx.QueryRow("SELECT COUNT(*) FROM bookmarks WHERE id = ? AND deleted_at IS NULL", id).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("查询书签失败: %w", err)
+		}
+		if exists == 0 {
+			res.Status = models.BulkStatusNotFound
+			results = append(results, res)
+			continue
+		}
+
+		if _, err := tx.Exec("SAVEPOINT bulk_item"); err != nil {
+			return nil, fmt.Errorf("创建保存点失败: %w", err)
+		}
+		err = fn(tx, id)
+		if err == nil {
+			err = r.recordRevisionTx(tx, id)
+		}
+		if err != nil {
+			if _, rbErr := tx.Exec("ROLLBACK TO bulk_item"); rbErr != nil {
+				return nil, fmt.Errorf("回滚保存点失败: %w", rbErr)
+			}
+			log.Printf("⚠️ 批量操作失败: ID=%d, 错误: %v", id, err)
+			res.Status = models.BulkStatusFailed
+			res.Error = err.Error()
+		}
+		if _, err := tx.Exec("RELEASE bulk_item"); err != nil {
+			return nil, fmt.Errorf("释放保存点失败: %w", err)
+		}
+		results = append(results, res)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	return results, nil
+}
+
+// AddTagsTx 在事务中为书签追加标签
+func (r *BookmarkRepository) AddTagsTx(tx *sql.Tx, id int, tagNames []string) error {
+	for _, tagName := range tagNames {
+		tagID, err := r.getOrCreateTagTx(tx, tagName)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id) VALUES (?, ?)", id, tagID); err != nil {
+			return fmt.Errorf("关联标签失败: %w", err)
+		}
+	}
+	return touchBookmarkTx(tx, id)
+}
+
+// RemoveTagsTx 在事务中移除书签的指定标签
+func (r *BookmarkRepository) RemoveTagsTx(tx *sql.Tx, id int, tagNames []string) error {
+	for _, tagName := range tagNames {
+		if _, err := tx.Exec(
+			"DELETE FROM bookmark_tags WHERE bookmark_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ? COLLATE NOCASE)",
+			id, tagName,
+		); err != nil {
+			return fmt.Errorf("移除标签失败: %w", err)
+		}
+	}
+	return touchBookmarkTx(tx, id)
+}
+
+// bookmarkFlagColumns 可批量设置的布尔字段
+var bookmarkFlagColumns = map[string]bool{
+	"unread":      true,
+	"is_favorite": true,
+	"shared":      true,
+	"is_archived": true,
+}
+
+// SetFlagTx 在事务中设置书签的布尔字段（unread / is_favorite / shared / is_archived）
+func (r *BookmarkRepository) SetFlagTx(tx *sql.Tx, id int, column string, value bool) error {
+	if !bookmarkFlagColumns[column] {
+		return fmt.Errorf("不支持的字段: %s", column)
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.Exec("UPDATE bookmarks SET "+column+" = ?, date_modified = ? WHERE id = ?", value, now, id); err != nil {
+		return fmt.Errorf("更新书签失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteTx 在事务中将书签移入回收站
+func (r *BookmarkRepository) DeleteTx(tx *sql.Tx, id int) error {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.Exec("UPDATE bookmarks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, id); err != nil {
+		return fmt.Errorf("删除书签失败: %w", err)
+	}
+	return nil
+}
+
+// touchBookmarkTx 更新书签的修改时间
+func touchBookmarkTx(tx *sql.Tx, id int) error {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.Exec("UPDATE bookmarks SET date_modified = ? WHERE id = ?", now, id); err != nil {
+		return fmt.Errorf("更新修改时间失败: %w", err)
+	}
+	return nil
+}
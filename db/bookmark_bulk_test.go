@@ -0,0 +1,91 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ai-bookmark-service/models"
+)
+
+func TestBulkApplyRollsBackFailedItem(t *testing.T) {
+	initTestDB(t)
+	repo := NewBookmarkRepository()
+
+	ids := make([]int, 3)
+	for i := range ids {
+		bm, err := repo.Create(&models.BookmarkCreate{URL: fmt.Sprintf("https://example.com/%d", i), TagNames: []string{"old"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = bm.ID
+	}
+
+	// 第二个书签修改后才失败，它的所有修改都应回滚
+	results, err := repo.BulkApply(append(ids, 9999), func(tx *sql.Tx, id int) error {
+		if err := repo.AddTagsTx(tx, id, []string{"new"}); err != nil {
+			return err
+		}
+		if err := repo.SetFlagTx(tx, id, "is_favorite", true); err != nil {
+			return err
+		}
+		if id == ids[1] {
+			return errors.New("模拟失败")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStatus := []string{models.BulkStatusOK, models.BulkStatusFailed, models.BulkStatusOK, models.BulkStatusNotFound}
+	if len(results) != len(wantStatus) {
+		t.Fatalf("返回 %d 个结果，期望 %d 个", len(results), len(wantStatus))
+	}
+	for i, res := range results {
+		if res.Status != wantStatus[i] {
+			t.Errorf("结果 %d: ID=%d 状态=%q，期望 %q", i, res.ID, res.Status, wantStatus[i])
+		}
+	}
+	if results[1].Error != "模拟失败" {
+		t.Errorf("失败原因 = %q", results[1].Error)
+	}
+
+	for i, id := range ids {
+		bm, err := repo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		failed := i == 1
+		if bm.IsFavorite == failed || (len(bm.TagNames) == 2) == failed {
+			t.Errorf("书签 %d: 收藏=%v 标签=%v，失败=%v", id, bm.IsFavorite, bm.TagNames, failed)
+		}
+	}
+	// 失败的书签不记录历史版本
+	if rev := lastRevision(t, repo, ids[1]); rev.Snapshot.IsFavorite {
+		t.Error("回滚的修改被记录到历史版本")
+	}
+}
+
+func TestRemoveTagsTxIgnoresCase(t *testing.T) {
+	initTestDB(t)
+	repo := NewBookmarkRepository()
+	bm, err := repo.Create(&models.BookmarkCreate{URL: "https://example.com/", TagNames: []string{"GoLang", "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.BulkApply([]int{bm.ID}, func(tx *sql.Tx, id int) error {
+		return repo.RemoveTagsTx(tx, id, []string{"golang"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(bm.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.TagNames) != 1 || got.TagNames[0] != "web" {
+		t.Errorf("标签 = %v，期望 [web]", got.TagNames)
+	}
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// ImportBatch 在单个事务中批量导入书签
+// 每个书签使用独立的 SAVEPOINT，单条失败不会影响同批次的其他书签
+func (r *BookmarkRepository) ImportBatch(items []*models.ImportItem) ([]*models.ImportItemResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]*models.ImportItemResult, 0, len(items))
+	for _, item := range items {
+		res := &models.ImportItemResult{
+			URL:    item.Bookmark.URL,
+			Title:  item.Bookmark.Title,
+			Folder: item.FolderName,
+		}
+
+		if _, err := tx.Exec("SAVEPOINT import_item"); err != nil {
+			return nil, fmt.Errorf("创建保存点失败: %w", err)
+		}
+
+		id, created, err := r.importItemTx(tx, item)
+		if err != nil {
+			if _, rbErr := tx.Exec("ROLLBACK TO import_item"); rbErr != nil {
+				return nil, fmt.Errorf("回滚保存点失败: %w", rbErr)
+			}
+			log.Printf("⚠️ 导入书签失败: %s, 错误: %v", item.Bookmark.URL, err)
+			res.Status = models.ImportStatusFailed
+			res.Error = err.Error()
+		} else {
+			res.BookmarkID = id
+			if created {
+				res.Status = models.ImportStatusCreated
+			} else {
+				res.Status = models.ImportStatusUpdated
+			}
+		}
+
+		if _, err := tx.Exec("RELEASE import_item"); err != nil {
+			return nil, fmt.Errorf("释放保存点失败: %w", err)
+		}
+		results = append(results, res)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	return results, nil
+}
+
+// importItemTx 在事务中导入单个书签，规范 URL 已存在时合并到现有书签
+func (r *BookmarkRepository) importItemTx(tx *sql.Tx, item *models.ImportItem) (int, bool, error) {
+	bm := &item.Bookmark
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	dateAdded := now
+	if !item.DateAdded.IsZero() {
+		dateAdded = item.DateAdded.UTC().Format(time.RFC3339Nano)
+	}
+
+	created := false
+	id, _, err := findByCanonicalURL(tx, bm.URL)
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := tx.Exec(
+			"INSERT INTO bookmarks (url, canonical_url, title, description, notes, is_favorite, unread, shared, date_added, date_modified) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Notes, bm.IsFavorite, bm.Unread, bm.Shared, dateAdded, now,
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("插入书签失败: %w", err)
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return 0, false, fmt.Errorf("获取插入ID失败: %w", err)
+		}
+		id = int(lastID)
+		created = true
+	case err != nil:
+		return 0, false, fmt.Errorf("查询书签失败: %w", err)
+	default:
+		// 已存在: 只覆盖导入文件中非空的字段，保留原有的添加时间；在回收站中的书签会被恢复
+		_, err := tx.Exec(`
+			UPDATE bookmarks SET
+				title = CASE WHEN ? <> '' THEN ? ELSE title END,
+				description = CASE WHEN ? <> '' THEN ? ELSE description END,
+				notes = CASE WHEN ? <> '' THEN ? ELSE notes END,
+				is_favorite = MAX(is_favorite, ?),
+				deleted_at = NULL,
+				date_modified = ?
+			WHERE id = ?`,
+			bm.Title, bm.Title, bm.Description, bm.Description, bm.Notes, bm.Notes,
+			bm.IsFavorite, now, id,
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("更新书签失败: %w", err)
+		}
+	}
+
+	// 标签取并集
+	for _, tagName := range bm.TagNames {
+		tagID, err := r.getOrCreateTagTx(tx, tagName)
+		if err != nil {
+			return 0, false, err
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id) VALUES (?, ?)", id, tagID); err != nil {
+			return 0, false, fmt.Errorf("关联标签失败: %w", err)
+		}
+	}
+
+	if item.FolderID > 0 {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO bookmark_folders (bookmark_id, folder_id, date_added) VALUES (?, ?, ?)",
+			id, item.FolderID, now,
+		); err != nil {
+			return 0, false, fmt.Errorf("关联文件夹失败: %w", err)
+		}
+	}
+
+	if err := r.recordRevisionTx(tx, id); err != nil {
+		return 0, false, err
+	}
+
+	return id, created, nil
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DuplicateCandidate 查重所需的书签字段
+type DuplicateCandidate struct {
+	ID           int
+	URL          string
+	CanonicalURL string
+	Title        string
+	Description  string
+	DateAdded    time.Time
+}
+
+// ListDuplicateCandidates 列出全部书签（不含回收站）的查重字段，按添加时间正序
+func (r *BookmarkRepository) ListDuplicateCandidates() ([]*DuplicateCandidate, error) {
+	rows, err := r.db.Query(`
+		SELECT id, url, COALESCE(canonical_url, url), title, description, date_added
+		FROM bookmarks WHERE deleted_at IS NULL
+		ORDER BY date_added, id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询书签失败: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []*DuplicateCandidate{}
+	for rows.Next() {
+		var c DuplicateCandidate
+		if err := rows.Scan(&c.ID, &c.URL, &c.CanonicalURL, &c.Title, &c.Description, &c.DateAdded); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &c)
+	}
+	return candidates, rows.Err()
+}
+
+// Merge 将 sourceIDs 合并到 targetID 后把它们移入回收站:
+// 标签、文件夹、附件取并集，笔记拼接，添加时间取最早，标题和描述为空时使用被合并书签的值
+// 任一书签不存在或已在回收站中时返回 sql.ErrNoRows
+func (r *BookmarkRepository) Merge(targetID int, sourceIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	type mergeRow struct {
+		title, description, notes string
+		isFavorite                bool
+		dateAdded                 time.Time
+	}
+	load := func(id int) (*mergeRow, error) {
+		var m mergeRow
+		err := tx.QueryRow(
+			"SELECT title, description, notes, is_favorite, date_added FROM bookmarks WHERE id = ? AND deleted_at IS NULL", id,
+		).Scan(&m.title, &m.description, &m.notes, &m.isFavorite, &m.dateAdded)
+		return &m, err
+	}
+
+	target, err := load(targetID)
+	if err != nil {
+		return err
+	}
+	notes := []string{}
+	if n := strings.TrimSpace(target.notes); n != "" {
+		notes = append(notes, n)
+	}
+
+	placeholders := make([]string, len(sourceIDs))
+	args := make([]interface{}, len(sourceIDs))
+	for i, id := range sourceIDs {
+		source, err := load(id)
+		if err != nil {
+			return err
+		}
+		if target.title == "" {
+			target.title = source.title
+		}
+		if target.description == "" {
+			target.description = source.description
+		}
+		if n := strings.TrimSpace(source.notes); n != "" && !containsString(notes, n) {
+			notes = append(notes, n)
+		}
+		target.isFavorite = target.isFavorite || source.isFavorite
+		if source.dateAdded.Before(target.dateAdded) {
+			target.dateAdded = source.dateAdded
+		}
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	in := "(" + strings.Join(placeholders, ",") + ")"
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.Exec(
+		"UPDATE bookmarks SET title = ?, description = ?, notes = ?, is_favorite = ?, date_added = ?, date_modified = ? WHERE id = ?",
+		target.title, target.description, strings.Join(notes, "\n\n"), target.isFavorite, target.dateAdded.UTC().Format(time.RFC3339Nano), now, targetID,
+	); err != nil {
+		return fmt.Errorf("更新目标书签失败: %w", err)
+	}
+
+	moveArgs := append([]interface{}{targetID}, args...)
+	statements := []string{
+		"INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id) SELECT ?, tag_id FROM bookmark_tags WHERE bookmark_id IN " + in,
+		"INSERT OR IGNORE INTO bookmark_folders (bookmark_id, folder_id, date_added) SELECT ?, folder_id, date_added FROM bookmark_folders WHERE bookmark_id IN " + in,
+		"UPDATE bookmark_assets SET bookmark_id = ? WHERE bookmark_id IN " + in,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, moveArgs...); err != nil {
+			return fmt.Errorf("合并关联数据失败: %w", err)
+		}
+	}
+
+	for _, id := range sourceIDs {
+		if err := r.DeleteTx(tx, id); err != nil {
+			return err
+		}
+	}
+	if err := r.recordRevisionTx(tx, targetID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	log.Printf("🔗 合并重复书签: %v -> %d", sourceIDs, targetID)
+	return nil
+}
+
+// containsString 切片中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
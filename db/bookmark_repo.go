@@ -12,22 +12,41 @@ import (
 
 // BookmarkRepository 书签数据库操作
 type BookmarkRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	source string // 写入历史版本时记录的修改来源
 }
 
 // NewBookmarkRepository 创建书签仓库
 func NewBookmarkRepository() *BookmarkRepository {
-	return &BookmarkRepository{db: DB}
+	return &BookmarkRepository{db: DB, source: models.RevisionSourceUser}
+}
+
+// WithSource 返回以指定来源记录历史版本的仓库副本
+func (r *BookmarkRepository) WithSource(source string) *BookmarkRepository {
+	return &BookmarkRepository{db: r.db, source: source}
 }
 
 // Create 创建书签（带事务处理）
 func (r *BookmarkRepository) Create(bm *models.BookmarkCreate) (*models.Bookmark, error) {
 	// 检查是否已存在
-	var existingID int
-	err := r.db.QueryRow("SELECT id FROM bookmarks WHERE url = ?", bm.URL).Scan(&existingID)
+	existingID, trashed, err := findByCanonicalURL(r.db, bm.URL)
 	if err == nil {
-		log.Printf("🔄 URL已存在(ID=%d)，转为更新操作", existingID)
-		return r.Update(existingID, bm)
+		if trashed {
+			log.Printf("♻️ URL在回收站中(ID=%d)，恢复后更新", existingID)
+			if err := r.Restore(existingID); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Printf("🔄 URL已存在(ID=%d)，转为更新操作", existingID)
+		}
+		// 保留已保存的原始 URL，只更新其他字段
+		existing, err := r.GetByID(existingID)
+		if err != nil {
+			return nil, err
+		}
+		merged := *bm
+		merged.URL = existing.URL
+		return r.Update(existingID, &merged)
 	}
 
 	// 开始事务
@@ -43,8 +62,8 @@ func (r *BookmarkRepository) Create(bm *models.BookmarkCreate) (*models.Bookmark
 
 	// 插入书签
 	result, err := tx.Exec(
-		"INSERT INTO bookmarks (url, title, description, notes, is_favorite, unread, shared, date_added, date_modified) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		bm.URL, bm.Title, bm.Description, bm.Notes, bm.IsFavorite, bm.Unread, bm.Shared, now, now,
+		"INSERT INTO bookmarks (url, canonical_url, title, description, notes, is_favorite, unread, shared, is_archived, date_added, date_modified) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Notes, bm.IsFavorite, bm.Unread, bm.Shared, bm.IsArchived, now, now,
 	)
 	if err != nil {
 		log.Printf("❌ INSERT失败: %v", err)
@@ -68,6 +87,10 @@ func (r *BookmarkRepository) Create(bm *models.BookmarkCreate) (*models.Bookmark
 		}
 	}
 
+	if err := r.recordRevisionTx(tx, int(id)); err != nil {
+		return nil, err
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("提交事务失败: %w", err)
@@ -77,7 +100,8 @@ func (r *BookmarkRepository) Create(bm *models.BookmarkCreate) (*models.Bookmark
 	return r.GetByID(int(id))
 }
 
-// Update 更新书签（带事务处理）
+// Update 更新书签（带事务处理），书签不存在或在回收站中时返回 sql.ErrNoRows，
+// 新 URL 与其他书签重复时返回 ErrDuplicateURL
 func (r *BookmarkRepository) Update(id int, bm *models.BookmarkCreate) (*models.Bookmark, error) {
 	// 开始事务
 	tx, err := r.db.Begin()
@@ -86,18 +110,25 @@ func (r *BookmarkRepository) Update(id int, bm *models.BookmarkCreate) (*models.
 	}
 	defer tx.Rollback()
 
+	if err := checkDuplicateURL(tx, id, bm.URL); err != nil {
+		return nil, err
+	}
+
 	now := time.Now().UTC().Format(time.RFC3339Nano)
 
 	log.Printf("🔄 执行UPDATE: ID=%d Title=%s Shared=%v", id, bm.Title, bm.Shared)
 
-	_, err = tx.Exec(
-		"UPDATE bookmarks SET url=?, title=?, description=?, notes=?, is_favorite=?, unread=?, shared=?, date_modified=? WHERE id=?",
-		bm.URL, bm.Title, bm.Description, bm.Notes, bm.IsFavorite, bm.Unread, bm.Shared, now, id,
+	result, err := tx.Exec(
+		"UPDATE bookmarks SET url=?, canonical_url=?, title=?, description=?, notes=?, is_favorite=?, unread=?, shared=?, is_archived=?, date_modified=? WHERE id=? AND deleted_at IS NULL",
+		bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Notes, bm.IsFavorite, bm.Unread, bm.Shared, bm.IsArchived, now, id,
 	)
 	if err != nil {
 		log.Printf("❌ UPDATE失败: %v", err)
 		return nil, fmt.Errorf("更新书签失败: %w", err)
 	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
 
 	// 更新标签（删除旧的，添加新的）
 	if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_id = ?", id); err != nil {
@@ -115,6 +146,10 @@ func (r *BookmarkRepository) Update(id int, bm *models.BookmarkCreate) (*models.
 		}
 	}
 
+	if err := r.recordRevisionTx(tx, id); err != nil {
+		return nil, err
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("提交事务失败: %w", err)
@@ -123,14 +158,102 @@ func (r *BookmarkRepository) Update(id int, bm *models.BookmarkCreate) (*models.
 	return r.GetByID(id)
 }
 
+// Patch 部分更新书签，只修改请求中提供的字段，书签不存在或在回收站中时返回 sql.ErrNoRows，
+// 新 URL 与其他书签重复时返回 ErrDuplicateURL
+// 标签处理顺序: 先按 tag_names 整体替换，再追加 add_tags，最后移除 remove_tags
+func (r *BookmarkRepository) Patch(id int, p *models.BookmarkPatch) (*models.Bookmark, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	sets := []string{"date_modified = ?"}
+	args := []interface{}{time.Now().UTC().Format(time.RFC3339Nano)}
+	addSet := func(column string, value interface{}) {
+		sets = append(sets, column+" = ?")
+		args = append(args, value)
+	}
+	if p.URL != nil {
+		if err := checkDuplicateURL(tx, id, *p.URL); err != nil {
+			return nil, err
+		}
+		addSet("url", *p.URL)
+		addSet("canonical_url", canonicalURL(*p.URL))
+	}
+	if p.Title != nil {
+		addSet("title", *p.Title)
+	}
+	if p.Description != nil {
+		addSet("description", *p.Description)
+	}
+	if p.Notes != nil {
+		addSet("notes", *p.Notes)
+	}
+	if p.IsFavorite != nil {
+		addSet("is_favorite", *p.IsFavorite)
+	}
+	if p.Unread != nil {
+		addSet("unread", *p.Unread)
+	}
+	if p.Shared != nil {
+		addSet("shared", *p.Shared)
+	}
+	if p.IsArchived != nil {
+		addSet("is_archived", *p.IsArchived)
+	}
+
+	args = append(args, id)
+	result, err := tx.Exec("UPDATE bookmarks SET "+strings.Join(sets, ", ")+" WHERE id = ? AND deleted_at IS NULL", args...)
+	if err != nil {
+		return nil, fmt.Errorf("更新书签失败: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if p.TagNames != nil {
+		if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE bookmark_id = ?", id); err != nil {
+			return nil, fmt.Errorf("删除旧标签失败: %w", err)
+		}
+		if err := r.AddTagsTx(tx, id, *p.TagNames); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.AddTags) > 0 {
+		if err := r.AddTagsTx(tx, id, p.AddTags); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.RemoveTags) > 0 {
+		if err := r.RemoveTagsTx(tx, id, p.RemoveTags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.recordRevisionTx(tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	return r.GetByID(id)
+}
+
 // GetByID 根据ID获取书签
 func (r *BookmarkRepository) GetByID(id int) (*models.Bookmark, error) {
 	// 使用 LEFT JOIN 一次性获取书签和标签（解决 N+1 问题）
 	query := `
 		SELECT 
-			b.id, b.url, b.title, b.description, b.notes,
-			b.is_favorite, b.unread, b.shared,
+			b.id, b.url, COALESCE(b.canonical_url, b.url), b.title, b.description, b.notes,
+			b.is_favorite, b.unread, b.shared, b.is_archived,
 			b.date_added, b.date_modified,
+			b.website_title, b.website_description, b.favicon_url, b.preview_image_url, b.deleted_at,
+			COALESCE(b.web_archive_snapshot_url, ''),
+			COALESCE(b.author, ''), b.date_published, COALESCE(b.site_name, ''), COALESCE(b.website_canonical_url, ''),
+			COALESCE(b.content_type, ''), COALESCE(b.language, ''), COALESCE(b.reading_time, 0), b.site_details,
 			GROUP_CONCAT(t.name, ',') as tag_names
 		FROM bookmarks b
 		LEFT JOIN bookmark_tags bt ON b.id = bt.bookmark_id
@@ -141,16 +264,21 @@ func (r *BookmarkRepository) GetByID(id int) (*models.Bookmark, error) {
 
 	var bm models.Bookmark
 	var tagNamesStr sql.NullString
+	var nullable nullableColumns
 
 	err := r.db.QueryRow(query, id).Scan(
-		&bm.ID, &bm.URL, &bm.Title, &bm.Description, &bm.Notes,
-		&bm.IsFavorite, &bm.Unread, &bm.Shared,
+		&bm.ID, &bm.URL, &bm.CanonicalURL, &bm.Title, &bm.Description, &bm.Notes,
+		&bm.IsFavorite, &bm.Unread, &bm.Shared, &bm.IsArchived,
 		&bm.DateAdded, &bm.DateModified,
+		&nullable.title, &nullable.description, &nullable.favicon, &nullable.previewImage, &nullable.deletedAt, &bm.WebArchiveSnapshotURL,
+		&bm.Author, &nullable.datePublished, &bm.SiteName, &bm.WebsiteCanonicalURL,
+		&bm.ContentType, &bm.Language, &bm.ReadingTime, &nullable.siteDetails,
 		&tagNamesStr,
 	)
 	if err != nil {
 		return nil, err
 	}
+	nullable.apply(&bm)
 
 	// 解析标签
 	if tagNamesStr.Valid && tagNamesStr.String != "" {
@@ -162,55 +290,91 @@ func (r *BookmarkRepository) GetByID(id int) (*models.Bookmark, error) {
 	return &bm, nil
 }
 
-// GetByURL 根据URL获取书签
+// GetActiveByID 根据ID获取不在回收站中的书签，书签不存在或已删除时返回 sql.ErrNoRows
+func (r *BookmarkRepository) GetActiveByID(id int) (*models.Bookmark, error) {
+	bm, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if bm.DateDeleted != nil {
+		return nil, sql.ErrNoRows
+	}
+	return bm, nil
+}
+
+// GetByURL 根据URL获取书签，按规范 URL 匹配（不含回收站中的书签）
 func (r *BookmarkRepository) GetByURL(url string) (*models.Bookmark, error) {
-	var id int
-	err := r.db.QueryRow("SELECT id FROM bookmarks WHERE url = ?", url).Scan(&id)
+	id, trashed, err := findByCanonicalURL(r.db, url)
 	if err != nil {
 		return nil, err
 	}
+	if trashed {
+		return nil, sql.ErrNoRows
+	}
 	return r.GetByID(id)
 }
 
 // List 获取书签列表（优化版，解决 N+1 查询问题）
+// filters["sort"] 指定排序方式（见 ParseBookmarkSort）
+// filters["cursor"] 为 *BookmarkCursor 时使用键集分页，offset 被忽略
 func (r *BookmarkRepository) List(limit, offset int, filters map[string]interface{}) ([]*models.Bookmark, error) {
-	// 使用 LEFT JOIN 一次性获取所有数据
-	query := `
-		SELECT 
-			b.id, b.url, b.title, b.description, b.notes,
-			b.is_favorite, b.unread, b.shared,
-			b.date_added, b.date_modified,
-			GROUP_CONCAT(t.name, ',') as tag_names
-		FROM bookmarks b
-		LEFT JOIN bookmark_tags bt ON b.id = bt.bookmark_id
-		LEFT JOIN tags t ON bt.tag_id = t.id
-	`
-
-	// 构建 WHERE 条件
-	whereClauses := []string{}
-	args := []interface{}{}
-
-	if q, ok := filters["q"].(string); ok && q != "" {
-		whereClauses = append(whereClauses, "(b.title LIKE ? OR b.description LIKE ? OR b.url LIKE ?)")
-		searchTerm := "%" + q + "%"
-		args = append(args, searchTerm, searchTerm, searchTerm)
+	// 构建搜索 JOIN 和 WHERE 条件
+	join, where, args, err := buildBookmarkFilter(filters)
+	if err != nil {
+		return nil, err
 	}
 
-	if unread, ok := filters["unread"].(bool); ok {
-		whereClauses = append(whereClauses, "b.unread = ?")
-		args = append(args, unread)
+	// 有搜索条件时返回相关度和高亮摘要，默认按相关度排序
+	searching := join != ""
+	searchColumns := "NULL, NULL"
+	if searching {
+		searchColumns = "s.fts_rank, s.fts_snippet"
 	}
 
-	if shared, ok := filters["shared"].(bool); ok {
-		whereClauses = append(whereClauses, "b.shared = ?")
-		args = append(args, shared)
+	sortParam, _ := filters["sort"].(string)
+	sort, err := ParseBookmarkSort(sortParam)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	if sort == nil {
+		sort = defaultBookmarkSort(searching)
+	}
+	orderBy := sort.orderBy(searching)
+
+	// 键集分页: 按 (date_added, id) 定位，忽略 offset
+	cursor, _ := filters["cursor"].(*BookmarkCursor)
+	if cursor != nil {
+		var clause string
+		var cursorArgs []interface{}
+		clause, cursorArgs, orderBy = cursorClause(cursor)
+		if clause != "" {
+			if where == "" {
+				where = " WHERE " + clause
+			} else {
+				where += " AND " + clause
+			}
+			args = append(args, cursorArgs...)
+		}
+		offset = 0
 	}
 
-	query += " GROUP BY b.id ORDER BY b.date_added DESC LIMIT ? OFFSET ?"
+	// 一次查询获取书签及其标签
+	query := `
+		SELECT 
+			b.id, b.url, COALESCE(b.canonical_url, b.url), b.title, b.description, b.notes,
+			b.is_favorite, b.unread, b.shared, b.is_archived,
+			b.date_added, b.date_modified,
+			b.website_title, b.website_description, b.favicon_url, b.preview_image_url, b.deleted_at,
+			COALESCE(b.web_archive_snapshot_url, ''),
+			COALESCE(b.author, ''), b.date_published, COALESCE(b.site_name, ''), COALESCE(b.website_canonical_url, ''),
+			COALESCE(b.content_type, ''), COALESCE(b.language, ''), COALESCE(b.reading_time, 0), b.site_details,
+			(SELECT GROUP_CONCAT(t.name, ',') FROM bookmark_tags bt JOIN tags t ON bt.tag_id = t.id WHERE bt.bookmark_id = b.id) as tag_names,
+			` + searchColumns + `
+		FROM bookmarks b` + join + `
+	` + where
+
+	// 标签用相关子查询获取，不需要 GROUP BY，排序可以直接走索引并在 LIMIT 处提前结束
+	query += " ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(query, args...)
@@ -223,17 +387,31 @@ func (r *BookmarkRepository) List(limit, offset int, filters map[string]interfac
 	for rows.Next() {
 		var bm models.Bookmark
 		var tagNamesStr sql.NullString
+		var rank sql.NullFloat64
+		var snippet sql.NullString
+		var nullable nullableColumns
 
 		err := rows.Scan(
-			&bm.ID, &bm.URL, &bm.Title, &bm.Description, &bm.Notes,
-			&bm.IsFavorite, &bm.Unread, &bm.Shared,
+			&bm.ID, &bm.URL, &bm.CanonicalURL, &bm.Title, &bm.Description, &bm.Notes,
+			&bm.IsFavorite, &bm.Unread, &bm.Shared, &bm.IsArchived,
 			&bm.DateAdded, &bm.DateModified,
-			&tagNamesStr,
+			&nullable.title, &nullable.description, &nullable.favicon, &nullable.previewImage, &nullable.deletedAt, &bm.WebArchiveSnapshotURL,
+			&bm.Author, &nullable.datePublished, &bm.SiteName, &bm.WebsiteCanonicalURL,
+			&bm.ContentType, &bm.Language, &bm.ReadingTime, &nullable.siteDetails,
+			&tagNamesStr, &rank, &snippet,
 		)
 		if err != nil {
 			log.Printf("⚠️ 扫描书签失败: %v", err)
 			continue
 		}
+		nullable.apply(&bm)
+
+		// BM25 分数越小越相关，对外取反使分数越大越相关
+		if rank.Valid {
+			score := -rank.Float64
+			bm.SearchScore = &score
+		}
+		bm.SearchSnippet = highlightSnippet(snippet.String)
 
 		// 解析标签
 		if tagNamesStr.Valid && tagNamesStr.String != "" {
@@ -245,44 +423,127 @@ func (r *BookmarkRepository) List(limit, offset int, filters map[string]interfac
 		bookmarks = append(bookmarks, &bm)
 	}
 
+	// 向前翻页时按升序查询，结果翻转为与其他页一致的时间倒序
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(bookmarks)-1; i < j; i, j = i+1, j-1 {
+			bookmarks[i], bookmarks[j] = bookmarks[j], bookmarks[i]
+		}
+	}
+
 	return bookmarks, nil
 }
 
-// Delete 删除书签
+// SetArchived 归档或取消归档书签，书签不存在时返回 sql.ErrNoRows
+func (r *BookmarkRepository) SetArchived(id int, archived bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	result, err := tx.Exec("UPDATE bookmarks SET is_archived = ?, date_modified = ? WHERE id = ? AND deleted_at IS NULL", archived, now, id)
+	if err != nil {
+		return fmt.Errorf("更新归档状态失败: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := r.recordRevisionTx(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete 将书签移入回收站，书签不存在或已在回收站中时返回 sql.ErrNoRows
 func (r *BookmarkRepository) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM bookmarks WHERE id = ?", id)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	result, err := r.db.Exec("UPDATE bookmarks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, id)
 	if err != nil {
 		return fmt.Errorf("删除书签失败: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("书签不存在: ID=%d", id)
+		return sql.ErrNoRows
 	}
 
 	return nil
 }
 
-// Count 统计书签数量
+// Count 统计书签数量（与 List 使用相同的过滤条件）
 func (r *BookmarkRepository) Count(filters map[string]interface{}) (int, error) {
-	query := "SELECT COUNT(*) FROM bookmarks"
+	join, where, args, err := buildBookmarkFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	query := "SELECT COUNT(*) FROM bookmarks b" + join + where
+
+	var count int
+	err = r.db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
 
-	whereClauses := []string{}
+// buildBookmarkFilter 根据过滤条件构建全文搜索 JOIN 和 WHERE 子句（书签表别名为 b）
+// 返回的参数按 JOIN、WHERE 的顺序排列
+// 默认排除回收站中的书签，filters["trashed"] 为 true 时只返回回收站中的书签
+func buildBookmarkFilter(filters map[string]interface{}) (string, string, []interface{}, error) {
+	whereClauses := []string{"b.deleted_at IS NULL"}
+	join := ""
 	args := []interface{}{}
 
+	if trashed, ok := filters["trashed"].(bool); ok && trashed {
+		whereClauses[0] = "b.deleted_at IS NOT NULL"
+	}
+
+	// 搜索查询语言: 普通词走全文索引，操作符编译为 WHERE 条件
 	if q, ok := filters["q"].(string); ok && q != "" {
-		whereClauses = append(whereClauses, "(title LIKE ? OR description LIKE ? OR url LIKE ?)")
-		searchTerm := "%" + q + "%"
-		args = append(args, searchTerm, searchTerm, searchTerm)
+		sq, err := ParseSearchQuery(q)
+		if err != nil {
+			return "", "", nil, err
+		}
+		var searchArgs []interface{}
+		join, searchArgs = searchJoin(sq.Terms)
+		args = append(args, searchArgs...)
+
+		clauses, queryArgs := compileQueryFilters(sq)
+		whereClauses = append(whereClauses, clauses...)
+		args = append(args, queryArgs...)
 	}
 
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	if tag, ok := filters["tag"].(string); ok && tag != "" {
+		whereClauses = append(whereClauses, tagFilterClause)
+		args = append(args, tag)
 	}
 
-	var count int
-	err := r.db.QueryRow(query, args...).Scan(&count)
-	return count, err
+	if unread, ok := filters["unread"].(bool); ok {
+		whereClauses = append(whereClauses, "b.unread = ?")
+		args = append(args, unread)
+	}
+
+	if shared, ok := filters["shared"].(bool); ok {
+		whereClauses = append(whereClauses, "b.shared = ?")
+		args = append(args, shared)
+	}
+
+	if archived, ok := filters["archived"].(bool); ok {
+		whereClauses = append(whereClauses, "b.is_archived = ?")
+		args = append(args, archived)
+	}
+
+	if folderID, ok := filters["folder_id"].(int); ok {
+		whereClauses = append(whereClauses, "b.id IN (SELECT bookmark_id FROM bookmark_folders WHERE folder_id = ?)")
+		args = append(args, folderID)
+	}
+
+	if noFolder, ok := filters["no_folder"].(bool); ok && noFolder {
+		whereClauses = append(whereClauses, "b.id NOT IN (SELECT bookmark_id FROM bookmark_folders)")
+	}
+
+	return join, " WHERE " + strings.Join(whereClauses, " AND "), args, nil
 }
 
 // getOrCreateTagTx 在事务中获取或创建标签
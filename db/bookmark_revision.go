@@ -0,0 +1,131 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// queryer *sql.DB 和 *sql.Tx 共有的查询方法
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// snapshotBookmark 读取书签当前内容
+func snapshotBookmark(q queryer, id int) (*models.BookmarkSnapshot, error) {
+	s := &models.BookmarkSnapshot{TagNames: []string{}}
+	err := q.QueryRow(
+		"SELECT url, title, description, notes, is_favorite, unread, shared, is_archived FROM bookmarks WHERE id = ?", id,
+	).Scan(&s.URL, &s.Title, &s.Description, &s.Notes, &s.IsFavorite, &s.Unread, &s.Shared, &s.IsArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query("SELECT t.name FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.bookmark_id = ? ORDER BY t.name", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询书签标签失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		s.TagNames = append(s.TagNames, name)
+	}
+	return s, rows.Err()
+}
+
+// recordRevisionTx 在事务中为书签记录新版本，内容与最新版本相同时不记录
+func (r *BookmarkRepository) recordRevisionTx(tx *sql.Tx, id int) error {
+	snapshot, err := snapshotBookmark(tx, id)
+	if err != nil {
+		return fmt.Errorf("读取书签内容失败: %w", err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	var last string
+	err = tx.QueryRow("SELECT snapshot FROM bookmark_revisions WHERE bookmark_id = ? ORDER BY id DESC LIMIT 1", id).Scan(&last)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("查询历史版本失败: %w", err)
+	default:
+		// 重新编码后比较，兼容迁移时由 SQL 生成的快照
+		var lastSnapshot models.BookmarkSnapshot
+		if json.Unmarshal([]byte(last), &lastSnapshot) == nil {
+			if lastData, err := json.Marshal(&lastSnapshot); err == nil && bytes.Equal(lastData, data) {
+				return nil
+			}
+		}
+	}
+
+	source := r.source
+	if source == "" {
+		source = models.RevisionSourceUser
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.Exec(
+		"INSERT INTO bookmark_revisions (bookmark_id, source, snapshot, date_created) VALUES (?, ?, ?, ?)",
+		id, source, string(data), now,
+	); err != nil {
+		return fmt.Errorf("记录历史版本失败: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions 获取书签的全部历史版本（按时间正序）
+func (r *BookmarkRepository) ListRevisions(bookmarkID int) ([]*models.BookmarkRevision, error) {
+	rows, err := r.db.Query(
+		"SELECT id, bookmark_id, source, snapshot, date_created FROM bookmark_revisions WHERE bookmark_id = ? ORDER BY id",
+		bookmarkID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史版本失败: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.BookmarkRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision 获取书签的指定历史版本
+func (r *BookmarkRepository) GetRevision(bookmarkID, revisionID int) (*models.BookmarkRevision, error) {
+	row := r.db.QueryRow(
+		"SELECT id, bookmark_id, source, snapshot, date_created FROM bookmark_revisions WHERE bookmark_id = ? AND id = ?",
+		bookmarkID, revisionID,
+	)
+	return scanRevision(row)
+}
+
+// scanRevision 扫描一行历史版本
+func scanRevision(row interface{ Scan(...interface{}) error }) (*models.BookmarkRevision, error) {
+	var rev models.BookmarkRevision
+	var data string
+	if err := row.Scan(&rev.ID, &rev.BookmarkID, &rev.Source, &data, &rev.DateCreated); err != nil {
+		return nil, err
+	}
+	rev.Snapshot = &models.BookmarkSnapshot{}
+	if err := json.Unmarshal([]byte(data), rev.Snapshot); err != nil {
+		return nil, fmt.Errorf("解析历史版本失败: ID=%d, %w", rev.ID, err)
+	}
+	if rev.Snapshot.TagNames == nil {
+		rev.Snapshot.TagNames = []string{}
+	}
+	return &rev, nil
+}
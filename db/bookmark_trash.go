@@ -0,0 +1,73 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Restore 将书签移出回收站，书签不在回收站时返回 sql.ErrNoRows
+func (r *BookmarkRepository) Restore(id int) error {
+	result, err := r.db.Exec("UPDATE bookmarks SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("恢复书签失败: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Purge 彻底删除回收站中的书签及其标签、文件夹、附件记录、历史版本、链接检查结果和正文，书签不在回收站时返回 sql.ErrNoRows
+// 附件文件由调用方负责删除
+func (r *BookmarkRepository) Purge(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM bookmarks WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("删除书签失败: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	for _, table := range []string{"bookmark_tags", "bookmark_folders", "bookmark_assets", "bookmark_revisions", "link_checks", "bookmark_contents"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE bookmark_id = ?", id); err != nil {
+			return fmt.Errorf("删除 %s 关联失败: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListTrashedIDs 返回删除时间早于 before 的回收站书签ID，before 为零值时返回全部
+func (r *BookmarkRepository) ListTrashedIDs(before time.Time) ([]int, error) {
+	query := "SELECT id FROM bookmarks WHERE deleted_at IS NOT NULL"
+	args := []interface{}{}
+	if !before.IsZero() {
+		query += " AND deleted_at < ?"
+		args = append(args, before.UTC().Format(time.RFC3339Nano))
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY deleted_at", args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询回收站失败: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"ai-bookmark-service/utils"
+)
+
+// canonicalURL 计算书签的规范 URL，无法解析时返回原 URL
+func canonicalURL(rawURL string) string {
+	canonical, err := utils.CanonicalizeURL(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return canonical
+}
+
+// findByCanonicalURL 按规范 URL 查找书签，优先返回 URL 完全相同的、不在回收站中的书签
+func findByCanonicalURL(q queryer, rawURL string) (id int, trashed bool, err error) {
+	err = q.QueryRow(`
+		SELECT id, deleted_at IS NOT NULL FROM bookmarks
+		WHERE canonical_url = ? OR url = ?
+		ORDER BY url = ? DESC, deleted_at IS NOT NULL, id
+		LIMIT 1`,
+		canonicalURL(rawURL), rawURL, rawURL,
+	).Scan(&id, &trashed)
+	return id, trashed, err
+}
+
+// ErrDuplicateURL 修改后的 URL 与其他书签相同或规范 URL 相同
+var ErrDuplicateURL = errors.New("已存在相同 URL 的书签")
+
+// checkDuplicateURL 检查把书签 id 的 URL 改为 rawURL 后是否与其他书签重复:
+// 与不在回收站中的书签规范 URL 相同，或与任意书签（含回收站，url 列有唯一约束）的 URL 相同
+// URL 未改变时不检查，已有的重复书签由重复书签合并处理；书签不存在或在回收站中时返回 sql.ErrNoRows
+func checkDuplicateURL(q queryer, id int, rawURL string) error {
+	var current string
+	if err := q.QueryRow("SELECT url FROM bookmarks WHERE id = ? AND deleted_at IS NULL", id).Scan(&current); err != nil {
+		return err
+	}
+	if current == rawURL {
+		return nil
+	}
+
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM bookmarks
+		WHERE id != ? AND (url = ? OR (canonical_url = ? AND deleted_at IS NULL)))`,
+		id, rawURL, canonicalURL(rawURL),
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("查询重复 URL 失败: %w", err)
+	}
+	if exists {
+		return ErrDuplicateURL
+	}
+	return nil
+}
+
+// backfillCanonicalURLs 为历史书签计算规范 URL，规范化规则变化后重新计算已保存的规范 URL
+func backfillCanonicalURLs() error {
+	rows, err := DB.Query("SELECT id, url, COALESCE(canonical_url, '') FROM bookmarks")
+	if err != nil {
+		return fmt.Errorf("查询书签失败: %w", err)
+	}
+	pending := map[int]string{}
+	for rows.Next() {
+		var id int
+		var url, stored string
+		if err := rows.Scan(&id, &url, &stored); err != nil {
+			rows.Close()
+			return err
+		}
+		if canonical := canonicalURL(url); canonical != stored {
+			pending[id] = canonical
+		}
+	}
+	rows.Close()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	for id, canonical := range pending {
+		if _, err := tx.Exec("UPDATE bookmarks SET canonical_url = ? WHERE id = ?", canonical, id); err != nil {
+			return fmt.Errorf("更新规范 URL 失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	log.Printf("🔧 数据库迁移: 更新 %d 个书签的规范 URL", len(pending))
+	return nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BookmarkCursor 书签列表的键集分页游标，位置由 (date_added, id) 确定
+// 零值表示从第一页开始
+type BookmarkCursor struct {
+	DateAdded time.Time
+	ID        int
+	Backward  bool // true 表示取游标之前（更新）的一页，即上一页
+}
+
+// ErrInvalidCursor 游标格式无效
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// IsZero 游标是否未指定位置
+func (c *BookmarkCursor) IsZero() bool {
+	return c.ID == 0
+}
+
+// Encode 将游标编码为可放入 URL 的不透明字符串
+func (c *BookmarkCursor) Encode() string {
+	dir := "n"
+	if c.Backward {
+		dir = "p"
+	}
+	raw := dir + "|" + strconv.Itoa(c.ID) + "|" + c.DateAdded.UTC().Format(time.RFC3339Nano)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeBookmarkCursor 解析 Encode 生成的游标，空字符串表示第一页
+func DecodeBookmarkCursor(s string) (*BookmarkCursor, error) {
+	if s == "" {
+		return &BookmarkCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || (parts[0] != "n" && parts[0] != "p") {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	dateAdded, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &BookmarkCursor{DateAdded: dateAdded, ID: id, Backward: parts[0] == "p"}, nil
+}
+
+// cursorClause 构建游标位置条件和对应的排序
+func cursorClause(c *BookmarkCursor) (string, []interface{}, string) {
+	orderBy := "b.date_added DESC, b.id DESC"
+	if c.Backward {
+		orderBy = "b.date_added ASC, b.id ASC"
+	}
+	if c.IsZero() {
+		return "", nil, orderBy
+	}
+
+	op := "<"
+	if c.Backward {
+		op = ">"
+	}
+	date := c.DateAdded.UTC().Format(time.RFC3339Nano)
+	clause := "(b.date_added " + op + " ? OR (b.date_added = ? AND b.id " + op + " ?))"
+	return clause, []interface{}{date, date, c.ID}, orderBy
+}
@@ -114,6 +114,72 @@ func Init(dbPath string) error {
 		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
 	);
 
+	CREATE TABLE IF NOT EXISTS bookmark_assets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		bookmark_id INTEGER NOT NULL,
+		asset_type TEXT NOT NULL,
+		content_type TEXT DEFAULT '',
+		display_name TEXT DEFAULT '',
+		file TEXT DEFAULT '',
+		file_size INTEGER DEFAULT 0,
+		status TEXT DEFAULT 'pending',
+		date_created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmark_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		bookmark_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		snapshot TEXT NOT NULL,
+		date_created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS link_checks (
+		bookmark_id INTEGER PRIMARY KEY,
+		status_code INTEGER DEFAULT 0,
+		final_url TEXT DEFAULT '',
+		error TEXT DEFAULT '',
+		redirected INTEGER DEFAULT 0,
+		consecutive_failures INTEGER DEFAULT 0,
+		last_checked DATETIME,
+		FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmark_contents (
+		bookmark_id INTEGER PRIMARY KEY,
+		title TEXT DEFAULT '',
+		byline TEXT DEFAULT '',
+		excerpt TEXT DEFAULT '',
+		text TEXT DEFAULT '',
+		markdown TEXT DEFAULT '',
+		word_count INTEGER DEFAULT 0,
+		date_extracted DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS media_cache (
+		kind TEXT NOT NULL,
+		source_url TEXT NOT NULL,
+		file TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		date_fetched DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (kind, source_url)
+	);
+
+	CREATE TABLE IF NOT EXISTS scraper_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		domain TEXT NOT NULL UNIQUE,
+		headers TEXT DEFAULT '{}',
+		cookies TEXT DEFAULT '',
+		proxy_url TEXT DEFAULT '',
+		timeout INTEGER DEFAULT 0,
+		disabled INTEGER DEFAULT 0,
+		date_created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		date_modified DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
 	CREATE TABLE IF NOT EXISTS system_configs (
 		key TEXT PRIMARY KEY,
 		value TEXT NOT NULL,
@@ -121,7 +187,6 @@ func Init(dbPath string) error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_bookmarks_url ON bookmarks(url);
-	CREATE INDEX IF NOT EXISTS idx_bookmarks_date_added ON bookmarks(date_added DESC);
 	CREATE INDEX IF NOT EXISTS idx_bookmarks_is_favorite ON bookmarks(is_favorite);
 	CREATE INDEX IF NOT EXISTS idx_bookmarks_unread ON bookmarks(unread);
 	CREATE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
@@ -134,6 +199,9 @@ func Init(dbPath string) error {
 	CREATE INDEX IF NOT EXISTS idx_workflows_priority ON workflows(priority);
 	CREATE INDEX IF NOT EXISTS idx_workflow_triggers_workflow ON workflow_triggers(workflow_id);
 	CREATE INDEX IF NOT EXISTS idx_workflow_actions_workflow ON workflow_actions(workflow_id);
+	CREATE INDEX IF NOT EXISTS idx_bookmark_assets_bookmark ON bookmark_assets(bookmark_id);
+	CREATE INDEX IF NOT EXISTS idx_bookmark_revisions_bookmark ON bookmark_revisions(bookmark_id, id);
+	CREATE INDEX IF NOT EXISTS idx_link_checks_last_checked ON link_checks(last_checked);
 	`
 
 	_, err = DB.Exec(schema)
@@ -141,6 +209,14 @@ func Init(dbPath string) error {
 		return err
 	}
 
+	if err := migrate(); err != nil {
+		return err
+	}
+
+	if err := initFTS(); err != nil {
+		return err
+	}
+
 	log.Printf("✅ 数据库初始化成功 (WAL模式): %s", dbPath)
 	return nil
 }
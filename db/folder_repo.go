@@ -56,6 +56,13 @@ func (r *FolderRepository) Create(fc *models.FolderCreate) (*models.Folder, erro
 	return r.GetByID(int(id))
 }
 
+// folderCountQuery counts the bookmarks in a folder, excluding trashed ones
+const folderCountQuery = `
+	SELECT COUNT(*) FROM bookmark_folders bf
+	JOIN bookmarks b ON b.id = bf.bookmark_id
+	WHERE bf.folder_id = ? AND b.deleted_at IS NULL
+`
+
 // GetByID retrieves a folder by ID
 func (r *FolderRepository) GetByID(id int) (*models.Folder, error) {
 	var folder models.Folder
@@ -69,7 +76,7 @@ func (r *FolderRepository) GetByID(id int) (*models.Folder, error) {
 	}
 	
 	// Get bookmark count
-	if err := r.db.QueryRow("SELECT COUNT(*) FROM bookmark_folders WHERE folder_id = ?", id).Scan(&folder.Count); err != nil {
+	if err := r.db.QueryRow(folderCountQuery, id).Scan(&folder.Count); err != nil {
 		log.Printf("⚠️ 获取文件夹书签数量失败: %v", err)
 		folder.Count = 0
 	}
@@ -83,9 +90,10 @@ func (r *FolderRepository) List() ([]*models.Folder, error) {
 	query := `
 		SELECT 
 			f.id, f.name, f.color, f.icon, f.sort_order, f.date_added,
-			COUNT(bf.bookmark_id) as bookmark_count
+			COUNT(b.id) as bookmark_count
 		FROM folders f
 		LEFT JOIN bookmark_folders bf ON f.id = bf.folder_id
+		LEFT JOIN bookmarks b ON b.id = bf.bookmark_id AND b.deleted_at IS NULL
 		GROUP BY f.id, f.name, f.color, f.icon, f.sort_order, f.date_added
 		ORDER BY f.sort_order ASC
 	`
@@ -154,10 +162,20 @@ func (r *FolderRepository) Delete(id int) error {
 }
 
 // GetBookmarks retrieves bookmarks in a folder
-func (r *FolderRepository) GetBookmarks(folderID int, limit, offset int) ([]*models.Bookmark, int, error) {
+// sort 为空时按加入文件夹的时间倒序，否则见 ParseBookmarkSort
+func (r *FolderRepository) GetBookmarks(folderID int, limit, offset int, sort string) ([]*models.Bookmark, int, error) {
+	orderBy := "bf.date_added DESC"
+	bs, err := ParseBookmarkSort(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bs != nil {
+		orderBy = bs.orderBy(false)
+	}
+
 	// Get total count
 	var total int
-	if err := r.db.QueryRow("SELECT COUNT(*) FROM bookmark_folders WHERE folder_id = ?", folderID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(folderCountQuery, folderID).Scan(&total); err != nil {
 		log.Printf("⚠️ 获取文件夹书签总数失败: %v", err)
 		total = 0
 	}
@@ -167,8 +185,8 @@ func (r *FolderRepository) GetBookmarks(folderID int, limit, offset int) ([]*mod
 		SELECT bf.bookmark_id 
 		FROM bookmark_folders bf
 		JOIN bookmarks b ON bf.bookmark_id = b.id
-		WHERE bf.folder_id = ?
-		ORDER BY bf.date_added DESC
+		WHERE bf.folder_id = ? AND b.deleted_at IS NULL
+		ORDER BY `+orderBy+`
 		LIMIT ? OFFSET ?
 	`, folderID, limit, offset)
 	
@@ -213,6 +231,31 @@ func (r *FolderRepository) RemoveBookmark(bookmarkID, folderID int) error {
 	return err
 }
 
+// AddBookmarkTx adds a bookmark to a folder within a transaction
+func (r *FolderRepository) AddBookmarkTx(tx *sql.Tx, bookmarkID, folderID int) error {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	_, err := tx.Exec(
+		"INSERT OR IGNORE INTO bookmark_folders (bookmark_id, folder_id, date_added) VALUES (?, ?, ?)",
+		bookmarkID, folderID, now,
+	)
+	return err
+}
+
+// RemoveBookmarkTx removes a bookmark from a folder within a transaction
+func (r *FolderRepository) RemoveBookmarkTx(tx *sql.Tx, bookmarkID, folderID int) error {
+	_, err := tx.Exec(
+		"DELETE FROM bookmark_folders WHERE bookmark_id = ? AND folder_id = ?",
+		bookmarkID, folderID,
+	)
+	return err
+}
+
+// ClearBookmarkTx removes a bookmark from all folders within a transaction
+func (r *FolderRepository) ClearBookmarkTx(tx *sql.Tx, bookmarkID int) error {
+	_, err := tx.Exec("DELETE FROM bookmark_folders WHERE bookmark_id = ?", bookmarkID)
+	return err
+}
+
 // GetBookmarkFolders retrieves folders that contain a bookmark
 func (r *FolderRepository) GetBookmarkFolders(bookmarkID int) ([]*models.Folder, error) {
 	rows, err := r.db.Query(`
@@ -243,3 +286,43 @@ func (r *FolderRepository) GetBookmarkFolders(bookmarkID int) ([]*models.Folder,
 	
 	return folders, nil
 }
+
+// GetOrCreateByName retrieves a folder by name, creating it if it does not exist
+func (r *FolderRepository) GetOrCreateByName(name string) (*models.Folder, error) {
+	var id int
+	err := r.db.QueryRow("SELECT id FROM folders WHERE name = ? ORDER BY id LIMIT 1", name).Scan(&id)
+	if err == nil {
+		return r.GetByID(id)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return r.Create(&models.FolderCreate{Name: name})
+}
+
+// ListMemberships returns the folder IDs of every bookmark that belongs to at least one folder
+func (r *FolderRepository) ListMemberships() (map[int][]int, error) {
+	rows, err := r.db.Query(`
+		SELECT bf.bookmark_id, bf.folder_id
+		FROM bookmark_folders bf
+		JOIN folders f ON bf.folder_id = f.id
+		ORDER BY f.sort_order
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := make(map[int][]int)
+	for rows.Next() {
+		var bookmarkID, folderID int
+		if err := rows.Scan(&bookmarkID, &folderID); err != nil {
+			log.Printf("⚠️ 扫描文件夹关联失败: %v", err)
+			continue
+		}
+		memberships[bookmarkID] = append(memberships[bookmarkID], folderID)
+	}
+
+	return memberships, nil
+}
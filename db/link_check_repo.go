@@ -0,0 +1,152 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// brokenLinkClause 失效链接的判定: 明确返回 404/410，或连续失败达到 2 次（避免偶发故障误判）
+const brokenLinkClause = `b.id IN (SELECT bookmark_id FROM link_checks WHERE status_code IN (404, 410) OR consecutive_failures >= 2)`
+
+// redirectedLinkClause 已重定向到其他地址的链接
+const redirectedLinkClause = `b.id IN (SELECT bookmark_id FROM link_checks WHERE redirected = 1)`
+
+// LinkCheckRepository 链接健康检查结果数据库操作
+type LinkCheckRepository struct {
+	db *sql.DB
+}
+
+// NewLinkCheckRepository 创建链接检查仓库
+func NewLinkCheckRepository() *LinkCheckRepository {
+	return &LinkCheckRepository{db: DB}
+}
+
+// linkCheckColumns 链接检查查询列，与 scanLinkCheck 对应
+const linkCheckColumns = "lc.bookmark_id, lc.status_code, lc.final_url, lc.error, lc.redirected, lc.consecutive_failures, lc.last_checked"
+
+// Record 保存一次检查结果，failed 为 true 时累加连续失败次数，否则清零
+// redirected 按最终地址与书签的规范 URL 比较得出
+func (r *LinkCheckRepository) Record(bookmarkID, statusCode int, finalURL, errMsg string, failed bool) (*models.LinkCheck, error) {
+	var bookmarkCanonical string
+	err := r.db.QueryRow("SELECT COALESCE(canonical_url, url) FROM bookmarks WHERE id = ?", bookmarkID).Scan(&bookmarkCanonical)
+	if err != nil {
+		return nil, err
+	}
+	redirected := finalURL != "" && canonicalURL(finalURL) != bookmarkCanonical
+	failures := 0
+	if failed {
+		failures = 1
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO link_checks (bookmark_id, status_code, final_url, error, redirected, consecutive_failures, last_checked)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(bookmark_id) DO UPDATE SET
+			status_code = excluded.status_code,
+			final_url = excluded.final_url,
+			error = excluded.error,
+			redirected = excluded.redirected,
+			consecutive_failures = CASE WHEN ? THEN link_checks.consecutive_failures + 1 ELSE 0 END,
+			last_checked = excluded.last_checked`,
+		bookmarkID, statusCode, finalURL, errMsg, redirected, failures, time.Now().UTC().Format(time.RFC3339Nano), failed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("保存链接检查结果失败: %w", err)
+	}
+
+	return r.Get(bookmarkID)
+}
+
+// Get 获取书签的最近一次检查结果，未检查过时返回 sql.ErrNoRows
+func (r *LinkCheckRepository) Get(bookmarkID int) (*models.LinkCheck, error) {
+	row := r.db.QueryRow("SELECT "+linkCheckColumns+" FROM link_checks lc WHERE lc.bookmark_id = ?", bookmarkID)
+	return scanLinkCheck(row)
+}
+
+// ListDue 列出从未检查或最近一次检查早于 before 的书签ID，未检查的优先
+func (r *LinkCheckRepository) ListDue(before time.Time, limit int) ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT b.id FROM bookmarks b
+		LEFT JOIN link_checks lc ON lc.bookmark_id = b.id
+		WHERE b.deleted_at IS NULL AND (lc.last_checked IS NULL OR lc.last_checked < ?)
+		ORDER BY lc.last_checked IS NOT NULL, lc.last_checked, b.id
+		LIMIT ?`,
+		before.UTC().Format(time.RFC3339Nano), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询待检查书签失败: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Report 生成链接健康报告
+func (r *LinkCheckRepository) Report() (*models.LinkReport, error) {
+	report := &models.LinkReport{}
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COUNT(lc.bookmark_id),
+			COALESCE(SUM(lc.bookmark_id IS NOT NULL AND lc.consecutive_failures = 0 AND lc.redirected = 0), 0)
+		FROM bookmarks b
+		LEFT JOIN link_checks lc ON lc.bookmark_id = b.id
+		WHERE b.deleted_at IS NULL`,
+	).Scan(&report.Total, &report.Checked, &report.Healthy)
+	if err != nil {
+		return nil, fmt.Errorf("统计链接检查结果失败: %w", err)
+	}
+
+	if report.Broken, err = r.listReportItems(brokenLinkClause); err != nil {
+		return nil, err
+	}
+	if report.Redirected, err = r.listReportItems(redirectedLinkClause); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// listReportItems 列出满足条件的书签及其检查结果
+func (r *LinkCheckRepository) listReportItems(clause string) ([]*models.LinkReportItem, error) {
+	rows, err := r.db.Query(`
+		SELECT b.id, b.url, b.title, ` + linkCheckColumns + `
+		FROM bookmarks b
+		JOIN link_checks lc ON lc.bookmark_id = b.id
+		WHERE b.deleted_at IS NULL AND ` + clause + `
+		ORDER BY lc.last_checked DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("查询链接检查结果失败: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*models.LinkReportItem{}
+	for rows.Next() {
+		item := &models.LinkReportItem{Check: &models.LinkCheck{}}
+		c := item.Check
+		if err := rows.Scan(&item.ID, &item.URL, &item.Title,
+			&c.BookmarkID, &c.StatusCode, &c.FinalURL, &c.Error, &c.Redirected, &c.ConsecutiveFailures, &c.LastChecked); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// scanLinkCheck 扫描一行链接检查结果
+func scanLinkCheck(row *sql.Row) (*models.LinkCheck, error) {
+	c := &models.LinkCheck{}
+	err := row.Scan(&c.BookmarkID, &c.StatusCode, &c.FinalURL, &c.Error, &c.Redirected, &c.ConsecutiveFailures, &c.LastChecked)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
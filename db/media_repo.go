@@ -0,0 +1,44 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// MediaRepository 网站图标和预览图缓存记录的数据库操作
+type MediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository 创建媒体缓存仓库
+func NewMediaRepository() *MediaRepository {
+	return &MediaRepository{db: DB}
+}
+
+// Get 按原始地址获取缓存记录，未缓存时返回 sql.ErrNoRows
+func (r *MediaRepository) Get(kind, sourceURL string) (*models.MediaFile, error) {
+	m := &models.MediaFile{Kind: kind, SourceURL: sourceURL}
+	err := r.db.QueryRow(
+		"SELECT file, content_type, date_fetched FROM media_cache WHERE kind = ? AND source_url = ?",
+		kind, sourceURL,
+	).Scan(&m.File, &m.ContentType, &m.DateFetched)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save 保存缓存记录，已存在时覆盖
+func (r *MediaRepository) Save(m *models.MediaFile) error {
+	_, err := r.db.Exec(
+		"INSERT OR REPLACE INTO media_cache (kind, source_url, file, content_type, date_fetched) VALUES (?, ?, ?, ?, ?)",
+		m.Kind, m.SourceURL, m.File, m.ContentType, time.Now().UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("保存媒体缓存记录失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// columnMigration 为已有数据库补充的列
+type columnMigration struct {
+	table  string
+	column string
+	ddl    string // ALTER TABLE ... ADD COLUMN 之后的列定义
+}
+
+// columnMigrations 按添加顺序排列，新建数据库同样通过这里补列
+var columnMigrations = []columnMigration{
+	{"bookmarks", "domain", "domain TEXT DEFAULT ''"},
+	{"bookmarks", "is_archived", "is_archived INTEGER DEFAULT 0"},
+	{"bookmarks", "website_title", "website_title TEXT"},
+	{"bookmarks", "website_description", "website_description TEXT"},
+	{"bookmarks", "favicon_url", "favicon_url TEXT"},
+	{"bookmarks", "preview_image_url", "preview_image_url TEXT"},
+	{"bookmarks", "deleted_at", "deleted_at DATETIME"},
+	{"bookmarks", "canonical_url", "canonical_url TEXT"},
+	{"bookmarks", "web_archive_snapshot_url", "web_archive_snapshot_url TEXT"},
+	{"bookmarks", "author", "author TEXT"},
+	{"bookmarks", "date_published", "date_published DATETIME"},
+	{"bookmarks", "site_name", "site_name TEXT"},
+	{"bookmarks", "website_canonical_url", "website_canonical_url TEXT"},
+	{"bookmarks", "content_type", "content_type TEXT"},
+	{"bookmarks", "language", "language TEXT"},
+	{"bookmarks", "reading_time", "reading_time INTEGER"},
+	{"bookmarks", "site_details", "site_details TEXT"},
+}
+
+// migrate 补充缺失的列，并执行依赖这些列的 schema
+func migrate() error {
+	for _, m := range columnMigrations {
+		exists, err := columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := DB.Exec("ALTER TABLE " + m.table + " ADD COLUMN " + m.ddl); err != nil {
+			return fmt.Errorf("添加列 %s.%s 失败: %w", m.table, m.column, err)
+		}
+		log.Printf("🔧 数据库迁移: 添加列 %s.%s", m.table, m.column)
+	}
+
+	if _, err := DB.Exec(migratedSchema); err != nil {
+		return fmt.Errorf("创建索引失败: %w", err)
+	}
+
+	// 回填历史书签的域名
+	if _, err := DB.Exec("UPDATE bookmarks SET domain = " + domainExpr("url") + " WHERE domain IS NULL OR domain = ''"); err != nil {
+		return fmt.Errorf("回填书签域名失败: %w", err)
+	}
+
+	if err := backfillCanonicalURLs(); err != nil {
+		return fmt.Errorf("回填规范 URL 失败: %w", err)
+	}
+
+	// 为还没有历史版本的书签记录初始版本
+	if _, err := DB.Exec(baselineRevisionsSQL); err != nil {
+		return fmt.Errorf("记录书签初始版本失败: %w", err)
+	}
+
+	return nil
+}
+
+// baselineRevisionsSQL 以书签当前内容生成初始版本，快照格式与 models.BookmarkSnapshot 一致
+var baselineRevisionsSQL = `
+	INSERT INTO bookmark_revisions (bookmark_id, source, snapshot, date_created)
+	SELECT b.id, 'baseline', json_object(
+		'url', b.url,
+		'title', COALESCE(b.title, ''),
+		'description', COALESCE(b.description, ''),
+		'notes', COALESCE(b.notes, ''),
+		'is_favorite', json(CASE WHEN b.is_favorite THEN 'true' ELSE 'false' END),
+		'unread', json(CASE WHEN b.unread THEN 'true' ELSE 'false' END),
+		'shared', json(CASE WHEN b.shared THEN 'true' ELSE 'false' END),
+		'is_archived', json(CASE WHEN b.is_archived THEN 'true' ELSE 'false' END),
+		'tag_names', (
+			SELECT json_group_array(name) FROM (
+				SELECT t.name FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id
+				WHERE bt.bookmark_id = b.id ORDER BY t.name
+			)
+		)
+	), COALESCE(b.date_modified, b.date_added)
+	FROM bookmarks b
+	WHERE NOT EXISTS (SELECT 1 FROM bookmark_revisions r WHERE r.bookmark_id = b.id)
+`
+
+// migratedSchema 依赖迁移列的索引和触发器
+// 排序索引均为升序单列索引，隐含的 rowid 使其同时满足 (列, id) 两个方向的排序
+// date_added 显式声明为 (date_added, id)，替代旧版本的降序单列索引 idx_bookmarks_date_added 和与其重复的 idx_bookmarks_added_id
+var migratedSchema = `
+	DROP INDEX IF EXISTS idx_bookmarks_date_added;
+	DROP INDEX IF EXISTS idx_bookmarks_added_id;
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_date_added_id ON bookmarks(date_added, id);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_date_modified ON bookmarks(date_modified);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_title ON bookmarks(title COLLATE NOCASE);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_domain ON bookmarks(domain);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_is_archived ON bookmarks(is_archived);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_deleted_at ON bookmarks(deleted_at);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_canonical_url ON bookmarks(canonical_url);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_content_type ON bookmarks(content_type);
+	CREATE INDEX IF NOT EXISTS idx_bookmark_folders_folder_date ON bookmark_folders(folder_id, date_added DESC);
+
+	CREATE TRIGGER IF NOT EXISTS bookmarks_domain_insert AFTER INSERT ON bookmarks BEGIN
+		UPDATE bookmarks SET domain = ` + domainExpr("new.url") + ` WHERE id = new.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS bookmarks_domain_update AFTER UPDATE OF url ON bookmarks BEGIN
+		UPDATE bookmarks SET domain = ` + domainExpr("new.url") + ` WHERE id = new.id;
+	END;
+`
+
+// columnExists 检查表中是否存在指定列
+func columnExists(table, column string) (bool, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("读取表结构失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+// domainExpr 从 URL 列提取主机名（小写，去掉 www. 前缀）的 SQL 表达式
+func domainExpr(col string) string {
+	rest := "substr(" + col + ", instr(" + col + ", '://') + 3)"
+	host := "lower(substr(" + rest + ", 1, CASE WHEN instr(" + rest + ", '/') > 0 THEN instr(" + rest + ", '/') - 1 ELSE length(" + rest + ") END))"
+	return "CASE WHEN " + host + " LIKE 'www.%' THEN substr(" + host + ", 5) ELSE " + host + " END"
+}
@@ -0,0 +1,291 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"ai-bookmark-service/models"
+)
+
+// SearchQuery 解析后的搜索查询
+// 语法示例: tag:golang folder:Work is:unread site:github.com after:2025-01-01 -tag:archived "exact phrase"
+// 结构化元数据: author:张三 type:video lang:zh
+type SearchQuery struct {
+	Terms    []string      // 需要全文匹配的词或短语
+	Excluded []string      // 需要排除的词或短语（以 - 开头）
+	Filters  []QueryFilter // 结构化操作符
+}
+
+// QueryFilter 单个搜索操作符
+type QueryFilter struct {
+	Field  string // tag | folder | is | site | after | before | author | type | lang
+	Value  string
+	Negate bool
+	date   time.Time
+}
+
+// QueryError 搜索查询语法错误，指出出错的记号及其位置
+type QueryError struct {
+	Message  string `json:"message"`
+	Token    string `json:"token"`
+	Position int    `json:"position"` // 出错记号在查询串中的字符偏移（从0开始）
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("查询语法错误: %s (位置 %d: %q)", e.Message, e.Position, e.Token)
+}
+
+// queryOperators 支持的操作符
+var queryOperators = map[string]bool{
+	"tag":    true,
+	"folder": true,
+	"is":     true,
+	"site":   true,
+	"after":  true,
+	"before": true,
+	"author": true,
+	"type":   true,
+	"lang":   true,
+}
+
+// queryIsValues is: 操作符支持的取值
+var queryIsValues = map[string]bool{
+	"unread":     true,
+	"read":       true,
+	"favorite":   true,
+	"shared":     true,
+	"private":    true,
+	"untagged":   true,
+	"broken":     true,
+	"redirected": true,
+}
+
+// queryContentTypes type: 操作符支持的取值
+var queryContentTypes = map[string]bool{
+	models.ContentTypeArticle: true,
+	models.ContentTypeVideo:   true,
+	models.ContentTypeAudio:   true,
+	models.ContentTypeImage:   true,
+	models.ContentTypeRepo:    true,
+	models.ContentTypePaper:   true,
+	models.ContentTypeBook:    true,
+	models.ContentTypeProduct: true,
+}
+
+// queryDateLayouts after:/before: 支持的日期格式
+var queryDateLayouts = []string{"2006-01-02", "2006/01/02", "2006-01"}
+
+// queryToken 词法分析得到的记号
+type queryToken struct {
+	text   string // 原始文本
+	pos    int    // 字符偏移
+	negate bool
+	field  string // 操作符名，普通词为空
+	value  string // 去掉引号后的值
+}
+
+// ParseSearchQuery 解析搜索查询语言
+func ParseSearchQuery(q string) (*SearchQuery, error) {
+	tokens, err := tokenizeQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	sq := &SearchQuery{}
+	for _, tok := range tokens {
+		if tok.field == "" {
+			if tok.value == "" {
+				continue
+			}
+			if tok.negate {
+				sq.Excluded = append(sq.Excluded, tok.value)
+			} else {
+				sq.Terms = append(sq.Terms, tok.value)
+			}
+			continue
+		}
+
+		if tok.value == "" {
+			return nil, &QueryError{Message: fmt.Sprintf("操作符 %s: 缺少值", tok.field), Token: tok.text, Position: tok.pos}
+		}
+
+		filter := QueryFilter{Field: tok.field, Value: tok.value, Negate: tok.negate}
+		switch tok.field {
+		case "is":
+			filter.Value = strings.ToLower(tok.value)
+			if !queryIsValues[filter.Value] {
+				return nil, &QueryError{Message: fmt.Sprintf("不支持的 is: 取值 %q", tok.value), Token: tok.text, Position: tok.pos}
+			}
+		case "site":
+			filter.Value = strings.TrimPrefix(strings.ToLower(tok.value), "www.")
+		case "type":
+			filter.Value = strings.ToLower(tok.value)
+			if !queryContentTypes[filter.Value] {
+				return nil, &QueryError{Message: fmt.Sprintf("不支持的 type: 取值 %q", tok.value), Token: tok.text, Position: tok.pos}
+			}
+		case "lang":
+			filter.Value = strings.ToLower(strings.ReplaceAll(tok.value, "_", "-"))
+		case "after", "before":
+			date, ok := parseQueryDate(tok.value)
+			if !ok {
+				return nil, &QueryError{Message: fmt.Sprintf("无效的日期 %q，应为 YYYY-MM-DD", tok.value), Token: tok.text, Position: tok.pos}
+			}
+			filter.date = date
+		}
+		sq.Filters = append(sq.Filters, filter)
+	}
+
+	return sq, nil
+}
+
+// tokenizeQuery 将查询拆分为记号，支持引号短语和 - 前缀
+func tokenizeQuery(q string) ([]queryToken, error) {
+	runes := []rune(q)
+	tokens := []queryToken{}
+
+	i := 0
+	for i < len(runes) {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		tok := queryToken{pos: i}
+		start := i
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			tok.negate = true
+			i++
+		}
+
+		// 操作符: 由字母组成的前缀加冒号，且值不以 // 开头（避免把 URL 当作操作符）
+		j := i
+		for j < len(runes) && unicode.IsLetter(runes[j]) {
+			j++
+		}
+		if j > i && j < len(runes) && runes[j] == ':' && !strings.HasPrefix(string(runes[j+1:]), "//") {
+			field := strings.ToLower(string(runes[i:j]))
+			if !queryOperators[field] {
+				end := scanWord(runes, j)
+				return nil, &QueryError{Message: fmt.Sprintf("未知的搜索操作符 %q", field), Token: string(runes[start:end]), Position: start}
+			}
+			tok.field = field
+			i = j + 1
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &QueryError{Message: "引号未闭合", Token: string(runes[start:]), Position: start}
+			}
+			tok.value = strings.TrimSpace(string(runes[i+1 : end]))
+			i = end + 1
+		} else {
+			end := scanWord(runes, i)
+			tok.value = string(runes[i:end])
+			i = end
+		}
+
+		tok.text = string(runes[start:i])
+		tokens = append(tokens, tok)
+	}
+
+	return tokens, nil
+}
+
+// scanWord 返回从 i 开始到下一个空白字符的位置
+func scanWord(runes []rune, i int) int {
+	for i < len(runes) && !unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+// parseQueryDate 解析 after:/before: 的日期
+func parseQueryDate(value string) (time.Time, bool) {
+	for _, layout := range queryDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// tagFilterClause 按标签名过滤的子句
+const tagFilterClause = `b.id IN (SELECT bt.bookmark_id FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id WHERE t.name = ? COLLATE NOCASE)`
+
+// compileQueryFilters 将操作符编译为 WHERE 条件
+func compileQueryFilters(sq *SearchQuery) ([]string, []interface{}) {
+	clauses := []string{}
+	args := []interface{}{}
+
+	for _, f := range sq.Filters {
+		var clause string
+		var fargs []interface{}
+
+		switch f.Field {
+		case "tag":
+			clause = tagFilterClause
+			fargs = []interface{}{f.Value}
+		case "folder":
+			clause = "b.id IN (SELECT bf.bookmark_id FROM bookmark_folders bf JOIN folders f ON f.id = bf.folder_id WHERE f.name = ? COLLATE NOCASE)"
+			fargs = []interface{}{f.Value}
+		case "site":
+			clause = "(b.domain = ? OR b.domain LIKE ?)"
+			fargs = []interface{}{f.Value, "%." + f.Value}
+		case "author":
+			clause = "COALESCE(b.author, '') LIKE ?"
+			fargs = []interface{}{"%" + f.Value + "%"}
+		case "type":
+			clause = "COALESCE(b.content_type, '') = ?"
+			fargs = []interface{}{f.Value}
+		case "lang":
+			// lang:zh 同时匹配 zh-CN、zh-TW 等
+			clause = "(lower(COALESCE(b.language, '')) = ? OR lower(COALESCE(b.language, '')) LIKE ?)"
+			fargs = []interface{}{f.Value, f.Value + "-%"}
+		case "after":
+			clause = "b.date_added >= ?"
+			fargs = []interface{}{f.date.UTC().Format(time.RFC3339Nano)}
+		case "before":
+			clause = "b.date_added < ?"
+			fargs = []interface{}{f.date.UTC().Format(time.RFC3339Nano)}
+		case "is":
+			switch f.Value {
+			case "unread":
+				clause = "b.unread = 1"
+			case "read":
+				clause = "b.unread = 0"
+			case "favorite":
+				clause = "b.is_favorite = 1"
+			case "shared":
+				clause = "b.shared = 1"
+			case "private":
+				clause = "b.shared = 0"
+			case "untagged":
+				clause = "b.id NOT IN (SELECT bookmark_id FROM bookmark_tags)"
+			case "broken":
+				clause = brokenLinkClause
+			case "redirected":
+				clause = redirectedLinkClause
+			}
+		}
+
+		if f.Negate {
+			clause = "NOT " + clause
+		}
+		clauses = append(clauses, clause)
+		args = append(args, fargs...)
+	}
+
+	for _, term := range sq.Excluded {
+		clause, targs := excludeTermClause(term)
+		clauses = append(clauses, clause)
+		args = append(args, targs...)
+	}
+
+	return clauses, args
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	tests := []struct {
+		q    string
+		want *SearchQuery
+	}{
+		{"golang 并发", &SearchQuery{Terms: []string{"golang", "并发"}}},
+		{"tag:golang", &SearchQuery{Filters: []QueryFilter{{Field: "tag", Value: "golang"}}}},
+		{`TAG:"机器 学习"`, &SearchQuery{Filters: []QueryFilter{{Field: "tag", Value: "机器 学习"}}}},
+		{"folder:Work", &SearchQuery{Filters: []QueryFilter{{Field: "folder", Value: "Work"}}}},
+		{"is:Unread is:favorite", &SearchQuery{Filters: []QueryFilter{{Field: "is", Value: "unread"}, {Field: "is", Value: "favorite"}}}},
+		{"site:WWW.GitHub.com", &SearchQuery{Filters: []QueryFilter{{Field: "site", Value: "github.com"}}}},
+		{"after:2025-01-01", &SearchQuery{Filters: []QueryFilter{{Field: "after", Value: "2025-01-01", date: date("2025-01-01")}}}},
+		{"before:2025/02/03", &SearchQuery{Filters: []QueryFilter{{Field: "before", Value: "2025/02/03", date: date("2025-02-03")}}}},
+		{"after:2025-03", &SearchQuery{Filters: []QueryFilter{{Field: "after", Value: "2025-03", date: date("2025-03-01")}}}},
+		{"-tag:archived -is:read", &SearchQuery{Filters: []QueryFilter{{Field: "tag", Value: "archived", Negate: true}, {Field: "is", Value: "read", Negate: true}}}},
+		{`"exact phrase" -spam -"bad phrase"`, &SearchQuery{Terms: []string{"exact phrase"}, Excluded: []string{"spam", "bad phrase"}}},
+		{`"  " - a-b`, &SearchQuery{Terms: []string{"-", "a-b"}}},
+		{"https://example.com/a:b", &SearchQuery{Terms: []string{"https://example.com/a:b"}}},
+		{
+			`tag:golang folder:Work is:unread site:github.com after:2025-01-01 -tag:archived "exact phrase"`,
+			&SearchQuery{
+				Terms: []string{"exact phrase"},
+				Filters: []QueryFilter{
+					{Field: "tag", Value: "golang"},
+					{Field: "folder", Value: "Work"},
+					{Field: "is", Value: "unread"},
+					{Field: "site", Value: "github.com"},
+					{Field: "after", Value: "2025-01-01", date: date("2025-01-01")},
+					{Field: "tag", Value: "archived", Negate: true},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseSearchQuery(tc.q)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q): %v", tc.q, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseSearchQuery(%q) = %+v，期望 %+v", tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		q         string
+		wantToken string
+		wantPos   int
+	}{
+		{"golang foo:bar", "foo:bar", 7},
+		{"中文 -bad:x", "-bad:x", 3},
+		{"tag:go after:2025-13-01", "after:2025-13-01", 7},
+		{"before:yesterday", "before:yesterday", 0},
+		{`tag:x "unterminated phrase`, `"unterminated phrase`, 6},
+		{`标签 tag:"机器`, `tag:"机器`, 3},
+		{"is:archived", "is:archived", 0},
+		{"type:movie", "type:movie", 0},
+		{"a tag:", "tag:", 2},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseSearchQuery(tc.q)
+		var qerr *QueryError
+		if !errors.As(err, &qerr) {
+			t.Errorf("ParseSearchQuery(%q) = %v，期望 *QueryError", tc.q, err)
+			continue
+		}
+		if qerr.Token != tc.wantToken || qerr.Position != tc.wantPos {
+			t.Errorf("ParseSearchQuery(%q) 出错记号 %q 位置 %d，期望 %q 位置 %d", tc.q, qerr.Token, qerr.Position, tc.wantToken, tc.wantPos)
+		}
+	}
+}
+
+// listURLs 按查询语言过滤书签，返回排序后的 URL
+func listURLs(t *testing.T, repo *BookmarkRepository, q string) []string {
+	t.Helper()
+	bookmarks, err := repo.List(100, 0, map[string]interface{}{"q": q})
+	if err != nil {
+		t.Fatalf("List(q=%q): %v", q, err)
+	}
+	urls := []string{}
+	for _, bm := range bookmarks {
+		urls = append(urls, bm.URL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func TestListWithQueryOperators(t *testing.T) {
+	initTestDB(t)
+	repo := NewBookmarkRepository()
+	folders := NewFolderRepository(repo)
+
+	items := []struct {
+		create models.BookmarkCreate
+		added  string
+	}{
+		{models.BookmarkCreate{URL: "https://github.com/golang/go", Title: "Go 仓库", TagNames: []string{"Golang"}, Unread: true}, "2025-01-10T00:00:00Z"},
+		{models.BookmarkCreate{URL: "https://gist.github.com/x", Title: "代码片段", TagNames: []string{"golang", "archived"}, IsFavorite: true}, "2024-12-01T00:00:00Z"},
+		{models.BookmarkCreate{URL: "https://www.example.com/", Title: "示例页面"}, "2025-02-10T00:00:00Z"},
+	}
+	ids := make([]int, len(items))
+	for i, item := range items {
+		create := item.create
+		bm, err := repo.Create(&create)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DB.Exec("UPDATE bookmarks SET date_added = ? WHERE id = ?", item.added, bm.ID); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = bm.ID
+	}
+	work, err := folders.Create(&models.FolderCreate{Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := folders.AddBookmark(ids[2], work.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	goRepo, gist, example := items[0].create.URL, items[1].create.URL, items[2].create.URL
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"tag:golang", []string{gist, goRepo}},
+		{"tag:golang -tag:archived", []string{goRepo}},
+		{"folder:work", []string{example}},
+		{"is:unread", []string{goRepo}},
+		{"is:favorite", []string{gist}},
+		{"is:untagged", []string{example}},
+		{"site:github.com", []string{gist, goRepo}},
+		{"site:example.com", []string{example}},
+		{"-site:github.com", []string{example}},
+		{"after:2025-01-01", []string{goRepo, example}},
+		{"before:2025-01-01", []string{gist}},
+		{"after:2025-01-01 before:2025-02", []string{goRepo}},
+	}
+	for _, tc := range tests {
+		if got := listURLs(t, repo, tc.q); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("q=%q 返回 %q，期望 %q", tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestListWithMetadataOperators(t *testing.T) {
+	initTestDB(t)
+	repo := NewBookmarkRepository()
+
+	items := []struct {
+		url      string
+		metadata models.PageMetadata
+	}{
+		{"https://example.com/video", models.PageMetadata{Author: "张三, Alice Smith", ContentType: models.ContentTypeVideo, Language: "zh-CN"}},
+		{"https://example.com/article", models.PageMetadata{Author: "Bob", ContentType: models.ContentTypeArticle, Language: "en-US"}},
+		{"https://example.com/paper", models.PageMetadata{Author: "alice smith", ContentType: models.ContentTypePaper, Language: "zh"}},
+		{"https://example.com/unknown", models.PageMetadata{}},
+	}
+	for _, item := range items {
+		bm, err := repo.Create(&models.BookmarkCreate{URL: item.url})
+		if err != nil {
+			t.Fatal(err)
+		}
+		metadata := item.metadata
+		if err := repo.UpdateStructuredMetadata(bm.ID, &metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	video, article, paper, unknown := items[0].url, items[1].url, items[2].url, items[3].url
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"author:alice", []string{paper, video}},
+		{`author:"Alice Smith"`, []string{paper, video}},
+		{"author:张三", []string{video}},
+		{"-author:bob", []string{paper, unknown, video}},
+		{"type:video", []string{video}},
+		{"type:Paper", []string{paper}},
+		{"-type:video", []string{article, paper, unknown}},
+		{"lang:zh", []string{paper, video}},
+		{"lang:zh_CN", []string{video}},
+		{"lang:en", []string{article}},
+		{"lang:en-us", []string{article}},
+		{"lang:z", []string{}},
+		{"type:article lang:en author:bob", []string{article}},
+	}
+	for _, tc := range tests {
+		if got := listURLs(t, repo, tc.q); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("q=%q 返回 %q，期望 %q", tc.q, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// ErrScraperProfileExists 同一域名已存在抓取配置
+var ErrScraperProfileExists = errors.New("该域名的抓取配置已存在")
+
+// ScraperProfileRepository 按域名的抓取配置数据库操作
+type ScraperProfileRepository struct {
+	db *sql.DB
+}
+
+// NewScraperProfileRepository 创建抓取配置仓库
+func NewScraperProfileRepository() *ScraperProfileRepository {
+	return &ScraperProfileRepository{db: DB}
+}
+
+// scraperProfileColumns 抓取配置查询列，与 scanScraperProfile 对应
+const scraperProfileColumns = "id, domain, headers, cookies, proxy_url, timeout, disabled, date_created, date_modified"
+
+// List 获取所有抓取配置（按域名排序）
+func (r *ScraperProfileRepository) List() ([]*models.ScraperProfile, error) {
+	rows, err := r.db.Query("SELECT " + scraperProfileColumns + " FROM scraper_profiles ORDER BY domain")
+	if err != nil {
+		return nil, fmt.Errorf("查询抓取配置失败: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []*models.ScraperProfile{}
+	for rows.Next() {
+		p, err := scanScraperProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// GetByID 获取抓取配置，不存在时返回 sql.ErrNoRows
+func (r *ScraperProfileRepository) GetByID(id int) (*models.ScraperProfile, error) {
+	return scanScraperProfile(r.db.QueryRow("SELECT "+scraperProfileColumns+" FROM scraper_profiles WHERE id = ?", id))
+}
+
+// Create 创建抓取配置，域名已存在时返回 ErrScraperProfileExists
+func (r *ScraperProfileRepository) Create(p *models.ScraperProfile) (*models.ScraperProfile, error) {
+	if err := r.checkDomain(p.Domain, 0); err != nil {
+		return nil, err
+	}
+	headers, err := json.Marshal(p.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求头失败: %w", err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	result, err := r.db.Exec(
+		"INSERT INTO scraper_profiles (domain, headers, cookies, proxy_url, timeout, disabled, date_created, date_modified) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		p.Domain, string(headers), p.Cookies, p.ProxyURL, p.Timeout, p.Disabled, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建抓取配置失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取抓取配置ID失败: %w", err)
+	}
+	return r.GetByID(int(id))
+}
+
+// Update 更新抓取配置的全部字段，不存在时返回 sql.ErrNoRows
+func (r *ScraperProfileRepository) Update(id int, p *models.ScraperProfile) (*models.ScraperProfile, error) {
+	if err := r.checkDomain(p.Domain, id); err != nil {
+		return nil, err
+	}
+	headers, err := json.Marshal(p.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求头失败: %w", err)
+	}
+
+	result, err := r.db.Exec(
+		"UPDATE scraper_profiles SET domain = ?, headers = ?, cookies = ?, proxy_url = ?, timeout = ?, disabled = ?, date_modified = ? WHERE id = ?",
+		p.Domain, string(headers), p.Cookies, p.ProxyURL, p.Timeout, p.Disabled, time.Now().UTC().Format(time.RFC3339Nano), id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("更新抓取配置失败: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetByID(id)
+}
+
+// Delete 删除抓取配置，不存在时返回 sql.ErrNoRows
+func (r *ScraperProfileRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM scraper_profiles WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除抓取配置失败: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// checkDomain 检查域名是否已被其他配置使用
+func (r *ScraperProfileRepository) checkDomain(domain string, excludeID int) error {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM scraper_profiles WHERE domain = ? AND id != ?)", domain, excludeID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("查询抓取配置失败: %w", err)
+	}
+	if exists {
+		return ErrScraperProfileExists
+	}
+	return nil
+}
+
+// scanScraperProfile 扫描单条抓取配置
+func scanScraperProfile(row interface{ Scan(...interface{}) error }) (*models.ScraperProfile, error) {
+	var p models.ScraperProfile
+	var headers string
+	err := row.Scan(&p.ID, &p.Domain, &headers, &p.Cookies, &p.ProxyURL, &p.Timeout, &p.Disabled, &p.DateCreated, &p.DateModified)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(headers), &p.Headers)
+	if p.Headers == nil {
+		p.Headers = map[string]string{}
+	}
+	return &p, nil
+}
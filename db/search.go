@@ -0,0 +1,177 @@
+package db
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// ftsSchema 全文索引及同步触发器
+// 使用 trigram 分词器，中文等无空格语言也能按子串检索
+const ftsSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS bookmarks_fts USING fts5(
+		title, description, notes, url, tags,
+		tokenize = 'trigram case_sensitive 0'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS bookmarks_fts_insert AFTER INSERT ON bookmarks BEGIN
+		INSERT INTO bookmarks_fts (rowid, title, description, notes, url, tags)
+		VALUES (new.id, new.title, new.description, new.notes, new.url, '');
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS bookmarks_fts_update AFTER UPDATE OF title, description, notes, url ON bookmarks BEGIN
+		UPDATE bookmarks_fts
+		SET title = new.title, description = new.description, notes = new.notes, url = new.url
+		WHERE rowid = new.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS bookmarks_fts_delete AFTER DELETE ON bookmarks BEGIN
+		DELETE FROM bookmarks_fts WHERE rowid = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS bookmark_tags_fts_insert AFTER INSERT ON bookmark_tags BEGIN
+		UPDATE bookmarks_fts SET tags = (` + ftsTagsSubquery + `new.bookmark_id)
+		WHERE rowid = new.bookmark_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS bookmark_tags_fts_delete AFTER DELETE ON bookmark_tags BEGIN
+		UPDATE bookmarks_fts SET tags = (` + ftsTagsSubquery + `old.bookmark_id)
+		WHERE rowid = old.bookmark_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS tags_fts_update AFTER UPDATE OF name ON tags BEGIN
+		UPDATE bookmarks_fts SET tags = (` + ftsTagsSubquery + `bookmarks_fts.rowid)
+		WHERE rowid IN (SELECT bookmark_id FROM bookmark_tags WHERE tag_id = new.id);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS tags_fts_delete AFTER DELETE ON tags BEGIN
+		UPDATE bookmarks_fts SET tags = (` + ftsTagsSubquery + `bookmarks_fts.rowid)
+		WHERE rowid IN (SELECT bookmark_id FROM bookmark_tags WHERE tag_id = old.id);
+	END;
+`
+
+// ftsTagsSubquery 拼接书签的所有标签名（调用处补全书签ID表达式）
+const ftsTagsSubquery = `SELECT COALESCE(GROUP_CONCAT(t.name, ' '), '') FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.bookmark_id = `
+
+// ftsMinTermLength trigram 分词器要求的最短检索词长度（字符数）
+const ftsMinTermLength = 3
+
+// initFTS 创建全文索引，索引与书签表不一致时全量重建
+func initFTS() error {
+	if _, err := DB.Exec(ftsSchema); err != nil {
+		return fmt.Errorf("创建全文索引失败: %w", err)
+	}
+
+	var bookmarkCount, indexedCount int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM bookmarks").Scan(&bookmarkCount); err != nil {
+		return err
+	}
+	if err := DB.QueryRow("SELECT COUNT(*) FROM bookmarks_fts").Scan(&indexedCount); err != nil {
+		return err
+	}
+	if bookmarkCount == indexedCount {
+		return nil
+	}
+
+	log.Printf("🔎 重建全文索引: 书签%d条, 已索引%d条", bookmarkCount, indexedCount)
+	return RebuildFTS()
+}
+
+// RebuildFTS 全量重建全文索引
+func RebuildFTS() error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM bookmarks_fts"); err != nil {
+		return fmt.Errorf("清空全文索引失败: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO bookmarks_fts (rowid, title, description, notes, url, tags)
+		SELECT b.id, b.title, b.description, b.notes, b.url, (` + ftsTagsSubquery + `b.id)
+		FROM bookmarks b
+	`); err != nil {
+		return fmt.Errorf("写入全文索引失败: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ftsLikeColumns LIKE 退化匹配时拼接的索引列
+const ftsLikeColumns = "(title || ' ' || description || ' ' || notes || ' ' || url || ' ' || tags)"
+
+// searchJoin 构建全文搜索子查询的 JOIN 子句，terms 为检索词或短语
+// 返回的子查询别名为 s，提供 fts_rank（越小越相关）和 fts_snippet 两列
+// 检索词都不少于3个字符时使用 FTS5 MATCH + BM25 排序，否则退化为对索引列的 LIKE 匹配
+func searchJoin(terms []string) (string, []interface{}) {
+	if len(terms) == 0 {
+		return "", nil
+	}
+
+	useMatch := true
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < ftsMinTermLength {
+			useMatch = false
+			break
+		}
+	}
+
+	if useMatch {
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = ftsQuote(term)
+		}
+		// 权重顺序: title, description, notes, url, tags
+		// LIMIT -1 阻止 SQLite 展开子查询，bm25/snippet 只能在 FTS 查询上下文中调用
+		join := ` JOIN (
+			SELECT rowid AS fts_rowid,
+				bm25(bookmarks_fts, 10.0, 4.0, 2.0, 3.0, 6.0) AS fts_rank,
+				snippet(bookmarks_fts, -1, '` + snippetMarkStart + `', '` + snippetMarkEnd + `', '…', 16) AS fts_snippet
+			FROM bookmarks_fts WHERE bookmarks_fts MATCH ? LIMIT -1
+		) s ON s.fts_rowid = b.id`
+		return join, []interface{}{strings.Join(quoted, " ")}
+	}
+
+	conds := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		conds[i] = ftsLikeColumns + " LIKE ?"
+		args[i] = "%" + term + "%"
+	}
+	join := ` JOIN (
+		SELECT rowid AS fts_rowid, NULL AS fts_rank, NULL AS fts_snippet
+		FROM bookmarks_fts WHERE ` + strings.Join(conds, " AND ") + `
+	) s ON s.fts_rowid = b.id`
+	return join, args
+}
+
+// snippetMarkStart/snippetMarkEnd snippet() 中标记命中位置的私用区字符，转义后再替换为 <mark> 标签
+const (
+	snippetMarkStart = "\uE000"
+	snippetMarkEnd   = "\uE001"
+)
+
+// snippetHighlighter 将命中标记替换为 <mark> 标签
+var snippetHighlighter = strings.NewReplacer(snippetMarkStart, "<mark>", snippetMarkEnd, "</mark>")
+
+// highlightSnippet 对命中片段做 HTML 转义（内容来自用户输入和抓取的网页），再以 <mark> 包裹关键词
+func highlightSnippet(snippet string) string {
+	return snippetHighlighter.Replace(html.EscapeString(snippet))
+}
+
+// excludeTermClause 构建排除检索词的 WHERE 条件
+func excludeTermClause(term string) (string, []interface{}) {
+	if utf8.RuneCountInString(term) >= ftsMinTermLength {
+		return "b.id NOT IN (SELECT rowid FROM bookmarks_fts WHERE bookmarks_fts MATCH ?)", []interface{}{ftsQuote(term)}
+	}
+	return "b.id NOT IN (SELECT rowid FROM bookmarks_fts WHERE " + ftsLikeColumns + " LIKE ?)", []interface{}{"%" + term + "%"}
+}
+
+// ftsQuote 将检索词转为 FTS5 短语，避免其中的特殊字符被解析为查询语法
+func ftsQuote(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
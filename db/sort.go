@@ -0,0 +1,97 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 书签排序字段
+const (
+	SortAdded     = "added"
+	SortModified  = "modified"
+	SortTitle     = "title"
+	SortDomain    = "domain"
+	SortRelevance = "relevance"
+)
+
+// BookmarkSort 书签排序方式
+type BookmarkSort struct {
+	Field string
+	Desc  bool
+}
+
+// sortColumns 排序字段对应的列，同值时按 id 同向排列（与单列索引的顺序一致，避免额外排序）
+var sortColumns = map[string]string{
+	SortAdded:    "b.date_added",
+	SortModified: "b.date_modified",
+	SortTitle:    "b.title COLLATE NOCASE",
+	SortDomain:   "b.domain",
+}
+
+// ParseBookmarkSort 解析 sort 参数，格式为 字段 或 字段_asc / 字段_desc
+// 未指定方向时日期和相关度默认降序，标题和域名默认升序；空字符串返回 nil
+func ParseBookmarkSort(s string) (*BookmarkSort, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil, nil
+	}
+
+	field, dir := s, ""
+	if i := strings.LastIndex(s, "_"); i > 0 {
+		field, dir = s[:i], s[i+1:]
+	}
+
+	if _, ok := sortColumns[field]; !ok && field != SortRelevance {
+		return nil, fmt.Errorf("不支持的排序字段: %s", field)
+	}
+
+	sort := &BookmarkSort{Field: field}
+	switch dir {
+	case "":
+		sort.Desc = field != SortTitle && field != SortDomain
+	case "asc":
+		sort.Desc = false
+	case "desc":
+		sort.Desc = true
+	default:
+		return nil, fmt.Errorf("不支持的排序方向: %s", dir)
+	}
+	return sort, nil
+}
+
+// String 返回 字段_方向 形式的排序参数
+func (s *BookmarkSort) String() string {
+	if s.Desc {
+		return s.Field + "_desc"
+	}
+	return s.Field + "_asc"
+}
+
+// orderBy 生成 ORDER BY 子句；searching 表示查询包含全文检索（可按相关度排序）
+// 按相关度排序但没有检索词时退化为按添加时间
+func (s *BookmarkSort) orderBy(searching bool) string {
+	if s.Field == SortRelevance {
+		if !searching {
+			return (&BookmarkSort{Field: SortAdded, Desc: s.Desc}).orderBy(false)
+		}
+		// fts_rank 越小越相关
+		if s.Desc {
+			return "s.fts_rank, b.date_added DESC"
+		}
+		return "s.fts_rank DESC, b.date_added DESC"
+	}
+
+	dir := " ASC"
+	if s.Desc {
+		dir = " DESC"
+	}
+	return sortColumns[s.Field] + dir + ", b.id" + dir
+}
+
+// defaultBookmarkSort 未指定排序时的默认值：有检索词按相关度，否则按添加时间倒序
+func defaultBookmarkSort(searching bool) *BookmarkSort {
+	if searching {
+		return &BookmarkSort{Field: SortRelevance, Desc: true}
+	}
+	return &BookmarkSort{Field: SortAdded, Desc: true}
+}
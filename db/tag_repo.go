@@ -9,7 +9,8 @@ import (
 
 // TagRepository 标签数据库操作
 type TagRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	source string // 修改书签标签时记录的历史版本来源，为空时为 user
 }
 
 // NewTagRepository 创建标签仓库
@@ -17,6 +18,11 @@ func NewTagRepository() *TagRepository {
 	return &TagRepository{db: DB}
 }
 
+// WithSource 返回以指定来源记录书签历史版本的仓库副本
+func (r *TagRepository) WithSource(source string) *TagRepository {
+	return &TagRepository{db: r.db, source: source}
+}
+
 // GetByID 根据 ID 获取标签
 func (r *TagRepository) GetByID(id int) (*models.Tag, error) {
 	var tag models.Tag
@@ -81,6 +87,35 @@ func (r *TagRepository) List() ([]*models.Tag, error) {
 	return tags, nil
 }
 
+// ListPage 分页获取标签（按名称排序），同时返回标签总数
+func (r *TagRepository) ListPage(limit, offset int) ([]*models.Tag, int, error) {
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM tags").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计标签数量失败: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, name, COALESCE(category, 'candidate'), COALESCE(usage_count, 0), 
+		       COALESCE(last_used, date_added), date_added 
+		FROM tags ORDER BY name LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询标签列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Category, &tag.UsageCount, &tag.LastUsed, &tag.DateAdded); err != nil {
+			continue
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, total, nil
+}
+
 // ListByCategories 根据分类获取标签
 func (r *TagRepository) ListByCategories(categories []string) ([]*models.Tag, error) {
 	if len(categories) == 0 {
@@ -133,37 +168,36 @@ func (r *TagRepository) UpdateCategory(tagID int, category string) error {
 	return nil
 }
 
-// GetBookmarkCount 获取标签关联的书签数量
+// GetBookmarkCount 获取标签关联的书签数量（不含回收站中的书签）
 func (r *TagRepository) GetBookmarkCount(tagID int) (int, error) {
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM bookmark_tags WHERE tag_id = ?", tagID).Scan(&count)
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM bookmark_tags bt JOIN bookmarks b ON b.id = bt.bookmark_id WHERE bt.tag_id = ? AND b.deleted_at IS NULL",
+		tagID,
+	).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("查询书签数量失败: %w", err)
 	}
 	return count, nil
 }
 
-// MergeBookmarks 将源标签的所有书签关联转移到目标标签
+// MergeBookmarks 将源标签的所有书签关联转移到目标标签，并为受影响的书签记录历史版本
 func (r *TagRepository) MergeBookmarks(sourceID, targetID int) error {
-	// 1. 获取源标签的所有书签
-	rows, err := r.db.Query("SELECT bookmark_id FROM bookmark_tags WHERE tag_id = ?", sourceID)
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("查询源标签书签失败: %w", err)
+		return fmt.Errorf("开始事务失败: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	bookmarkIDs := []int{}
-	for rows.Next() {
-		var id int
-		if err := rows.Scan(&id); err != nil {
-			continue
-		}
-		bookmarkIDs = append(bookmarkIDs, id)
+	// 1. 获取源标签的所有书签
+	bookmarkIDs, err := tagBookmarkIDsTx(tx, sourceID)
+	if err != nil {
+		return fmt.Errorf("查询源标签书签失败: %w", err)
 	}
 
 	// 2. 为每个书签添加目标标签关联(忽略重复)
 	for _, bmID := range bookmarkIDs {
-		_, err := r.db.Exec(`
+		_, err := tx.Exec(`
 			INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id) 
 			VALUES (?, ?)
 		`, bmID, targetID)
@@ -173,12 +207,15 @@ func (r *TagRepository) MergeBookmarks(sourceID, targetID int) error {
 	}
 
 	// 3. 删除源标签的所有关联
-	_, err = r.db.Exec("DELETE FROM bookmark_tags WHERE tag_id = ?", sourceID)
+	_, err = tx.Exec("DELETE FROM bookmark_tags WHERE tag_id = ?", sourceID)
 	if err != nil {
 		return fmt.Errorf("删除源标签关联失败: %w", err)
 	}
 
-	return nil
+	if err := r.recordRevisionsTx(tx, bookmarkIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // RecordSynonym 记录同义词关系
@@ -199,12 +236,58 @@ func (r *TagRepository) RecordSynonym(mainTagID, synonymTagID int, similarity fl
 	return nil
 }
 
-// Delete 删除标签
+// Delete 删除标签及其书签关联，并为受影响的书签记录历史版本
 func (r *TagRepository) Delete(tagID int) error {
-	_, err := r.db.Exec("DELETE FROM tags WHERE id = ?", tagID)
+	tx, err := r.db.Begin()
 	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	bookmarkIDs, err := tagBookmarkIDsTx(tx, tagID)
+	if err != nil {
+		return fmt.Errorf("查询标签书签失败: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM bookmark_tags WHERE tag_id = ?", tagID); err != nil {
+		return fmt.Errorf("删除标签关联失败: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", tagID); err != nil {
 		return fmt.Errorf("删除标签失败: %w", err)
 	}
+
+	if err := r.recordRevisionsTx(tx, bookmarkIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// tagBookmarkIDsTx 查询关联了指定标签的书签 ID
+func tagBookmarkIDsTx(tx *sql.Tx, tagID int) ([]int, error) {
+	rows, err := tx.Query("SELECT bookmark_id FROM bookmark_tags WHERE tag_id = ?", tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// recordRevisionsTx 为标签变化的书签记录历史版本
+func (r *TagRepository) recordRevisionsTx(tx *sql.Tx, bookmarkIDs []int) error {
+	revisions := &BookmarkRepository{db: r.db, source: r.source}
+	for _, id := range bookmarkIDs {
+		if err := revisions.recordRevisionTx(tx, id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
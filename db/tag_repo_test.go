@@ -0,0 +1,78 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ai-bookmark-service/models"
+)
+
+// initTestDB 使用临时数据库初始化全局连接
+func initTestDB(t *testing.T) {
+	t.Helper()
+	if err := Init(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() { Close() })
+}
+
+// lastRevision 返回书签最新的历史版本
+func lastRevision(t *testing.T, repo *BookmarkRepository, bookmarkID int) *models.BookmarkRevision {
+	t.Helper()
+	revisions, err := repo.ListRevisions(bookmarkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) == 0 {
+		t.Fatalf("书签 %d 没有历史版本", bookmarkID)
+	}
+	return revisions[len(revisions)-1]
+}
+
+func TestTagChangesRecordRevisions(t *testing.T) {
+	initTestDB(t)
+	bookmarks := NewBookmarkRepository()
+	tags := NewTagRepository()
+
+	bm, err := bookmarks.Create(&models.BookmarkCreate{URL: "https://example.com/", TagNames: []string{"golang", "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceID, err := tags.GetOrCreate("golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetID, err := tags.GetOrCreate("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tags.WithSource(models.RevisionSourceWorkflow).MergeBookmarks(sourceID, targetID); err != nil {
+		t.Fatal(err)
+	}
+	rev := lastRevision(t, bookmarks, bm.ID)
+	if rev.Source != models.RevisionSourceWorkflow || !reflect.DeepEqual(rev.Snapshot.TagNames, []string{"go", "web"}) {
+		t.Errorf("合并标签后的版本 = %s %v", rev.Source, rev.Snapshot.TagNames)
+	}
+
+	webID, err := tags.GetOrCreate("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Delete(webID); err != nil {
+		t.Fatal(err)
+	}
+	rev = lastRevision(t, bookmarks, bm.ID)
+	if rev.Source != models.RevisionSourceUser || !reflect.DeepEqual(rev.Snapshot.TagNames, []string{"go"}) {
+		t.Errorf("删除标签后的版本 = %s %v", rev.Source, rev.Snapshot.TagNames)
+	}
+
+	got, err := bookmarks.GetByID(bm.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.TagNames, []string{"go"}) {
+		t.Errorf("书签标签 = %v", got.TagNames)
+	}
+}
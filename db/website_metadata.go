@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// nullableColumns 书签的可空列: 网站元数据（未抓取时为 NULL，对外返回 null，与 linkding 一致）、发布时间和删除时间
+type nullableColumns struct {
+	title         sql.NullString
+	description   sql.NullString
+	favicon       sql.NullString
+	previewImage  sql.NullString
+	datePublished sql.NullTime
+	siteDetails   sql.NullString
+	deletedAt     sql.NullTime
+}
+
+// apply 将可空列写入书签
+func (c *nullableColumns) apply(bm *models.Bookmark) {
+	bm.WebsiteTitle = nullStringPtr(c.title)
+	bm.WebsiteDescription = nullStringPtr(c.description)
+	bm.FaviconURL = nullStringPtr(c.favicon)
+	bm.PreviewImageURL = nullStringPtr(c.previewImage)
+	if c.datePublished.Valid {
+		datePublished := c.datePublished.Time
+		bm.DatePublished = &datePublished
+	}
+	if c.siteDetails.Valid && c.siteDetails.String != "" {
+		json.Unmarshal([]byte(c.siteDetails.String), &bm.SiteDetails)
+	}
+	if c.deletedAt.Valid {
+		deletedAt := c.deletedAt.Time
+		bm.DateDeleted = &deletedAt
+	}
+}
+
+// UpdateWebsiteMetadata 保存抓取到的网站元数据，空值保存为 NULL
+func (r *BookmarkRepository) UpdateWebsiteMetadata(id int, title, description, faviconURL, previewImageURL string) error {
+	_, err := r.db.Exec(
+		"UPDATE bookmarks SET website_title = ?, website_description = ?, favicon_url = ?, preview_image_url = ? WHERE id = ?",
+		nullIfEmpty(title), nullIfEmpty(description), nullIfEmpty(faviconURL), nullIfEmpty(previewImageURL), id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新网站元数据失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateStructuredMetadata 保存抓取到的结构化元数据（作者、发布时间、内容类型、特定网站的附加信息等），空值保存为 NULL
+func (r *BookmarkRepository) UpdateStructuredMetadata(id int, metadata *models.PageMetadata) error {
+	var published interface{}
+	if metadata.PublishedAt != nil {
+		published = metadata.PublishedAt.UTC().Format(time.RFC3339Nano)
+	}
+	var readingTime interface{}
+	if metadata.ReadingTime > 0 {
+		readingTime = metadata.ReadingTime
+	}
+	var details interface{}
+	if len(metadata.Details) > 0 {
+		data, err := json.Marshal(metadata.Details)
+		if err != nil {
+			return fmt.Errorf("序列化网站附加信息失败: %w", err)
+		}
+		details = string(data)
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE bookmarks SET author = ?, date_published = ?, site_name = ?, website_canonical_url = ?,
+			content_type = ?, language = ?, reading_time = ?, site_details = ?
+		WHERE id = ?`,
+		nullIfEmpty(metadata.Author), published, nullIfEmpty(metadata.SiteName), nullIfEmpty(metadata.CanonicalURL),
+		nullIfEmpty(metadata.ContentType), nullIfEmpty(metadata.Language), readingTime, details, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新结构化元数据失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebArchiveSnapshotURL 保存外部存档服务返回的快照地址
+func (r *BookmarkRepository) UpdateWebArchiveSnapshotURL(id int, snapshotURL string) error {
+	if _, err := r.db.Exec("UPDATE bookmarks SET web_archive_snapshot_url = ? WHERE id = ?", snapshotURL, id); err != nil {
+		return fmt.Errorf("更新存档快照地址失败: %w", err)
+	}
+	return nil
+}
+
+// nullStringPtr NULL 转为 nil
+func nullStringPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	s := ns.String
+	return &s
+}
+
+// nullIfEmpty 空字符串转为 NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
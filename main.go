@@ -1,6 +1,7 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"io"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"ai-bookmark-service/api"
 	"ai-bookmark-service/config"
@@ -31,6 +33,13 @@ var (
 	tagOptimizer   *services.TagOptimizer
 	rateLimiter    *api.RateLimiter
 	aiWorkerPool   *services.AIWorkerPool
+	importer       *services.BookmarkImporter
+	exporter       *services.BookmarkExporter
+	bulkService    *services.BookmarkBulkService
+	metadataLoader *services.WebsiteMetadataLoader
+	pageArchiver   *services.PageArchiver
+	webArchiver    *services.WebArchiver
+	articleService *services.ArticleService
 )
 
 func main() {
@@ -51,6 +60,11 @@ func main() {
 	log.Printf("📊 异步AI: %v", cfg.EnableAsyncAI)
 	log.Printf("📊 限流启用: %v", cfg.RateLimitEnabled)
 
+	// 按域名的 URL 规范化规则需在数据库初始化（重新计算规范 URL）之前注册
+	if err := utils.LoadCanonicalRules(cfg.CanonicalRules); err != nil {
+		log.Fatalf("❌ CANONICAL_RULES 配置错误: %v", err)
+	}
+
 	// 2. 初始化数据库
 	if err := db.Init(cfg.DBPath); err != nil {
 		log.Fatalf("❌ 数据库初始化失败: %v", err)
@@ -68,15 +82,73 @@ func main() {
 	folderRepo = db.NewFolderRepository(bookmarkRepo)
 
 	// 4. 初始化服务
-	scraperService = services.NewScraperService()
+	scraperService = services.NewScraperService(cfg)
 	aiService = services.NewAIService(cfg, scraperService)
-	workflowEngine = services.NewWorkflowEngine(bookmarkRepo, folderRepo)
-	tagOptimizer = services.NewTagOptimizer(tagRepo, bookmarkRepo)
+	workflowEngine = services.NewWorkflowEngine(bookmarkRepo.WithSource(models.RevisionSourceWorkflow), folderRepo)
+	// 标签优化由 AI 自动合并、删除标签，历史记录来源记为 AI
+	tagOptimizer = services.NewTagOptimizer(tagRepo.WithSource(models.RevisionSourceAI), bookmarkRepo.WithSource(models.RevisionSourceAI))
+	importer = services.NewBookmarkImporter(bookmarkRepo.WithSource(models.RevisionSourceImport), folderRepo)
+	exporter = services.NewBookmarkExporter(bookmarkRepo, folderRepo)
 
 	// 5. 设置 API 处理器依赖
 	api.SetFolderRepository(folderRepo)
 	api.SetWorkflowEngine(workflowEngine)
 	api.SetTagOptimizer(tagOptimizer)
+	api.SetBookmarkImporter(importer)
+	api.SetBookmarkExporter(exporter)
+	api.SetBookmarkHistoryService(services.NewBookmarkHistoryService(bookmarkRepo))
+	api.SetDuplicateFinder(services.NewDuplicateFinder(bookmarkRepo))
+	api.SetTagRepository(tagRepo)
+
+	// 按域名的抓取配置（请求头、Cookie、代理、超时、禁止抓取）
+	api.SetScraperProfiles(db.NewScraperProfileRepository(), scraperService)
+	if err := api.LoadScraperProfiles(); err != nil {
+		log.Printf("⚠️ 加载抓取配置失败: %v", err)
+	}
+
+	assetService := services.NewAssetService(db.NewAssetRepository(), cfg.AssetsDir)
+	api.SetAssetService(assetService, bookmarkRepo)
+
+	// 回收站（按保留期自动清除）
+	trashService := services.NewTrashService(bookmarkRepo, assetService, cfg.TrashRetention)
+	api.SetTrashService(trashService)
+	trashService.Start()
+	defer trashService.Stop()
+
+	// 网站元数据（website_title / favicon_url / preview_image_url）后台加载
+	mediaCache := services.NewMediaCache(db.NewMediaRepository(), scraperService, cfg.MediaDir, cfg.PublicURL)
+	api.SetMediaCache(mediaCache)
+	metadataLoader = services.NewWebsiteMetadataLoader(bookmarkRepo, scraperService, mediaCache, cfg.SiteDefaultTags, 2)
+	metadataLoader.Start()
+	defer metadataLoader.Stop()
+
+	// 本地网页快照（保存为 snapshot 附件）
+	pageArchiver = services.NewPageArchiver(bookmarkRepo, assetService, scraperService, 2)
+	api.SetPageArchiver(pageArchiver)
+	pageArchiver.Start()
+	defer pageArchiver.Stop()
+
+	// 阅读模式正文提取（供阅读、AI 增强和 MCP 使用）
+	articleService = services.NewArticleService(db.NewArticleRepository(), bookmarkRepo, scraperService, 2)
+	api.SetArticleService(articleService)
+	articleService.Start()
+	defer articleService.Stop()
+
+	// 外部存档服务（Wayback Machine 等，结果写入 web_archive_snapshot_url）
+	archiveProvider, err := services.NewArchiveProvider(cfg)
+	if err != nil {
+		log.Fatalf("❌ 外部存档服务配置错误: %v", err)
+	}
+	webArchiver = services.NewWebArchiver(bookmarkRepo, archiveProvider)
+	api.SetWebArchiver(webArchiver)
+	webArchiver.Start()
+	defer webArchiver.Stop()
+
+	// 链接健康检查（定时检查状态码和重定向）
+	linkChecker := services.NewLinkChecker(bookmarkRepo, db.NewLinkCheckRepository(), scraperService, cfg.LinkCheckHours, cfg.LinkCheckWorkers)
+	api.SetLinkChecker(linkChecker)
+	linkChecker.Start()
+	defer linkChecker.Stop()
 
 	// 6. 初始化限流器
 	if cfg.RateLimitEnabled {
@@ -89,13 +161,33 @@ func main() {
 		aiWorkerPool.Start()
 		defer aiWorkerPool.Stop()
 	}
+	bulkService = services.NewBookmarkBulkService(bookmarkRepo, folderRepo, aiWorkerPool)
+	api.SetBookmarkBulkService(bulkService)
 
 	// 8. 初始化 MCP 服务器
-	mcpSrv := mcp.NewMCPServer(bookmarkRepo, tagRepo, folderRepo, scraperService)
+	mcpSrv := mcp.NewMCPServer(bookmarkRepo.WithSource(models.RevisionSourceMCP), tagRepo, folderRepo, scraperService, articleService)
 	httpServer := server.NewStreamableHTTPServer(mcpSrv.Server())
 	log.Printf("✅ MCP 服务器初始化成功")
 
-	// 8. 设置路由
+	// 8. 设置路由和中间件
+	handler := newRouter(http.StripPrefix("/mcp", httpServer))
+
+	// 9. 启动服务器
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	log.Printf("🚀 服务器启动: http://localhost:%s", port)
+	log.Printf("📚 REST API: http://localhost:%s/api/bookmarks", port)
+	log.Printf("🔗 MCP 端点: http://localhost:%s/mcp", port)
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
+		log.Fatalf("❌ 服务器启动失败: %v", err)
+	}
+}
+
+// newRouter 注册所有路由并应用中间件，mcpHandler 处理 /mcp/ 下的请求
+func newRouter(mcpHandler http.Handler) http.Handler {
 	mux := http.NewServeMux()
 
 	// 静态文件
@@ -110,7 +202,7 @@ func main() {
 	mux.HandleFunc("/js/", serveStatic)
 
 	// MCP HTTP 端点 - 使用 StreamableHTTPServer
-	mux.Handle("/mcp/", http.StripPrefix("/mcp", httpServer))
+	mux.Handle("/mcp/", mcpHandler)
 
 	// 系统状态端点 (用于引导页)
 	mux.HandleFunc("/api/system/status", func(w http.ResponseWriter, r *http.Request) {
@@ -200,6 +292,89 @@ func main() {
 			return
 		}
 
+		// /api/bookmarks/import/ (Netscape HTML 导入)
+		if r.URL.Path == "/api/bookmarks/import/" || r.URL.Path == "/api/bookmarks/import" {
+			if r.Method != "POST" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			api.HandleImportBookmarks(w, r)
+			return
+		}
+
+		// /api/bookmarks/export/ (html / json / csv / markdown 导出)
+		if r.URL.Path == "/api/bookmarks/export/" || r.URL.Path == "/api/bookmarks/export" {
+			if r.Method != "GET" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			api.HandleExportBookmarks(w, r)
+			return
+		}
+
+		// /api/bookmarks/archived/ (Linkding 归档列表)
+		if r.URL.Path == "/api/bookmarks/archived/" || r.URL.Path == "/api/bookmarks/archived" {
+			if r.Method != "GET" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			listBookmarks(w, r, true)
+			return
+		}
+
+		// /api/bookmarks/duplicates/ (重复书签分组) 和 /api/bookmarks/duplicates/merge/ (合并)
+		if r.URL.Path == "/api/bookmarks/duplicates/" || r.URL.Path == "/api/bookmarks/duplicates" {
+			if r.Method != "GET" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			api.HandleFindDuplicates(w, r)
+			return
+		}
+		if r.URL.Path == "/api/bookmarks/duplicates/merge/" || r.URL.Path == "/api/bookmarks/duplicates/merge" {
+			if r.Method != "POST" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			api.HandleMergeDuplicates(w, r)
+			return
+		}
+
+		// /api/bookmarks/{id}/archive/ 和 /api/bookmarks/{id}/unarchive/
+		if strings.HasSuffix(r.URL.Path, "/archive/") || strings.HasSuffix(r.URL.Path, "/unarchive/") {
+			handleArchiveBookmark(w, r)
+			return
+		}
+
+		// /api/bookmarks/bulk/ (批量操作)
+		if r.URL.Path == "/api/bookmarks/bulk/" || r.URL.Path == "/api/bookmarks/bulk" {
+			if r.Method != "POST" {
+				http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+				return
+			}
+			api.HandleBulkBookmarks(w, r)
+			return
+		}
+
+		// /api/bookmarks/{id}/{子资源}/...
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bookmarks/"), "/"), "/")
+		if len(parts) >= 2 {
+			switch parts[1] {
+			case "history": // /api/bookmarks/{id}/history/ 和 /api/bookmarks/{id}/history/{revision_id}/revert/
+				api.HandleBookmarkHistory(w, r)
+				return
+			case "assets": // /api/bookmarks/{id}/assets/ 和 /api/bookmarks/{id}/assets/{asset_id}/[download/]
+				api.HandleBookmarkAssets(w, r)
+				return
+			}
+		}
+
+		// /api/bookmarks/{id}/content/ (阅读模式正文)
+		if strings.HasSuffix(r.URL.Path, "/content/") || strings.HasSuffix(r.URL.Path, "/content") {
+			api.HandleBookmarkContent(w, r)
+			return
+		}
+
 		// Check if it's /api/bookmarks/{id}/enhance/
 		if len(r.URL.Path) > 9 && r.URL.Path[len(r.URL.Path)-9:] == "/enhance/" {
 			handleEnhanceBookmark(w, r)
@@ -209,7 +384,15 @@ func main() {
 		// /api/bookmarks/{id}
 		handleBookmarkByID(w, r)
 	})
+	mux.HandleFunc("/api/trash/", api.HandleTrash)
+	mux.HandleFunc("/api/links/", api.HandleLinks)
+	mux.HandleFunc("/api/media/", api.HandleMedia)
+	mux.HandleFunc("/api/scraper/profiles", api.HandleScraperProfiles)
+	mux.HandleFunc("/api/scraper/profiles/", api.HandleScraperProfiles)
 	mux.HandleFunc("/api/tags", handleTags)
+	// /api/tags/ 和 /api/tags/{id}/ (Linkding 兼容)
+	mux.HandleFunc("/api/tags/", api.HandleLinkdingTags)
+	mux.HandleFunc("/api/user/profile/", api.HandleUserProfile)
 	mux.HandleFunc("/api/tags/stats", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "GET" {
 			api.HandleGetTagStats(w, r)
@@ -312,25 +495,13 @@ func main() {
 	})
 	mux.HandleFunc("/api/workflows/apply", api.HandleApplyWorkflows)
 
-	// 9. 应用中间件
+	// 应用中间件
 	handler := api.LoggingMiddleware(mux)
 	handler = api.AuthMiddleware(func() string { return cfg.APIToken })(handler)
 	handler = api.RateLimitMiddleware(rateLimiter)(handler)
 	handler = api.CORSMiddleware(handler) // CORS 必须在最外层
 	handler = api.RecoveryMiddleware(handler)
-
-	// 10. 启动服务器
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("🚀 服务器启动: http://localhost:%s", port)
-	log.Printf("📚 REST API: http://localhost:%s/api/bookmarks", port)
-	log.Printf("🔗 MCP 端点: http://localhost:%s/mcp", port)
-	if err := http.ListenAndServe(":"+port, handler); err != nil {
-		log.Fatalf("❌ 服务器启动失败: %v", err)
-	}
+	return handler
 }
 
 // serveStatic 提供静态文件
@@ -347,7 +518,7 @@ func serveStatic(w http.ResponseWriter, r *http.Request) {
 func handleBookmarks(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		listBookmarks(w, r)
+		listBookmarks(w, r, false)
 	case "POST":
 		createBookmark(w, r)
 	default:
@@ -355,34 +526,41 @@ func handleBookmarks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// listBookmarks 获取书签列表
-func listBookmarks(w http.ResponseWriter, r *http.Request) {
+// listBookmarks 获取书签列表，archived 决定返回已归档还是未归档的书签
+func listBookmarks(w http.ResponseWriter, r *http.Request, archived bool) {
 	// 解析查询参数
 	query := r.URL.Query()
-	limit, _ := strconv.Atoi(query.Get("limit"))
-	if limit <= 0 || limit > 100 {
-		limit = 100
-	}
-
-	offset, _ := strconv.Atoi(query.Get("offset"))
-	if offset < 0 {
-		offset = 0
-	}
+	limit, offset := api.ParsePagination(query)
 
 	// 构建过滤器
-	filters := make(map[string]interface{})
-	if q := query.Get("q"); q != "" {
-		filters["q"] = q
-	}
-	if query.Get("unread") == "true" {
-		filters["unread"] = true
+	filters, err := api.ParseBookmarkFilters(query)
+	if err != nil {
+		api.WriteFilterError(w, err)
+		return
 	}
-	if query.Get("shared") == "true" {
-		filters["shared"] = true
+	filters["archived"] = archived
+
+	// 带 cursor 参数时使用键集分页（cursor 为空表示第一页）
+	_, cursorMode := query["cursor"]
+	var cursor *db.BookmarkCursor
+	fetchLimit := limit
+	if cursorMode {
+		// 游标按 (date_added, id) 定位，只能配合默认的添加时间倒序
+		if sort := query.Get("sort"); sort != "" && sort != "added" && sort != "added_desc" {
+			http.Error(w, "游标分页仅支持按添加时间倒序排列", http.StatusBadRequest)
+			return
+		}
+		cursor, err = db.DecodeBookmarkCursor(query.Get("cursor"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filters["cursor"] = cursor
+		fetchLimit = limit + 1 // 多查一条判断是否还有下一页
 	}
 
 	// 查询书签
-	bookmarks, err := bookmarkRepo.List(limit, offset, filters)
+	bookmarks, err := bookmarkRepo.List(fetchLimit, offset, filters)
 	if err != nil {
 		log.Printf("❌ 查询书签失败: %v", err)
 		http.Error(w, "查询失败", http.StatusInternalServerError)
@@ -394,14 +572,24 @@ func listBookmarks(w http.ResponseWriter, r *http.Request) {
 		bookmarks = []*models.Bookmark{}
 	}
 
-	// 统计总数
-	count, _ := bookmarkRepo.Count(filters)
+	// 统计总数（不受分页位置影响）
+	count, err := bookmarkRepo.Count(filters)
+	if err != nil {
+		log.Printf("⚠️ 统计书签数量失败: %v", err)
+	}
+
+	var next, previous interface{}
+	if cursorMode {
+		bookmarks, next, previous = api.CursorPage(r, cursor, bookmarks, limit)
+	} else {
+		next, previous = api.OffsetPageLinks(r, limit, offset, count)
+	}
 
 	// 返回结果
 	response := map[string]interface{}{
 		"count":    count,
-		"next":     nil,
-		"previous": nil,
+		"next":     next,
+		"previous": previous,
 		"results":  bookmarks,
 	}
 
@@ -504,18 +692,9 @@ func createBookmark(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// 映射归档到收藏
-	if bm.IsArchived {
-		bm.IsFavorite = true
-	}
-
 	// 自动截断
-	if len(bm.Title) > 200 {
-		bm.Title = bm.Title[:197] + "..."
-	}
-	if len(bm.Description) > 1000 {
-		bm.Description = bm.Description[:997] + "..."
-	}
+	bm.Title = utils.TruncateText(bm.Title, 200)
+	bm.Description = utils.TruncateText(bm.Description, 1000)
 
 	// 验证并创建
 	if err := utils.ValidateBookmarkCreate(&bm); err != nil {
@@ -534,6 +713,14 @@ func createBookmark(w http.ResponseWriter, r *http.Request) {
 	if cfg.EnableAsyncAI {
 		aiWorkerPool.Submit(created.ID)
 	}
+	metadataLoader.Submit(created.ID)
+	if cfg.AutoSnapshot {
+		if _, err := pageArchiver.Submit(created.ID); err != nil {
+			log.Printf("⚠️ 创建网页快照任务失败: %v", err)
+		}
+	}
+	webArchiver.Submit(created.ID)
+	articleService.Submit(created.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -573,6 +760,7 @@ func handleCheckBookmark(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"already_bookmarked": true,
 			"bookmark_id":        bm.ID,
+			"bookmark":           bm,
 			"metadata": map[string]string{
 				"url":         bm.URL,
 				"title":       bm.Title,
@@ -583,8 +771,8 @@ func handleCheckBookmark(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 不存在，尝试快速抓取元数据以供客户端预填充
-	// 这里使用 ScraperService, 但增加较短的超时，避免阻塞客户端过久
-	metadata, err := scraperService.ScrapeWebPage(normalizedURL)
+	// 使用较短的超时，避免阻塞客户端过久；抓取结果会被缓存，随后保存书签时的元数据加载和 AI 增强可以直接使用
+	metadata, err := scraperService.ScrapeWebPageTimeout(normalizedURL, time.Duration(cfg.ScraperCheckTimeout)*time.Second)
 	if err != nil {
 		// 抓取失败也返回 200，只是 metadata 里的内容不全
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -602,9 +790,10 @@ func handleCheckBookmark(w http.ResponseWriter, r *http.Request) {
 		"already_bookmarked": false,
 		"bookmark_id":        nil,
 		"metadata": map[string]string{
-			"url":         normalizedURL,
-			"title":       metadata.Title,
-			"description": metadata.Description,
+			"url":           normalizedURL,
+			"title":         metadata.Title,
+			"description":   metadata.Description,
+			"preview_image": metadata.Image,
 		},
 	})
 }
@@ -624,8 +813,10 @@ func handleBookmarkByID(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
 		getBookmark(w, r, id)
-	case "PATCH", "PUT":
+	case "PUT":
 		updateBookmark(w, r, id)
+	case "PATCH":
+		patchBookmark(w, r, id)
 	case "DELETE":
 		deleteBookmark(w, r, id)
 	default:
@@ -633,9 +824,38 @@ func handleBookmarkByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleArchiveBookmark 归档或取消归档书签 (Linkding 兼容)
+func handleArchiveBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 提取ID from /api/bookmarks/{id}/archive/ 或 /api/bookmarks/{id}/unarchive/
+	rest := strings.TrimSuffix(r.URL.Path[len("/api/bookmarks/"):], "/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "无效的ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := bookmarkRepo.SetArchived(id, action == "archive"); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "书签不存在", http.StatusNotFound)
+			return
+		}
+		log.Printf("❌ 更新归档状态失败: %v", err)
+		http.Error(w, "更新失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // getBookmark 获取单个书签
 func getBookmark(w http.ResponseWriter, r *http.Request, id int) {
-	bookmark, err := bookmarkRepo.GetByID(id)
+	bookmark, err := bookmarkRepo.GetActiveByID(id)
 	if err != nil {
 		http.Error(w, "书签不存在", http.StatusNotFound)
 		return
@@ -661,6 +881,64 @@ func updateBookmark(w http.ResponseWriter, r *http.Request, id int) {
 
 	// 更新书签
 	updated, err := bookmarkRepo.Update(id, &bm)
+	if err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+	if err == db.ErrDuplicateURL {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ 更新书签失败: %v", err)
+		http.Error(w, "更新失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// patchBookmark 部分更新书签，只修改请求中出现的字段
+func patchBookmark(w http.ResponseWriter, r *http.Request, id int) {
+	var body struct {
+		models.BookmarkPatch
+		// tag_names / tags 兼容数组和逗号分隔字符串两种格式
+		TagNames json.RawMessage `json:"tag_names"`
+		Tags     json.RawMessage `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+
+	patch := body.BookmarkPatch
+	for _, raw := range []json.RawMessage{body.TagNames, body.Tags} {
+		tags, ok, err := decodeTagList(raw)
+		if err != nil {
+			http.Error(w, "无效的标签数据", http.StatusBadRequest)
+			return
+		}
+		if ok {
+			patch.TagNames = &tags
+			break
+		}
+	}
+
+	if err := utils.ValidateBookmarkPatch(&patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := bookmarkRepo.Patch(id, &patch)
+	if err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+	if err == db.ErrDuplicateURL {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Printf("❌ 更新书签失败: %v", err)
 		http.Error(w, "更新失败", http.StatusInternalServerError)
@@ -671,9 +949,33 @@ func updateBookmark(w http.ResponseWriter, r *http.Request, id int) {
 	json.NewEncoder(w).Encode(updated)
 }
 
-// deleteBookmark 删除书签
+// decodeTagList 解析标签列表（数组或逗号分隔字符串），未提供或为 null 时 ok 为 false
+func decodeTagList(raw json.RawMessage) (tags []string, ok bool, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, false, nil
+	}
+	if err := json.Unmarshal(raw, &tags); err == nil {
+		return tags, true, nil
+	}
+	var tagStr string
+	if err := json.Unmarshal(raw, &tagStr); err != nil {
+		return nil, false, err
+	}
+	tags = []string{}
+	for _, p := range strings.Split(tagStr, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			tags = append(tags, p)
+		}
+	}
+	return tags, true, nil
+}
+
+// deleteBookmark 删除书签（移入回收站）
 func deleteBookmark(w http.ResponseWriter, r *http.Request, id int) {
-	if err := bookmarkRepo.Delete(id); err != nil {
+	if err := bookmarkRepo.Delete(id); err == sql.ErrNoRows {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	} else if err != nil {
 		log.Printf("❌ 删除书签失败: %v", err)
 		http.Error(w, "删除失败", http.StatusInternalServerError)
 		return
@@ -740,7 +1042,7 @@ func enhanceBookmarkAsync(bookmarkID int) {
 	log.Printf("🔄 后台任务开始: 增强书签 ID=%d", bookmarkID)
 
 	// 获取书签
-	bm, err := bookmarkRepo.GetByID(bookmarkID)
+	bm, err := bookmarkRepo.GetActiveByID(bookmarkID)
 	if err != nil {
 		log.Printf("❌ 后台任务: 书签不存在 ID=%d, 错误: %v", bookmarkID, err)
 		return
@@ -748,55 +1050,41 @@ func enhanceBookmarkAsync(bookmarkID int) {
 
 	// AI增强
 	log.Printf("🤖 触发AI增强: Title='%s' Desc='%s'", bm.Title, bm.Description)
-	aiResp, err := aiService.Enhance(bm.URL)
+	article, err := articleService.Get(bookmarkID)
+	if err != nil {
+		log.Printf("⚠️ 提取正文失败，仅使用网页元数据: %v", err)
+		article = nil
+	}
+	aiResp, err := aiService.Enhance(bm.URL, article)
 	if err != nil {
 		log.Printf("⚠️ 后台AI增强失败: %v", err)
 		return
 	}
 
-	// 更新书签
+	// 更新书签: AI 调用期间书签可能被修改，只写入 AI 改动的字段并追加标签，避免覆盖其他修改
 	needsUpdate := false
-	updateReq := &models.BookmarkCreate{
-		URL:         bm.URL,
-		Title:       bm.Title,
-		Description: bm.Description,
-		Notes:       bm.Notes,
-		IsFavorite:  bm.IsFavorite,
-		Unread:      bm.Unread,
-		Shared:      bm.Shared,
-		TagNames:    bm.TagNames,
-	}
-
-	if aiResp.Title != "" {
-		updateReq.Title = aiResp.Title
+	patch := &models.BookmarkPatch{}
+
+	if aiResp.Title != "" && aiResp.Title != bm.Title {
+		patch.Title = &aiResp.Title
 		needsUpdate = true
 		log.Printf("✨ AI优化标题: %s", aiResp.Title)
 	}
 
-	if aiResp.Description != "" {
-		updateReq.Description = aiResp.Description
+	if aiResp.Description != "" && aiResp.Description != bm.Description {
+		patch.Description = &aiResp.Description
 		needsUpdate = true
 		log.Printf("✨ AI优化描述: %s", aiResp.Description[:utils.Min(150, len(aiResp.Description))])
 	}
 
 	if len(aiResp.Tags) > 0 {
-		// 合并标签（去重）
-		tagMap := make(map[string]bool)
-		for _, tag := range updateReq.TagNames {
-			tagMap[tag] = true
-		}
-		for _, tag := range aiResp.Tags {
-			if !tagMap[tag] {
-				updateReq.TagNames = append(updateReq.TagNames, tag)
-				tagMap[tag] = true
-			}
-		}
+		patch.AddTags = aiResp.Tags
 		needsUpdate = true
 		log.Printf("✨ AI添加标签: %v", aiResp.Tags)
 	}
 
 	if needsUpdate {
-		_, err := bookmarkRepo.Update(bookmarkID, updateReq)
+		_, err := bookmarkRepo.WithSource(models.RevisionSourceAI).Patch(bookmarkID, patch)
 		if err != nil {
 			log.Printf("❌ 后台任务更新失败: %v", err)
 		} else {
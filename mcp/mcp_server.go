@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"fmt"
+	"html"
 	"strings"
 
 	"ai-bookmark-service/db"
@@ -17,6 +18,7 @@ type MCPServer struct {
 	tagRepo        *db.TagRepository
 	folderRepo     *db.FolderRepository
 	scraperService *services.ScraperService
+	articleService *services.ArticleService
 	mcpServer      *server.MCPServer
 }
 
@@ -26,12 +28,14 @@ func NewMCPServer(
 	tagRepo *db.TagRepository,
 	folderRepo *db.FolderRepository,
 	scraperService *services.ScraperService,
+	articleService *services.ArticleService,
 ) *MCPServer {
 	s := &MCPServer{
 		bookmarkRepo:   bookmarkRepo,
 		tagRepo:        tagRepo,
 		folderRepo:     folderRepo,
 		scraperService: scraperService,
+		articleService: articleService,
 	}
 
 	// Create MCP server with latest API
@@ -76,6 +80,11 @@ func formatBookmarks(bookmarks []*models.Bookmark, title string) string {
 		if len(bookmark.TagNames) > 0 {
 			result.WriteString(fmt.Sprintf("- **标签**: %s\n", strings.Join(bookmark.TagNames, ", ")))
 		}
+
+		if bookmark.SearchSnippet != "" {
+			snippet := html.UnescapeString(strings.NewReplacer("<mark>", "**", "</mark>", "**").Replace(bookmark.SearchSnippet))
+			result.WriteString(fmt.Sprintf("- **匹配**: %s\n", snippet))
+		}
 	}
 
 	return result.String()
@@ -5,17 +5,25 @@ import (
 	"fmt"
 	"strings"
 
+	"ai-bookmark-service/models"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// sortParamDescription 列表类工具的 sort 参数说明
+const sortParamDescription = "排序方式: added|modified|title|domain|relevance,可加 _asc 或 _desc 后缀指定方向"
+
 // registerTools registers all MCP tools using correct API
 func (s *MCPServer) registerTools() {
 	// Tool 1: Search bookmarks
 	searchTool := mcp.NewTool("search_bookmarks",
-		mcp.WithDescription("搜索书签,支持搜索标题、URL、描述和标签"),
+		mcp.WithDescription("全文搜索书签,支持搜索标题、URL、描述、笔记和标签,结果按相关度排序"),
 		mcp.WithString("query",
 			mcp.Required(),
-			mcp.Description("搜索关键词"),
+			mcp.Description("搜索关键词,支持操作符: tag:名称 folder:名称 is:unread|read|favorite|shared|private|untagged|broken|redirected site:域名 after:YYYY-MM-DD before:YYYY-MM-DD author:作者 type:article|video|audio|image|repo|paper|book|product lang:语言, 前缀 - 表示排除, \"引号\" 表示精确短语"),
+		),
+		mcp.WithString("sort",
+			mcp.Description(sortParamDescription),
 		),
 	)
 	s.mcpServer.AddTool(searchTool, s.handleSearchBookmarks)
@@ -39,6 +47,9 @@ func (s *MCPServer) registerTools() {
 			mcp.Required(),
 			mcp.Description("文件夹ID"),
 		),
+		mcp.WithString("sort",
+			mcp.Description(sortParamDescription),
+		),
 	)
 	s.mcpServer.AddTool(byFolderTool, s.handleGetBookmarksByFolder)
 
@@ -49,12 +60,15 @@ func (s *MCPServer) registerTools() {
 			mcp.Required(),
 			mcp.Description("标签名称"),
 		),
+		mcp.WithString("sort",
+			mcp.Description(sortParamDescription),
+		),
 	)
 	s.mcpServer.AddTool(byTagTool, s.handleGetBookmarksByTag)
 
 	// Tool 6: Fetch bookmark content
 	fetchContentTool := mcp.NewTool("fetch_bookmark_content",
-		mcp.WithDescription("抓取书签页面的实际内容,用于深入阅读和讨论。注意:此操作可能较慢,请谨慎使用。"),
+		mcp.WithDescription("抓取书签页面的正文(阅读模式 Markdown),用于深入阅读和讨论。已保存的书签优先返回已提取的正文,否则实时抓取,可能较慢。"),
 		mcp.WithString("url",
 			mcp.Required(),
 			mcp.Description("要抓取内容的书签URL"),
@@ -71,7 +85,7 @@ func (s *MCPServer) handleSearchBookmarks(ctx context.Context, request mcp.CallT
 		return mcp.NewToolResultError("query parameter required"), nil
 	}
 
-	filters := map[string]interface{}{"q": query}
+	filters := map[string]interface{}{"q": query, "sort": request.GetString("sort", "")}
 	bookmarks, err := s.bookmarkRepo.List(100, 0, filters)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to search bookmarks: %v", err)), nil
@@ -107,7 +121,7 @@ func (s *MCPServer) handleGetBookmarksByFolder(ctx context.Context, request mcp.
 		return mcp.NewToolResultError("folder_id parameter required"), nil
 	}
 
-	filters := map[string]interface{}{"folder_id": int(folderID)}
+	filters := map[string]interface{}{"folder_id": int(folderID), "sort": request.GetString("sort", "")}
 	bookmarks, err := s.bookmarkRepo.List(100, 0, filters)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get bookmarks by folder: %v", err)), nil
@@ -123,7 +137,7 @@ func (s *MCPServer) handleGetBookmarksByTag(ctx context.Context, request mcp.Cal
 		return mcp.NewToolResultError("tag parameter required"), nil
 	}
 
-	filters := map[string]interface{}{"tag": tag}
+	filters := map[string]interface{}{"tag": tag, "sort": request.GetString("sort", "")}
 	bookmarks, err := s.bookmarkRepo.List(100, 0, filters)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get bookmarks by tag: %v", err)), nil
@@ -139,35 +153,37 @@ func (s *MCPServer) handleFetchBookmarkContent(ctx context.Context, request mcp.
 		return mcp.NewToolResultError("url parameter required"), nil
 	}
 
-	// 使用 ScraperService 抓取頁面元數據
-	metadata, err := s.scraperService.ScrapeWebPage(url)
+	// 已保存的书签使用存储的正文（没有时提取并保存），否则实时抓取
+	var article *models.Article
+	var err error
+	if bm, lookupErr := s.bookmarkRepo.GetByURL(url); lookupErr == nil {
+		article, err = s.articleService.Get(bm.ID)
+	} else {
+		article, err = s.scraperService.ScrapeArticle(url)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch content: %v", err)), nil
 	}
 
 	// 格式化返回結果
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("# %s\n\n", metadata.Title))
+	result.WriteString(fmt.Sprintf("# %s\n\n", article.Title))
 	result.WriteString(fmt.Sprintf("**URL**: %s\n\n", url))
-	
-	if metadata.Description != "" {
-		result.WriteString(fmt.Sprintf("**描述**: %s\n\n", metadata.Description))
+	if article.Byline != "" {
+		result.WriteString(fmt.Sprintf("**作者**: %s\n\n", article.Byline))
 	}
-	
-	if metadata.OGTitle != "" && metadata.OGTitle != metadata.Title {
-		result.WriteString(fmt.Sprintf("**Open Graph 標題**: %s\n\n", metadata.OGTitle))
+	if article.Excerpt != "" {
+		result.WriteString(fmt.Sprintf("**摘要**: %s\n\n", article.Excerpt))
 	}
-	
-	if metadata.OGDesc != "" && metadata.OGDesc != metadata.Description {
-		result.WriteString(fmt.Sprintf("**Open Graph 描述**: %s\n\n", metadata.OGDesc))
+	result.WriteString(fmt.Sprintf("**字数**: %d\n\n", article.WordCount))
+
+	result.WriteString("---\n\n")
+	if article.Markdown != "" {
+		result.WriteString(article.Markdown)
+		result.WriteString("\n")
+	} else {
+		result.WriteString("⚠️ 未能从页面中提取到正文。\n")
 	}
 
-	result.WriteString("\n---\n\n")
-	result.WriteString("⚠️ **注意**: 當前只能抓取頁面的元數據(標題、描述等)。\n")
-	result.WriteString("如需完整文章內容,請考慮:\n")
-	result.WriteString("1. 使用瀏覽器打開 URL 閱讀\n")
-	result.WriteString("2. 將文章內容複製粘貼給我\n")
-	result.WriteString("3. 未來可以擴展抓取完整文本內容的功能\n")
-
 	return mcp.NewToolResultText(result.String()), nil
 }
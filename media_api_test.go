@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ai-bookmark-service/api"
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/services"
+)
+
+func TestMediaHandler(t *testing.T) {
+	h := newTestServer(t)
+	dir := t.TempDir()
+	api.SetMediaCache(services.NewMediaCache(db.NewMediaRepository(), nil, dir, ""))
+	t.Cleanup(func() { api.SetMediaCache(nil) })
+
+	hash := strings.Repeat("0f", 32)
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`
+	if err := os.WriteFile(filepath.Join(dir, models.MediaKindFavicon, hash+".svg"), []byte(svg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(t, h, "GET", "/api/media/favicons/"+hash+".svg", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != svg {
+		t.Fatalf("状态码 = %d: %s", rec.Code, rec.Body.String())
+	}
+	for header, want := range map[string]string{
+		"Content-Type":           "image/svg+xml",
+		"ETag":                   `"` + hash + `"`,
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=31536000, immutable",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q，期望 %q", header, got, want)
+		}
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "sandbox") {
+		t.Errorf("Content-Security-Policy = %q，SVG 应在沙箱中", csp)
+	}
+
+	if rec := doRequest(t, h, "POST", "/api/media/favicons/"+hash+".svg", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST 状态码 = %d，期望 405", rec.Code)
+	}
+
+	// 不符合缓存文件名格式的路径一律返回 404，不能读取缓存目录之外的文件
+	for _, path := range []string{
+		"/api/media/previews/" + hash + ".svg",
+		"/api/media/favicons/" + hash,
+		"/api/media/favicons/" + strings.ToUpper(hash) + ".svg",
+		"/api/media/favicons/secret.txt",
+		"/api/media/favicons/..%2fsecret.txt",
+		"/api/media/favicons/%2e%2e%2fsecret.txt",
+		"/api/media/..%2fsecret.txt",
+		"/api/media/favicons/x/" + hash + ".svg",
+		"/api/media/favicons/",
+		"/api/media/",
+	} {
+		rec := doRequest(t, h, "GET", path, "", nil)
+		if rec.Code != http.StatusNotFound || strings.Contains(rec.Body.String(), "secret") {
+			t.Errorf("GET %s 状态码 = %d，期望 404: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
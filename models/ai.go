@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // AIResponse AI 响应数据模型
 type AIResponse struct {
 	Title       string   `json:"title"`
@@ -13,4 +15,31 @@ type PageMetadata struct {
 	Description string
 	OGTitle     string
 	OGDesc      string
+	Image       string // og:image / twitter:image（已解析为绝对地址）
+	Favicon     string // <link rel="icon">（已解析为绝对地址）
+
+	// 结构化元数据（JSON-LD、OpenGraph article 标签、微数据、oEmbed）
+	Author       string     // 作者，多位作者以逗号分隔
+	PublishedAt  *time.Time // 发布时间
+	SiteName     string     // 站点名称
+	CanonicalURL string     // <link rel="canonical"> / og:url（已解析为绝对地址）
+	ContentType  string     // 内容类型，见 ContentType* 常量
+	Language     string     // 语言，如 zh-CN
+	ReadingTime  int        // 预计阅读分钟数，0 表示未知
+
+	// 特定网站提取器的结果（GitHub、YouTube、arXiv 等）
+	Details map[string]string // 附加信息，如仓库语言、星标数、视频时长、频道
+	Tags    []string          // 建议的默认标签
 }
+
+// 网页内容类型
+const (
+	ContentTypeArticle = "article"
+	ContentTypeVideo   = "video"
+	ContentTypeAudio   = "audio"
+	ContentTypeImage   = "image"
+	ContentTypeRepo    = "repo"
+	ContentTypePaper   = "paper"
+	ContentTypeBook    = "book"
+	ContentTypeProduct = "product"
+)
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Article 阅读模式提取的网页正文
+type Article struct {
+	BookmarkID    int       `json:"bookmark"`
+	Title         string    `json:"title"`
+	Byline        string    `json:"byline"`  // 作者
+	Excerpt       string    `json:"excerpt"` // 摘要（页面描述或正文首段）
+	Text          string    `json:"text"`    // 纯文本正文
+	Markdown      string    `json:"markdown"`
+	WordCount     int       `json:"word_count"` // 中日韩文字按字计数，其他按词计数
+	DateExtracted time.Time `json:"date_extracted"`
+}
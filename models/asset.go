@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// 附件类型（与 linkding 一致）
+const (
+	AssetTypeSnapshot = "snapshot"
+	AssetTypeUpload   = "upload"
+)
+
+// 附件状态（与 linkding 一致）
+const (
+	AssetStatusPending  = "pending"
+	AssetStatusComplete = "complete"
+	AssetStatusFailure  = "failure"
+)
+
+// BookmarkAsset 书签附件
+type BookmarkAsset struct {
+	ID          int       `json:"id"`
+	BookmarkID  int       `json:"bookmark"`
+	AssetType   string    `json:"asset_type"`
+	DateCreated time.Time `json:"date_created"`
+	ContentType string    `json:"content_type"`
+	DisplayName string    `json:"display_name"`
+	FileSize    int64     `json:"file_size"`
+	Status      string    `json:"status"`
+	File        string    `json:"-"` // 相对于附件目录的文件名
+}
@@ -6,22 +6,41 @@ import "time"
 type Bookmark struct {
 	ID           int       `json:"id"`
 	URL          string    `json:"url"`
+	CanonicalURL string    `json:"canonical_url"` // 去除跟踪参数等后的规范 URL，用于去重
 	Title        string    `json:"title"`
 	Description  string    `json:"description"`
 	Notes        string    `json:"notes"`
 	IsFavorite   bool      `json:"is_favorite"`
 	Unread       bool      `json:"unread"`
 	Shared       bool      `json:"shared"`
+	IsArchived   bool      `json:"is_archived"`
 	TagNames     []string  `json:"tag_names"`
 	DateAdded    time.Time `json:"date_added"`
 	DateModified time.Time `json:"date_modified"`
 
+	// 回收站中的书签才有删除时间
+	DateDeleted *time.Time `json:"date_deleted,omitempty"`
+
 	// linkding 兼容字段
 	WebArchiveSnapshotURL string  `json:"web_archive_snapshot_url"`
 	FaviconURL            *string `json:"favicon_url"`
 	PreviewImageURL       *string `json:"preview_image_url"`
 	WebsiteTitle          *string `json:"website_title"`
 	WebsiteDescription    *string `json:"website_description"`
+
+	// 网页结构化元数据（后台抓取填充，未知时为空）
+	Author              string            `json:"author"`
+	DatePublished       *time.Time        `json:"date_published"`
+	SiteName            string            `json:"site_name"`
+	WebsiteCanonicalURL string            `json:"website_canonical_url"` // 网页声明的规范地址
+	ContentType         string            `json:"content_type"`          // article | video | audio | image | repo | paper | book | product
+	Language            string            `json:"language"`
+	ReadingTime         int               `json:"reading_time"` // 预计阅读分钟数，0 表示未知
+	SiteDetails         map[string]string `json:"site_details"` // 特定网站的附加信息，如仓库语言、星标数、视频时长
+
+	// 全文搜索结果（仅在带 q 参数查询时返回）
+	SearchScore   *float64 `json:"search_score,omitempty"`   // BM25 相关度，越大越相关
+	SearchSnippet string   `json:"search_snippet,omitempty"` // 命中片段（已 HTML 转义），关键词以 <mark> 包裹
 }
 
 // BookmarkCreate 创建书签请求
@@ -33,8 +52,21 @@ type BookmarkCreate struct {
 	IsFavorite  bool     `json:"is_favorite"`
 	Unread      bool     `json:"unread"`
 	Shared      bool     `json:"shared"`
+	IsArchived  bool     `json:"is_archived"`
 	TagNames    []string `json:"tag_names"`
+}
 
-	// Linkding 兼容字段
-	IsArchived bool `json:"is_archived"` // Linkding 支持归档字段
+// BookmarkPatch 部分更新书签请求（PATCH），nil 表示请求中未提供该字段、保持原值
+type BookmarkPatch struct {
+	URL         *string   `json:"url"`
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Notes       *string   `json:"notes"`
+	IsFavorite  *bool     `json:"is_favorite"`
+	Unread      *bool     `json:"unread"`
+	Shared      *bool     `json:"shared"`
+	IsArchived  *bool     `json:"is_archived"`
+	TagNames    *[]string `json:"tag_names"`   // 提供时整体替换标签
+	AddTags     []string  `json:"add_tags"`    // 追加的标签
+	RemoveTags  []string  `json:"remove_tags"` // 移除的标签
 }
@@ -0,0 +1,64 @@
+package models
+
+// 批量操作类型
+const (
+	BulkOpAddTags           = "add_tags"
+	BulkOpRemoveTags        = "remove_tags"
+	BulkOpAddToFolders      = "add_to_folders"
+	BulkOpMoveToFolders     = "move_to_folders"
+	BulkOpRemoveFromFolders = "remove_from_folders"
+	BulkOpMarkRead          = "mark_read"
+	BulkOpMarkUnread        = "mark_unread"
+	BulkOpFavorite          = "favorite"
+	BulkOpUnfavorite        = "unfavorite"
+	BulkOpShare             = "share"
+	BulkOpUnshare           = "unshare"
+	BulkOpArchive           = "archive"
+	BulkOpUnarchive         = "unarchive"
+	BulkOpDelete            = "delete"
+	BulkOpEnhance           = "enhance"
+)
+
+// 单个书签的批量处理结果
+const (
+	BulkStatusOK       = "ok"
+	BulkStatusQueued   = "queued" // 已提交到 AI 队列
+	BulkStatusNotFound = "not_found"
+	BulkStatusFailed   = "failed"
+)
+
+// BulkRequest 批量操作请求，ids 与 query 二选一
+type BulkRequest struct {
+	IDs       []int    `json:"ids"`
+	Query     string   `json:"query"` // 搜索查询语言，作用于所有匹配的书签
+	Operation string   `json:"operation"`
+	Tags      []string `json:"tags"`       // add_tags / remove_tags
+	FolderIDs []int    `json:"folder_ids"` // add_to_folders / move_to_folders / remove_from_folders
+}
+
+// BulkItemResult 单个书签的处理结果
+type BulkItemResult struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkReport 批量操作结果汇总
+type BulkReport struct {
+	Operation string            `json:"operation"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []*BulkItemResult `json:"results"`
+}
+
+// Add 记录单个书签的结果
+func (r *BulkReport) Add(item *BulkItemResult) {
+	r.Total++
+	if item.Status == BulkStatusOK || item.Status == BulkStatusQueued {
+		r.Succeeded++
+	} else {
+		r.Failed++
+	}
+	r.Results = append(r.Results, item)
+}
@@ -0,0 +1,22 @@
+package models
+
+// 重复书签的判定依据
+const (
+	DuplicateReasonURL     = "url"     // 宽松规范 URL 相同（忽略协议、www/m/amp 子域名和 AMP 路径）
+	DuplicateReasonTitle   = "title"   // 标题相同
+	DuplicateReasonContent = "content" // 标题和描述的文本相似度超过阈值
+)
+
+// DuplicateGroup 一组疑似重复的书签
+type DuplicateGroup struct {
+	Reasons           []string    `json:"reasons"`
+	Similarity        float64     `json:"similarity,omitempty"` // 仅 content: 组内最高的文本相似度
+	SuggestedTargetID int         `json:"suggested_target_id"`  // 建议保留的书签（最早添加）
+	Bookmarks         []*Bookmark `json:"bookmarks"`
+}
+
+// MergeRequest 合并重复书签请求: 将 ids 合并到 target_id
+type MergeRequest struct {
+	TargetID int   `json:"target_id"`
+	IDs      []int `json:"ids"`
+}
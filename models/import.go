@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// 导入结果状态
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportItem 从导入文件中解析出的单个书签
+type ImportItem struct {
+	Bookmark   BookmarkCreate
+	DateAdded  time.Time // 零值表示文件中未提供 ADD_DATE
+	FolderName string    // 所在的最内层 <H3> 文件夹名称，空表示根目录
+	FolderID   int       // 由导入服务解析后的文件夹ID
+}
+
+// ImportItemResult 单个书签的导入结果
+type ImportItemResult struct {
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	Status     string `json:"status"` // created | updated | skipped | failed
+	BookmarkID int    `json:"bookmark_id,omitempty"`
+	Folder     string `json:"folder,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ImportReport 导入报告
+type ImportReport struct {
+	Total   int                 `json:"total"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Skipped int                 `json:"skipped"`
+	Failed  int                 `json:"failed"`
+	Success int                 `json:"success"` // created + updated，兼容前端
+	Items   []*ImportItemResult `json:"items"`
+}
+
+// Add 记录单个书签的导入结果并更新统计
+func (r *ImportReport) Add(item *ImportItemResult) {
+	r.Total++
+	switch item.Status {
+	case ImportStatusCreated:
+		r.Created++
+		r.Success++
+	case ImportStatusUpdated:
+		r.Updated++
+		r.Success++
+	case ImportStatusSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
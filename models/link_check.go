@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// LinkCheck 书签链接的最近一次健康检查结果
+type LinkCheck struct {
+	BookmarkID          int       `json:"bookmark"`
+	StatusCode          int       `json:"status_code"` // 0 表示请求失败（DNS、超时等），原因见 error
+	FinalURL            string    `json:"final_url"`   // 跟随重定向后的地址
+	Error               string    `json:"error,omitempty"`
+	Redirected          bool      `json:"redirected"` // 最终地址的规范形式与书签不同
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastChecked         time.Time `json:"last_checked"`
+}
+
+// LinkReportItem 链接健康报告中的单个书签
+type LinkReportItem struct {
+	ID    int        `json:"id"`
+	URL   string     `json:"url"`
+	Title string     `json:"title"`
+	Check *LinkCheck `json:"check"`
+}
+
+// LinkReport 链接健康报告
+type LinkReport struct {
+	Total      int               `json:"total"`      // 未删除的书签总数
+	Checked    int               `json:"checked"`    // 已检查过的书签数
+	Healthy    int               `json:"healthy"`    // 最近一次检查成功且未重定向
+	Broken     []*LinkReportItem `json:"broken"`     // 失效的链接
+	Redirected []*LinkReportItem `json:"redirected"` // 已重定向到其他地址的链接
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// 缓存的媒体类型，同时作为 /api/media/{kind}/ 路径和缓存子目录名
+const (
+	MediaKindFavicon = "favicons"
+	MediaKindPreview = "previews"
+)
+
+// MediaFile 缓存到本地的网站图标或预览图
+type MediaFile struct {
+	Kind        string
+	SourceURL   string // 原始图片地址
+	File        string // 缓存文件名（内容哈希 + 扩展名）
+	ContentType string
+	DateFetched time.Time
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// 书签修改来源
+const (
+	RevisionSourceUser     = "user"
+	RevisionSourceAI       = "ai"
+	RevisionSourceWorkflow = "workflow"
+	RevisionSourceImport   = "import"
+	RevisionSourceMCP      = "mcp"
+	RevisionSourceMetadata = "metadata" // 根据网站元数据自动添加的默认标签
+	RevisionSourceRevert   = "revert"   // 回退到历史版本
+	RevisionSourceBaseline = "baseline" // 启用历史记录前已存在的书签的初始版本
+)
+
+// BookmarkSnapshot 书签在某个版本的内容（不含文件夹归属）
+type BookmarkSnapshot struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Notes       string   `json:"notes"`
+	IsFavorite  bool     `json:"is_favorite"`
+	Unread      bool     `json:"unread"`
+	Shared      bool     `json:"shared"`
+	IsArchived  bool     `json:"is_archived"`
+	TagNames    []string `json:"tag_names"` // 按名称排序
+}
+
+// BookmarkRevision 书签的一个历史版本
+type BookmarkRevision struct {
+	ID          int               `json:"id"`
+	BookmarkID  int               `json:"bookmark_id"`
+	Source      string            `json:"source"`
+	DateCreated time.Time         `json:"date_created"`
+	Snapshot    *BookmarkSnapshot `json:"snapshot"`
+	Changes     []*FieldChange    `json:"changes"` // 相对上一版本的字段变化
+}
+
+// FieldChange 单个字段的变化
+type FieldChange struct {
+	Field   string      `json:"field"`
+	Old     interface{} `json:"old"`
+	New     interface{} `json:"new"`
+	Added   []string    `json:"added,omitempty"`   // 仅 tag_names: 新增的标签
+	Removed []string    `json:"removed,omitempty"` // 仅 tag_names: 移除的标签
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ScraperProfile 按域名配置的抓取参数，应用于该域名及其子域名的所有抓取请求
+type ScraperProfile struct {
+	ID           int               `json:"id"`
+	Domain       string            `json:"domain"`    // 如 example.com，同时匹配 www.example.com 等子域名，多个配置匹配时使用最具体的
+	Headers      map[string]string `json:"headers"`   // 覆盖默认请求头（如 User-Agent、Referer），值为空时删除该请求头
+	Cookies      string            `json:"cookies"`   // Cookie 请求头，如 "session=abc; lang=zh"
+	ProxyURL     string            `json:"proxy_url"` // http、https 或 socks5 代理地址
+	Timeout      int               `json:"timeout"`   // 抓取超时（秒），0 表示使用全局设置
+	Disabled     bool              `json:"disabled"`  // 禁止抓取该域名的网页内容（链接健康检查不受影响）
+	DateCreated  time.Time         `json:"date_created"`
+	DateModified time.Time         `json:"date_modified"`
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,8 +29,11 @@ func NewAIService(cfg *config.Config, scraper *ScraperService) *AIService {
 	}
 }
 
-// Enhance 使用 AI 增强书签
-func (s *AIService) Enhance(url string) (*models.AIResponse, error) {
+// aiPromptContentRunes 提示词中正文节选的最大字符数
+const aiPromptContentRunes = 3000
+
+// Enhance 使用 AI 增强书签，article 为已提取的正文（可为 nil）
+func (s *AIService) Enhance(url string, article *models.Article) (*models.AIResponse, error) {
 	// 详细日志：显示 AI 配置状态（脱敏）
 	apiKeyPreview := "未设置"
 	if len(s.config.AIAPIKey) > 4 {
@@ -51,7 +55,7 @@ func (s *AIService) Enhance(url string) (*models.AIResponse, error) {
 	}
 
 	// 构建AI提示词,优先使用抓取的内容
-	prompt := s.buildPrompt(url, metadata)
+	prompt := s.buildPrompt(url, metadata, article)
 
 	// 调用 AI API
 	reqBody := map[string]interface{}{
@@ -129,7 +133,7 @@ func (s *AIService) Enhance(url string) (*models.AIResponse, error) {
 }
 
 // buildPrompt 构建 AI 提示词
-func (s *AIService) buildPrompt(url string, metadata *models.PageMetadata) string {
+func (s *AIService) buildPrompt(url string, metadata *models.PageMetadata, article *models.Article) string {
 	pageTitle := metadata.OGTitle
 	if pageTitle == "" {
 		pageTitle = metadata.Title
@@ -139,6 +143,48 @@ func (s *AIService) buildPrompt(url string, metadata *models.PageMetadata) strin
 		pageDesc = metadata.Description
 	}
 
+	// 有正文时附上节选，让 AI 基于实际内容而不只是标题和描述
+	content := ""
+	if article != nil {
+		if pageTitle == "" {
+			pageTitle = article.Title
+		}
+		if pageDesc == "" {
+			pageDesc = article.Excerpt
+		}
+		if article.Text != "" {
+			content = fmt.Sprintf("\n网页正文(节选):\n%s\n", truncateRunes(article.Text, aiPromptContentRunes))
+		}
+	}
+
+	// 结构化元数据帮助 AI 判断来源和内容类型
+	var details strings.Builder
+	for _, item := range []struct{ label, value string }{
+		{"作者", metadata.Author},
+		{"站点", metadata.SiteName},
+		{"内容类型", metadata.ContentType},
+		{"语言", metadata.Language},
+	} {
+		if item.value != "" {
+			details.WriteString(item.label + ": " + item.value + "\n")
+		}
+	}
+	if metadata.PublishedAt != nil {
+		details.WriteString("发布时间: " + metadata.PublishedAt.Format("2006-01-02") + "\n")
+	}
+	if metadata.ReadingTime > 0 {
+		details.WriteString(fmt.Sprintf("预计阅读时间: %d 分钟\n", metadata.ReadingTime))
+	}
+	// 特定网站的附加信息（仓库语言、星标数、视频时长等），按名称排序保证提示词稳定
+	keys := make([]string, 0, len(metadata.Details))
+	for key := range metadata.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		details.WriteString(key + ": " + metadata.Details[key] + "\n")
+	}
+
 	if pageTitle != "" || pageDesc != "" {
 		// 有抓取内容,使用真实信息
 		return fmt.Sprintf(`分析这个网页并返回JSON格式的书签信息:
@@ -146,7 +192,7 @@ func (s *AIService) buildPrompt(url string, metadata *models.PageMetadata) strin
 URL: %s
 网页标题: %s
 网页描述: %s
-
+%s%s
 请基于以上真实内容返回以下JSON格式(不要包含markdown代码块标记):
 {
   "title": "简洁的中文标题(20字内)",
@@ -158,7 +204,7 @@ URL: %s
 1. 标题要简洁明了,基于网页真实标题
 2. 描述要详实深邃，不要记流水账，要能体现网页的核心价值
 3. 标签要准确分类(3-5个)
-4. 只返回JSON,不要其他内容`, url, pageTitle, pageDesc)
+4. 只返回JSON,不要其他内容`, url, pageTitle, pageDesc, details.String(), content)
 	}
 
 	// 抓取失败,降级为只用URL
@@ -41,20 +41,27 @@ func (p *AIWorkerPool) Start() {
 	}
 }
 
-// Submit 提交任务
-func (p *AIWorkerPool) Submit(bookmarkID int) {
+// Submit 提交任务，返回是否成功入队
+func (p *AIWorkerPool) Submit(bookmarkID int) bool {
 	if !p.enabled {
 		log.Printf("ℹ️ AI Worker Pool 未启动，跳过任务: %d", bookmarkID)
-		return
+		return false
 	}
 	select {
 	case p.taskChan <- bookmarkID:
 		// 成功入队
+		return true
 	default:
 		log.Printf("⚠️ AI 任务队列已满 (size=1000)，忽略书签 ID: %d", bookmarkID)
+		return false
 	}
 }
 
+// Enabled 工作池是否已启动
+func (p *AIWorkerPool) Enabled() bool {
+	return p.enabled
+}
+
 // Stop 停止工作池
 func (p *AIWorkerPool) Stop() {
 	close(p.stopChan)
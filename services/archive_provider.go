@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/config"
+)
+
+// ArchiveProvider 外部网页存档服务，提交 URL 后返回存档快照地址
+type ArchiveProvider interface {
+	Name() string
+	Submit(ctx context.Context, url string) (string, error)
+}
+
+// NewArchiveProvider 按配置创建存档服务，未启用时返回 nil
+func NewArchiveProvider(cfg *config.Config) (ArchiveProvider, error) {
+	switch cfg.ArchiveProvider {
+	case "", "none":
+		return nil, nil
+	case "wayback":
+		return NewWaybackProvider(cfg.ArchiveAPIKey), nil
+	case "generic":
+		return NewGenericArchiveProvider(cfg.ArchiveEndpoint, cfg.ArchiveAPIKey)
+	default:
+		return nil, fmt.Errorf("不支持的存档服务: %s", cfg.ArchiveProvider)
+	}
+}
+
+// noRedirectClient 不跟随重定向的客户端，存档服务通过重定向地址返回快照位置
+func noRedirectClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// locationHeader 读取响应中的快照地址（Location 或 Content-Location），解析为绝对地址
+func locationHeader(resp *http.Response) string {
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		loc = resp.Header.Get("Content-Location")
+	}
+	if loc == "" {
+		return ""
+	}
+	u, err := resp.Request.URL.Parse(loc)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// GenericArchiveProvider 通用存档服务: POST 表单 url=<地址>，从 Location 响应头读取快照地址
+type GenericArchiveProvider struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewGenericArchiveProvider 创建通用存档服务，apiKey 非空时以 Bearer 方式发送
+func NewGenericArchiveProvider(endpoint, apiKey string) (*GenericArchiveProvider, error) {
+	u, err := neturl.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("无效的存档服务地址: %s", endpoint)
+	}
+	return &GenericArchiveProvider{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   noRedirectClient(60 * time.Second),
+	}, nil
+}
+
+// Name 存档服务名称
+func (p *GenericArchiveProvider) Name() string {
+	return "generic"
+}
+
+// Submit 提交 URL 并返回快照地址
+func (p *GenericArchiveProvider) Submit(ctx context.Context, url string) (string, error) {
+	form := neturl.Values{"url": {url}}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("存档服务返回错误状态: %s", resp.Status)
+	}
+	snapshotURL := locationHeader(resp)
+	if snapshotURL == "" {
+		return "", fmt.Errorf("存档服务未返回 Location")
+	}
+	return snapshotURL, nil
+}
+
+const (
+	waybackSaveURL      = "https://web.archive.org/save/"
+	waybackStatusURL    = "https://web.archive.org/save/status/"
+	waybackPollInterval = 5 * time.Second
+)
+
+// WaybackProvider Internet Archive Wayback Machine 的 Save Page Now 服务
+// 配置 S3 密钥（access:secret）时使用 SPN2 API 提交并轮询任务状态，否则匿名提交
+type WaybackProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewWaybackProvider 创建 Wayback Machine 存档服务
+func NewWaybackProvider(apiKey string) *WaybackProvider {
+	return &WaybackProvider{
+		apiKey: apiKey,
+		client: noRedirectClient(2 * time.Minute),
+	}
+}
+
+// Name 存档服务名称
+func (p *WaybackProvider) Name() string {
+	return "wayback"
+}
+
+// Submit 提交 URL 并返回快照地址
+func (p *WaybackProvider) Submit(ctx context.Context, url string) (string, error) {
+	if p.apiKey == "" {
+		return p.submitAnonymous(ctx, url)
+	}
+	return p.submitSPN2(ctx, url)
+}
+
+// submitAnonymous 匿名提交: GET /save/<url>，快照地址在重定向或 Content-Location 中
+func (p *WaybackProvider) submitAnonymous(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", waybackSaveURL+url, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("Wayback Machine 返回错误状态: %s", resp.Status)
+	}
+	snapshotURL := locationHeader(resp)
+	if !strings.Contains(snapshotURL, "/web/") {
+		return "", fmt.Errorf("Wayback Machine 未返回快照地址")
+	}
+	return snapshotURL, nil
+}
+
+// waybackJob SPN2 任务状态
+type waybackJob struct {
+	JobID       string `json:"job_id"`
+	Status      string `json:"status"` // pending | success | error
+	Timestamp   string `json:"timestamp"`
+	OriginalURL string `json:"original_url"`
+	Message     string `json:"message"`
+}
+
+// submitSPN2 通过 SPN2 API 提交并轮询任务直到完成
+func (p *WaybackProvider) submitSPN2(ctx context.Context, url string) (string, error) {
+	form := neturl.Values{"url": {url}, "skip_first_archive": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", waybackSaveURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	job, err := p.doSPN2(req)
+	if err != nil {
+		return "", err
+	}
+	if job.JobID == "" {
+		return "", fmt.Errorf("Wayback Machine 未返回任务ID: %s", job.Message)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("等待 Wayback Machine 存档超时: %w", ctx.Err())
+		case <-time.After(waybackPollInterval):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", waybackStatusURL+job.JobID, nil)
+		if err != nil {
+			return "", fmt.Errorf("创建请求失败: %w", err)
+		}
+		status, err := p.doSPN2(req)
+		if err != nil {
+			return "", err
+		}
+
+		switch status.Status {
+		case "success":
+			return "https://web.archive.org/web/" + status.Timestamp + "/" + status.OriginalURL, nil
+		case "error":
+			return "", fmt.Errorf("Wayback Machine 存档失败: %s", status.Message)
+		}
+	}
+}
+
+// doSPN2 发送带凭据的 SPN2 请求并解析任务状态
+func (p *WaybackProvider) doSPN2(req *http.Request) (*waybackJob, error) {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "LOW "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Wayback Machine 返回错误状态: %s", resp.Status)
+	}
+	var job waybackJob
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&job); err != nil {
+		return nil, fmt.Errorf("解析 Wayback Machine 响应失败: %w", err)
+	}
+	return &job, nil
+}
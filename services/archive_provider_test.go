@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+func TestGenericArchiveProviderSubmit(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		location string
+		want     string // 空字符串表示期望返回错误，{server} 替换为测试服务地址
+	}{
+		{"201 绝对地址", http.StatusCreated, "https://archive.example/snap/1", "https://archive.example/snap/1"},
+		{"302 绝对地址", http.StatusFound, "https://archive.example/snap/2", "https://archive.example/snap/2"},
+		{"302 相对地址", http.StatusFound, "/snap/3", "{server}/snap/3"},
+		{"201 相对路径", http.StatusCreated, "snap/4", "{server}/api/snap/4"},
+		{"缺少 Location", http.StatusCreated, "", ""},
+		{"5xx", http.StatusServiceUnavailable, "https://archive.example/snap/5", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotURL, gotAuth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				gotURL = r.PostForm.Get("url")
+				gotAuth = r.Header.Get("Authorization")
+				if tc.location != "" {
+					w.Header().Set("Location", tc.location)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			p, err := NewGenericArchiveProvider(srv.URL+"/api/save", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Submit(context.Background(), "https://example.com/page")
+			if gotURL != "https://example.com/page" || gotAuth != "Bearer secret" {
+				t.Errorf("请求参数 url=%q auth=%q", gotURL, gotAuth)
+			}
+
+			want := strings.ReplaceAll(tc.want, "{server}", srv.URL)
+			if want == "" {
+				if err == nil {
+					t.Errorf("期望返回错误，得到 %q", got)
+				}
+				return
+			}
+			if err != nil || got != want {
+				t.Errorf("Submit = %q, %v，期望 %q", got, err, want)
+			}
+		})
+	}
+}
+
+func TestWebArchiverRetriesWithBackoff(t *testing.T) {
+	initTestDB(t)
+	repo := db.NewBookmarkRepository()
+	bm, err := repo.Create(&models.BookmarkCreate{URL: "https://example.com/retry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 前两次返回 503，第三次返回快照地址
+	var mu sync.Mutex
+	var attempts []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		n := len(attempts)
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Location", "/snap/retry")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	provider, err := NewGenericArchiveProvider(srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const delay = 20 * time.Millisecond
+	archiver := NewWebArchiver(repo, provider)
+	archiver.retryDelay = delay
+	archiver.Start()
+	defer archiver.Stop()
+
+	if !archiver.Submit(bm.ID) {
+		t.Fatal("提交失败")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := repo.GetByID(bm.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.WebArchiveSnapshotURL != "" {
+			if got.WebArchiveSnapshotURL != srv.URL+"/snap/retry" {
+				t.Errorf("快照地址 = %q", got.WebArchiveSnapshotURL)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("等待外部存档超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 {
+		t.Fatalf("提交次数 = %d，期望 3", len(attempts))
+	}
+	// 每次重试的等待时间翻倍
+	for i, want := range []time.Duration{delay, 2 * delay} {
+		if gap := attempts[i+1].Sub(attempts[i]); gap < want {
+			t.Errorf("第 %d 次重试间隔 %v，期望至少 %v", i+1, gap, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// ArticleService 书签正文（阅读模式）的提取和存储
+// 新书签在后台提取，读取时尚未提取的书签同步提取
+type ArticleService struct {
+	repo         *db.ArticleRepository
+	bookmarkRepo *db.BookmarkRepository
+	scraper      *ScraperService
+	queue        chan int
+	workerCount  int
+	wg           sync.WaitGroup
+	started      bool
+}
+
+// NewArticleService 创建正文服务
+func NewArticleService(repo *db.ArticleRepository, bookmarkRepo *db.BookmarkRepository, scraper *ScraperService, workerCount int) *ArticleService {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	return &ArticleService{
+		repo:         repo,
+		bookmarkRepo: bookmarkRepo,
+		scraper:      scraper,
+		queue:        make(chan int, 1000),
+		workerCount:  workerCount,
+	}
+}
+
+// Start 启动后台 worker
+func (s *ArticleService) Start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	for i := 0; i < s.workerCount; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	log.Printf("📖 正文提取服务启动: %d workers", s.workerCount)
+}
+
+// Stop 停止后台 worker，等待进行中的任务完成
+func (s *ArticleService) Stop() {
+	if !s.started {
+		return
+	}
+	close(s.queue)
+	s.wg.Wait()
+}
+
+// Submit 提交书签到后台提取队列，返回是否成功入队
+func (s *ArticleService) Submit(bookmarkID int) bool {
+	if !s.started {
+		return false
+	}
+	select {
+	case s.queue <- bookmarkID:
+		return true
+	default:
+		log.Printf("⚠️ 正文提取队列已满，忽略书签 ID: %d", bookmarkID)
+		return false
+	}
+}
+
+func (s *ArticleService) worker() {
+	defer s.wg.Done()
+	for id := range s.queue {
+		if _, err := s.repo.Get(id); err == nil {
+			continue
+		}
+		if _, err := s.Extract(id); err != nil {
+			log.Printf("⚠️ 提取正文失败 ID=%d: %v", id, err)
+		}
+	}
+}
+
+// Get 获取书签正文，尚未提取时立即提取并保存；书签不存在或在回收站中时返回 sql.ErrNoRows
+func (s *ArticleService) Get(bookmarkID int) (*models.Article, error) {
+	if _, err := s.bookmarkRepo.GetActiveByID(bookmarkID); err != nil {
+		return nil, err
+	}
+	article, err := s.repo.Get(bookmarkID)
+	if err != sql.ErrNoRows {
+		return article, err
+	}
+	return s.Extract(bookmarkID)
+}
+
+// Extract 重新抓取书签页面并提取正文，覆盖已保存的内容；书签不存在或在回收站中时返回 sql.ErrNoRows
+func (s *ArticleService) Extract(bookmarkID int) (*models.Article, error) {
+	bm, err := s.bookmarkRepo.GetActiveByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.scraper.ScrapeArticle(bm.URL)
+	if err != nil {
+		return nil, err
+	}
+	article.BookmarkID = bookmarkID
+	if err := s.repo.Save(article); err != nil {
+		return nil, err
+	}
+	log.Printf("📖 正文提取完成: ID=%d, %d 字", bookmarkID, article.WordCount)
+	return article, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// unsafeFilenameChars 生成存储文件名时需要替换的字符
+var unsafeFilenameChars = regexp.MustCompile(`[^\w.\-]+`)
+
+// AssetService 书签附件服务，负责附件文件的存储
+type AssetService struct {
+	assetRepo *db.AssetRepository
+	dir       string
+}
+
+// NewAssetService 创建附件服务，dir 为附件存储目录
+func NewAssetService(assetRepo *db.AssetRepository, dir string) *AssetService {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("⚠️ 创建附件目录失败: %s, 错误: %v", dir, err)
+	}
+	return &AssetService{
+		assetRepo: assetRepo,
+		dir:       dir,
+	}
+}
+
+// List 获取书签的附件列表
+func (s *AssetService) List(bookmarkID int) ([]*models.BookmarkAsset, error) {
+	return s.assetRepo.ListByBookmark(bookmarkID)
+}
+
+// Get 获取书签的指定附件
+func (s *AssetService) Get(bookmarkID, id int) (*models.BookmarkAsset, error) {
+	return s.assetRepo.GetByID(bookmarkID, id)
+}
+
+// Upload 保存上传的文件并创建附件记录
+func (s *AssetService) Upload(bookmarkID int, displayName, contentType string, r io.Reader) (*models.BookmarkAsset, error) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(displayName))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	file, size, err := s.store(bookmarkID, "upload", displayName, r)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := s.assetRepo.Create(&models.BookmarkAsset{
+		BookmarkID:  bookmarkID,
+		AssetType:   models.AssetTypeUpload,
+		ContentType: contentType,
+		DisplayName: displayName,
+		File:        file,
+		FileSize:    size,
+		Status:      models.AssetStatusComplete,
+	})
+	if err != nil {
+		os.Remove(filepath.Join(s.dir, file))
+		return nil, err
+	}
+
+	log.Printf("📎 上传附件: 书签ID=%d, 文件=%s, 大小=%d", bookmarkID, displayName, size)
+	return asset, nil
+}
+
+// CreateSnapshot 创建待生成的网页快照附件记录
+func (s *AssetService) CreateSnapshot(bookmarkID int) (*models.BookmarkAsset, error) {
+	return s.assetRepo.Create(&models.BookmarkAsset{
+		BookmarkID:  bookmarkID,
+		AssetType:   models.AssetTypeSnapshot,
+		ContentType: "text/html; charset=utf-8",
+		DisplayName: fmt.Sprintf("snapshot_%s.html", time.Now().Format("20060102_150405")),
+		Status:      models.AssetStatusPending,
+	})
+}
+
+// CompleteSnapshot gzip 压缩保存快照内容，并将附件标记为完成
+func (s *AssetService) CompleteSnapshot(asset *models.BookmarkAsset, content []byte) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(content); err != nil {
+		return fmt.Errorf("压缩快照失败: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("压缩快照失败: %w", err)
+	}
+
+	file, size, err := s.store(asset.BookmarkID, "snapshot", asset.DisplayName+".gz", &buf)
+	if err != nil {
+		return err
+	}
+
+	asset.File = file
+	asset.FileSize = size
+	asset.Status = models.AssetStatusComplete
+	if err := s.assetRepo.Update(asset); err != nil {
+		os.Remove(filepath.Join(s.dir, file))
+		return err
+	}
+
+	log.Printf("📸 网页快照已保存: 书签ID=%d, 原始大小=%d, 压缩后=%d", asset.BookmarkID, len(content), size)
+	return nil
+}
+
+// FailSnapshot 将快照附件标记为失败
+func (s *AssetService) FailSnapshot(asset *models.BookmarkAsset) {
+	asset.Status = models.AssetStatusFailure
+	if err := s.assetRepo.Update(asset); err != nil {
+		log.Printf("⚠️ 更新快照状态失败: ID=%d, 错误: %v", asset.ID, err)
+	}
+}
+
+// IsCompressed 附件文件是否以 gzip 压缩存储（网页快照）
+func (s *AssetService) IsCompressed(asset *models.BookmarkAsset) bool {
+	return strings.HasSuffix(asset.File, ".gz")
+}
+
+// Open 打开附件文件
+func (s *AssetService) Open(asset *models.BookmarkAsset) (*os.File, error) {
+	if asset.File == "" {
+		return nil, fmt.Errorf("附件文件不存在")
+	}
+	return os.Open(filepath.Join(s.dir, asset.File))
+}
+
+// Delete 删除附件记录及其文件
+func (s *AssetService) Delete(asset *models.BookmarkAsset) error {
+	if err := s.assetRepo.Delete(asset.ID); err != nil {
+		return err
+	}
+	s.RemoveFile(asset)
+	return nil
+}
+
+// RemoveFile 删除附件文件（不删除记录），失败只记录日志
+func (s *AssetService) RemoveFile(asset *models.BookmarkAsset) {
+	if asset.File == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(s.dir, asset.File)); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️ 删除附件文件失败: %s, 错误: %v", asset.File, err)
+	}
+}
+
+// store 将内容写入附件目录，返回存储文件名和大小
+func (s *AssetService) store(bookmarkID int, prefix, displayName string, r io.Reader) (string, int64, error) {
+	name := unsafeFilenameChars.ReplaceAllString(filepath.Base(displayName), "_")
+	name = strings.Trim(name, "._")
+	if len(name) > 80 {
+		name = name[len(name)-80:]
+	}
+	file := fmt.Sprintf("%s_%d_%d_%s", prefix, bookmarkID, time.Now().UnixNano(), name)
+
+	f, err := os.Create(filepath.Join(s.dir, file))
+	if err != nil {
+		return "", 0, fmt.Errorf("创建附件文件失败: %w", err)
+	}
+
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath.Join(s.dir, file))
+		return "", 0, fmt.Errorf("写入附件文件失败: %w", err)
+	}
+
+	return file, size, nil
+}
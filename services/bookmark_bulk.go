@@ -0,0 +1,224 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// ErrAIDisabled AI 增强未启用（工作池未启动）
+var ErrAIDisabled = errors.New("AI功能未启用")
+
+// BulkRequestError 批量操作请求参数错误
+type BulkRequestError struct {
+	Message string
+}
+
+func (e *BulkRequestError) Error() string {
+	return e.Message
+}
+
+// BookmarkBulkService 书签批量操作服务
+type BookmarkBulkService struct {
+	bookmarkRepo *db.BookmarkRepository
+	folderRepo   *db.FolderRepository
+	aiWorkerPool *AIWorkerPool
+}
+
+// NewBookmarkBulkService 创建书签批量操作服务
+func NewBookmarkBulkService(bookmarkRepo *db.BookmarkRepository, folderRepo *db.FolderRepository, aiWorkerPool *AIWorkerPool) *BookmarkBulkService {
+	return &BookmarkBulkService{
+		bookmarkRepo: bookmarkRepo,
+		folderRepo:   folderRepo,
+		aiWorkerPool: aiWorkerPool,
+	}
+}
+
+// Apply 执行批量操作
+// 请求参数错误返回 *BulkRequestError，AI 未启用时 enhance 返回 ErrAIDisabled
+func (s *BookmarkBulkService) Apply(req *models.BulkRequest) (*models.BulkReport, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.resolveIDs(req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BulkReport{Operation: req.Operation, Results: []*models.BulkItemResult{}}
+
+	// AI 增强在事务外逐个提交到工作池
+	if req.Operation == models.BulkOpEnhance {
+		for _, id := range ids {
+			report.Add(s.enqueueEnhance(id))
+		}
+		return report, nil
+	}
+
+	results, err := s.bookmarkRepo.BulkApply(ids, s.operation(req))
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		report.Add(res)
+	}
+
+	log.Printf("📦 批量操作完成: %s, 共%d个, 成功%d, 失败%d", req.Operation, report.Total, report.Succeeded, report.Failed)
+	return report, nil
+}
+
+// validate 校验请求参数并规范化标签
+func (s *BookmarkBulkService) validate(req *models.BulkRequest) error {
+	if len(req.IDs) > 0 && req.Query != "" {
+		return &BulkRequestError{"ids 和 query 只能指定一个"}
+	}
+	if len(req.IDs) == 0 && strings.TrimSpace(req.Query) == "" {
+		return &BulkRequestError{"缺少 ids 或 query"}
+	}
+
+	switch req.Operation {
+	case models.BulkOpAddTags, models.BulkOpRemoveTags:
+		tags := make([]string, 0, len(req.Tags))
+		for _, t := range req.Tags {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+		if len(tags) == 0 {
+			return &BulkRequestError{"缺少 tags"}
+		}
+		req.Tags = tags
+
+	case models.BulkOpAddToFolders, models.BulkOpMoveToFolders, models.BulkOpRemoveFromFolders:
+		if len(req.FolderIDs) == 0 && req.Operation != models.BulkOpMoveToFolders {
+			return &BulkRequestError{"缺少 folder_ids"}
+		}
+		for _, folderID := range req.FolderIDs {
+			if _, err := s.folderRepo.GetByID(folderID); err != nil {
+				return &BulkRequestError{fmt.Sprintf("文件夹不存在: %d", folderID)}
+			}
+		}
+
+	case models.BulkOpEnhance:
+		if s.aiWorkerPool == nil || !s.aiWorkerPool.Enabled() {
+			return ErrAIDisabled
+		}
+
+	case models.BulkOpMarkRead, models.BulkOpMarkUnread,
+		models.BulkOpFavorite, models.BulkOpUnfavorite,
+		models.BulkOpShare, models.BulkOpUnshare,
+		models.BulkOpArchive, models.BulkOpUnarchive,
+		models.BulkOpDelete:
+
+	default:
+		return &BulkRequestError{fmt.Sprintf("不支持的批量操作: %s", req.Operation)}
+	}
+
+	return nil
+}
+
+// resolveIDs 确定要处理的书签ID（去重并保持顺序）
+func (s *BookmarkBulkService) resolveIDs(req *models.BulkRequest) ([]int, error) {
+	if req.Query != "" {
+		if _, err := db.ParseSearchQuery(req.Query); err != nil {
+			return nil, err
+		}
+		return s.bookmarkRepo.ListIDs(map[string]interface{}{"q": req.Query})
+	}
+
+	seen := make(map[int]bool, len(req.IDs))
+	ids := make([]int, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// operation 返回在事务中处理单个书签的函数
+func (s *BookmarkBulkService) operation(req *models.BulkRequest) func(tx *sql.Tx, id int) error {
+	setFlag := func(column string, value bool) func(tx *sql.Tx, id int) error {
+		return func(tx *sql.Tx, id int) error {
+			return s.bookmarkRepo.SetFlagTx(tx, id, column, value)
+		}
+	}
+
+	switch req.Operation {
+	case models.BulkOpAddTags:
+		return func(tx *sql.Tx, id int) error {
+			return s.bookmarkRepo.AddTagsTx(tx, id, req.Tags)
+		}
+	case models.BulkOpRemoveTags:
+		return func(tx *sql.Tx, id int) error {
+			return s.bookmarkRepo.RemoveTagsTx(tx, id, req.Tags)
+		}
+	case models.BulkOpAddToFolders, models.BulkOpMoveToFolders:
+		move := req.Operation == models.BulkOpMoveToFolders
+		return func(tx *sql.Tx, id int) error {
+			// 移动 = 先移出所有文件夹，再加入目标文件夹
+			if move {
+				if err := s.folderRepo.ClearBookmarkTx(tx, id); err != nil {
+					return err
+				}
+			}
+			for _, folderID := range req.FolderIDs {
+				if err := s.folderRepo.AddBookmarkTx(tx, id, folderID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case models.BulkOpRemoveFromFolders:
+		return func(tx *sql.Tx, id int) error {
+			for _, folderID := range req.FolderIDs {
+				if err := s.folderRepo.RemoveBookmarkTx(tx, id, folderID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case models.BulkOpMarkRead:
+		return setFlag("unread", false)
+	case models.BulkOpMarkUnread:
+		return setFlag("unread", true)
+	case models.BulkOpFavorite:
+		return setFlag("is_favorite", true)
+	case models.BulkOpUnfavorite:
+		return setFlag("is_favorite", false)
+	case models.BulkOpShare:
+		return setFlag("shared", true)
+	case models.BulkOpUnshare:
+		return setFlag("shared", false)
+	case models.BulkOpArchive:
+		return setFlag("is_archived", true)
+	case models.BulkOpUnarchive:
+		return setFlag("is_archived", false)
+	case models.BulkOpDelete:
+		return s.bookmarkRepo.DeleteTx
+	}
+	return nil
+}
+
+// enqueueEnhance 将单个书签提交到 AI 增强队列
+func (s *BookmarkBulkService) enqueueEnhance(id int) *models.BulkItemResult {
+	res := &models.BulkItemResult{ID: id}
+	if _, err := s.bookmarkRepo.GetActiveByID(id); err != nil {
+		res.Status = models.BulkStatusNotFound
+		return res
+	}
+	if !s.aiWorkerPool.Submit(id) {
+		res.Status = models.BulkStatusFailed
+		res.Error = "AI 任务队列已满"
+		return res
+	}
+	res.Status = models.BulkStatusQueued
+	return res
+}
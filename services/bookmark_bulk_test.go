@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// createBulkBookmarks 创建批量操作测试用的书签，返回书签ID
+func createBulkBookmarks(t *testing.T, repo *db.BookmarkRepository, tags ...[]string) []int {
+	t.Helper()
+	ids := make([]int, len(tags))
+	for i, tagNames := range tags {
+		bm, err := repo.Create(&models.BookmarkCreate{URL: fmt.Sprintf("https://example.com/%d", i), TagNames: tagNames})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = bm.ID
+	}
+	return ids
+}
+
+func TestBulkApplyPerIDResults(t *testing.T) {
+	initTestDB(t)
+	bookmarkRepo := db.NewBookmarkRepository()
+	folderRepo := db.NewFolderRepository(bookmarkRepo)
+	ids := createBulkBookmarks(t, bookmarkRepo, []string{"Go"}, nil)
+	if err := bookmarkRepo.Delete(ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	s := NewBookmarkBulkService(bookmarkRepo, folderRepo, nil)
+
+	// 重复的ID只处理一次，回收站中和不存在的书签返回 not_found
+	report, err := s.Apply(&models.BulkRequest{
+		IDs:       []int{ids[0], 9999, ids[1], ids[0]},
+		Operation: models.BulkOpAddTags,
+		Tags:      []string{" web ", "", "新标签"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*models.BulkItemResult{
+		{ID: ids[0], Status: models.BulkStatusOK},
+		{ID: 9999, Status: models.BulkStatusNotFound},
+		{ID: ids[1], Status: models.BulkStatusNotFound},
+	}
+	if !reflect.DeepEqual(report.Results, want) {
+		t.Errorf("结果 = %+v，期望 %+v", report.Results, want)
+	}
+	if report.Total != 3 || report.Succeeded != 1 || report.Failed != 2 {
+		t.Errorf("汇总 = %d/%d/%d", report.Total, report.Succeeded, report.Failed)
+	}
+
+	bm, err := bookmarkRepo.GetByID(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := append([]string{}, bm.TagNames...)
+	sort.Strings(tags)
+	if !reflect.DeepEqual(tags, []string{"Go", "web", "新标签"}) {
+		t.Errorf("标签 = %v", tags)
+	}
+
+	// 移除标签忽略大小写
+	if _, err := s.Apply(&models.BulkRequest{IDs: []int{ids[0]}, Operation: models.BulkOpRemoveTags, Tags: []string{"go", "WEB"}}); err != nil {
+		t.Fatal(err)
+	}
+	if bm, _ = bookmarkRepo.GetByID(ids[0]); !reflect.DeepEqual(bm.TagNames, []string{"新标签"}) {
+		t.Errorf("移除后标签 = %v，期望 [新标签]", bm.TagNames)
+	}
+}
+
+func TestBulkApplyQuerySelection(t *testing.T) {
+	initTestDB(t)
+	bookmarkRepo := db.NewBookmarkRepository()
+	folderRepo := db.NewFolderRepository(bookmarkRepo)
+	ids := createBulkBookmarks(t, bookmarkRepo, []string{"later"}, []string{"later", "pinned"}, []string{"other"})
+	folder, err := folderRepo.Create(&models.FolderCreate{Name: "稍后阅读"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewBookmarkBulkService(bookmarkRepo, folderRepo, nil)
+
+	report, err := s.Apply(&models.BulkRequest{Query: "tag:later -tag:pinned", Operation: models.BulkOpMoveToFolders, FolderIDs: []int{folder.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 1 || report.Results[0].ID != ids[0] || report.Results[0].Status != models.BulkStatusOK {
+		t.Fatalf("结果 = %+v，期望只处理书签 %d", report.Results, ids[0])
+	}
+	members, err := folderRepo.ListMemberships()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || !reflect.DeepEqual(members[ids[0]], []int{folder.ID}) {
+		t.Errorf("文件夹关联 = %v", members)
+	}
+
+	// 查询语法错误和参数错误在执行前返回
+	_, err = s.Apply(&models.BulkRequest{Query: "tag:later foo:bar", Operation: models.BulkOpFavorite})
+	var qerr *db.QueryError
+	if !errors.As(err, &qerr) {
+		t.Errorf("查询语法错误返回 %v，期望 *db.QueryError", err)
+	}
+	for _, req := range []*models.BulkRequest{
+		{IDs: []int{ids[0]}, Query: "tag:later", Operation: models.BulkOpFavorite},
+		{Operation: models.BulkOpFavorite},
+		{IDs: []int{ids[0]}, Operation: models.BulkOpAddTags, Tags: []string{" "}},
+		{IDs: []int{ids[0]}, Operation: models.BulkOpAddToFolders, FolderIDs: []int{9999}},
+		{IDs: []int{ids[0]}, Operation: "rename"},
+	} {
+		var reqErr *BulkRequestError
+		if _, err := s.Apply(req); !errors.As(err, &reqErr) {
+			t.Errorf("%+v 返回 %v，期望 *BulkRequestError", req, err)
+		}
+	}
+	if bm, _ := bookmarkRepo.GetByID(ids[0]); bm.IsFavorite {
+		t.Error("参数错误的请求修改了书签")
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// 支持的导出格式
+const (
+	ExportFormatHTML     = "html"
+	ExportFormatJSON     = "json"
+	ExportFormatCSV      = "csv"
+	ExportFormatMarkdown = "markdown"
+)
+
+// exportPageSize 导出时每次从数据库读取的书签数量
+const exportPageSize = 500
+
+// ExportFormatInfo 导出格式对应的 HTTP 内容类型和文件扩展名
+var ExportFormatInfo = map[string]struct {
+	ContentType string
+	Extension   string
+}{
+	ExportFormatHTML:     {"text/html; charset=utf-8", "html"},
+	ExportFormatJSON:     {"application/json; charset=utf-8", "json"},
+	ExportFormatCSV:      {"text/csv; charset=utf-8", "csv"},
+	ExportFormatMarkdown: {"text/markdown; charset=utf-8", "md"},
+}
+
+// exportedBookmark JSON 导出中的书签，附带所属文件夹
+type exportedBookmark struct {
+	*models.Bookmark
+	FolderIDs []int `json:"folder_ids"`
+}
+
+// BookmarkExporter 书签导出服务
+type BookmarkExporter struct {
+	bookmarkRepo *db.BookmarkRepository
+	folderRepo   *db.FolderRepository
+}
+
+// NewBookmarkExporter 创建书签导出服务
+func NewBookmarkExporter(bookmarkRepo *db.BookmarkRepository, folderRepo *db.FolderRepository) *BookmarkExporter {
+	return &BookmarkExporter{
+		bookmarkRepo: bookmarkRepo,
+		folderRepo:   folderRepo,
+	}
+}
+
+// Export 按指定格式将书签流式写入 w，filters 与书签列表接口一致
+func (e *BookmarkExporter) Export(w io.Writer, format string, filters map[string]interface{}) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case ExportFormatHTML:
+		err = e.exportHTML(bw, filters)
+	case ExportFormatJSON:
+		err = e.exportJSON(bw, filters)
+	case ExportFormatCSV:
+		err = e.exportCSV(bw, filters)
+	case ExportFormatMarkdown:
+		err = e.exportMarkdown(bw, filters)
+	default:
+		return fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// eachBookmark 分页遍历符合条件的书签
+func (e *BookmarkExporter) eachBookmark(filters map[string]interface{}, fn func(*models.Bookmark) error) error {
+	for offset := 0; ; offset += exportPageSize {
+		page, err := e.bookmarkRepo.List(exportPageSize, offset, filters)
+		if err != nil {
+			return err
+		}
+		for _, bm := range page {
+			if err := fn(bm); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// withFilter 复制过滤条件并附加一个额外条件
+func withFilter(filters map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(filters)+1)
+	for k, v := range filters {
+		merged[k] = v
+	}
+	merged[key] = value
+	return merged
+}
+
+// exportHTML 导出 Netscape HTML，文件夹输出为嵌套的 <H3> 分组
+func (e *BookmarkExporter) exportHTML(w io.Writer, filters map[string]interface{}) error {
+	folders, err := e.folderRepo.List()
+	if err != nil {
+		return fmt.Errorf("获取文件夹失败: %w", err)
+	}
+
+	if _, err := io.WriteString(w, netscapeHeader+"<DL><p>\n"); err != nil {
+		return err
+	}
+
+	for _, folder := range folders {
+		if err := writeNetscapeFolderStart(w, folder, "    "); err != nil {
+			return err
+		}
+		err := e.eachBookmark(withFilter(filters, "folder_id", folder.ID), func(bm *models.Bookmark) error {
+			return writeNetscapeBookmark(w, bm, "        ")
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "    </DL><p>\n"); err != nil {
+			return err
+		}
+	}
+
+	// 不属于任何文件夹的书签放在根目录
+	err = e.eachBookmark(withFilter(filters, "no_folder", true), func(bm *models.Bookmark) error {
+		return writeNetscapeBookmark(w, bm, "    ")
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "</DL><p>\n")
+	return err
+}
+
+// exportJSON 导出完整的书签和文件夹数据
+func (e *BookmarkExporter) exportJSON(w io.Writer, filters map[string]interface{}) error {
+	folders, err := e.folderRepo.List()
+	if err != nil {
+		return fmt.Errorf("获取文件夹失败: %w", err)
+	}
+	memberships, err := e.folderRepo.ListMemberships()
+	if err != nil {
+		return fmt.Errorf("获取文件夹关联失败: %w", err)
+	}
+
+	foldersJSON, err := json.Marshal(folders)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "{\"exported_at\":%q,\"folders\":%s,\"bookmarks\":[", time.Now().UTC().Format(time.RFC3339), foldersJSON); err != nil {
+		return err
+	}
+
+	first := true
+	err = e.eachBookmark(filters, func(bm *models.Bookmark) error {
+		folderIDs := memberships[bm.ID]
+		if folderIDs == nil {
+			folderIDs = []int{}
+		}
+		data, err := json.Marshal(exportedBookmark{Bookmark: bm, FolderIDs: folderIDs})
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// exportCSV 导出 CSV（带 UTF-8 BOM，方便 Excel 直接打开中文内容）
+func (e *BookmarkExporter) exportCSV(w io.Writer, filters map[string]interface{}) error {
+	folderNames, memberships, err := e.folderIndex()
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "url", "title", "description", "notes", "tags", "folders", "is_favorite", "unread", "shared", "is_archived", "date_added", "date_modified"})
+
+	err = e.eachBookmark(filters, func(bm *models.Bookmark) error {
+		names := make([]string, 0, len(memberships[bm.ID]))
+		for _, id := range memberships[bm.ID] {
+			names = append(names, folderNames[id])
+		}
+		return cw.Write([]string{
+			strconv.Itoa(bm.ID),
+			bm.URL,
+			bm.Title,
+			bm.Description,
+			bm.Notes,
+			strings.Join(bm.TagNames, ","),
+			strings.Join(names, ","),
+			strconv.FormatBool(bm.IsFavorite),
+			strconv.FormatBool(bm.Unread),
+			strconv.FormatBool(bm.Shared),
+			strconv.FormatBool(bm.IsArchived),
+			bm.DateAdded.UTC().Format(time.RFC3339),
+			bm.DateModified.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown 导出 Markdown，按文件夹分节
+func (e *BookmarkExporter) exportMarkdown(w io.Writer, filters map[string]interface{}) error {
+	folders, err := e.folderRepo.List()
+	if err != nil {
+		return fmt.Errorf("获取文件夹失败: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "# 书签导出\n\n导出时间: %s\n", time.Now().Format("2006-01-02 15:04:05")); err != nil {
+		return err
+	}
+
+	writeItem := func(bm *models.Bookmark) error {
+		title := bm.Title
+		if title == "" {
+			title = bm.URL
+		}
+		line := fmt.Sprintf("- [%s](%s)", escapeMarkdownText(title), bm.URL)
+		if bm.Description != "" {
+			line += " - " + escapeMarkdownText(bm.Description)
+		}
+		if len(bm.TagNames) > 0 {
+			line += " `" + strings.Join(bm.TagNames, "` `") + "`"
+		}
+		_, err := io.WriteString(w, line+"\n")
+		return err
+	}
+
+	for _, folder := range folders {
+		if _, err := fmt.Fprintf(w, "\n## %s %s\n\n", folder.Icon, folder.Name); err != nil {
+			return err
+		}
+		if err := e.eachBookmark(withFilter(filters, "folder_id", folder.ID), writeItem); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, "\n## 未分类\n\n"); err != nil {
+		return err
+	}
+	return e.eachBookmark(withFilter(filters, "no_folder", true), writeItem)
+}
+
+// folderIndex 返回文件夹ID到名称的映射以及书签的文件夹关联
+func (e *BookmarkExporter) folderIndex() (map[int]string, map[int][]int, error) {
+	folders, err := e.folderRepo.List()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取文件夹失败: %w", err)
+	}
+	memberships, err := e.folderRepo.ListMemberships()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取文件夹关联失败: %w", err)
+	}
+
+	names := make(map[int]string, len(folders))
+	for _, f := range folders {
+		names[f.ID] = f.Name
+	}
+	return names, memberships, nil
+}
+
+// escapeMarkdownText 转义 Markdown 链接文本中的特殊字符并压缩换行
+func escapeMarkdownText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(s)
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// initTestDB 在临时目录中初始化数据库，测试结束后关闭
+func initTestDB(t *testing.T) {
+	t.Helper()
+	if err := db.Init(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+}
+
+// exportedSnapshot 导出导入前后需要保持一致的书签字段
+type exportedSnapshot struct {
+	Title       string
+	Description string
+	Notes       string
+	Tags        []string
+	Folders     []string
+	IsFavorite  bool
+	Unread      bool
+	Shared      bool
+	DateAdded   int64
+}
+
+// seedExportBookmarks 创建两个文件夹和三个书签，其中一个同时属于两个文件夹、一个不属于任何文件夹
+func seedExportBookmarks(t *testing.T) (*db.BookmarkRepository, *db.FolderRepository) {
+	t.Helper()
+	bookmarkRepo := db.NewBookmarkRepository()
+	folderRepo := db.NewFolderRepository(bookmarkRepo)
+
+	dev, err := folderRepo.Create(&models.FolderCreate{Name: "开发"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading, err := folderRepo.Create(&models.FolderCreate{Name: "稍后阅读 & 归档"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []struct {
+		create  models.BookmarkCreate
+		folders []int
+		added   time.Time
+	}{
+		{
+			create: models.BookmarkCreate{
+				URL:         "https://go.dev/doc/",
+				Title:       `Go 文档 <"官方">`,
+				Description: "语言规范、教程 & 博客",
+				Notes:       "第一行笔记\n第二行 <b>不是标签</b>",
+				IsFavorite:  true,
+				TagNames:    []string{"go", "文档"},
+			},
+			folders: []int{dev.ID, reading.ID},
+			added:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			create: models.BookmarkCreate{
+				URL:      "https://example.com/article?id=1&lang=zh",
+				Title:    "稍后阅读的文章",
+				Unread:   true,
+				Shared:   true,
+				TagNames: []string{"阅读"},
+			},
+			folders: []int{reading.ID},
+			added:   time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			create: models.BookmarkCreate{
+				URL:         "https://example.org/",
+				Title:       "根目录书签",
+				Description: "不属于任何文件夹",
+			},
+			added: time.Date(2023, 6, 7, 8, 9, 10, 0, time.UTC),
+		},
+	}
+	for _, item := range items {
+		create := item.create
+		bm, err := bookmarkRepo.Create(&create)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.DB.Exec("UPDATE bookmarks SET date_added = ? WHERE id = ?", item.added.Format(time.RFC3339Nano), bm.ID); err != nil {
+			t.Fatal(err)
+		}
+		for _, folderID := range item.folders {
+			if err := folderRepo.AddBookmark(bm.ID, folderID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return bookmarkRepo, folderRepo
+}
+
+// snapshotBookmarks 按 URL 收集书签及所属文件夹名称
+func snapshotBookmarks(t *testing.T, bookmarkRepo *db.BookmarkRepository, folderRepo *db.FolderRepository) map[string]exportedSnapshot {
+	t.Helper()
+	bookmarks, err := bookmarkRepo.List(1000, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := make(map[string]exportedSnapshot, len(bookmarks))
+	for _, bm := range bookmarks {
+		folders, err := folderRepo.GetBookmarkFolders(bm.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, folder := range folders {
+			names = append(names, folder.Name)
+		}
+		sort.Strings(names)
+		tags := append([]string{}, bm.TagNames...)
+		sort.Strings(tags)
+		snapshot[bm.URL] = exportedSnapshot{
+			Title:       bm.Title,
+			Description: bm.Description,
+			Notes:       bm.Notes,
+			Tags:        tags,
+			Folders:     names,
+			IsFavorite:  bm.IsFavorite,
+			Unread:      bm.Unread,
+			Shared:      bm.Shared,
+			DateAdded:   bm.DateAdded.Unix(),
+		}
+	}
+	return snapshot
+}
+
+func TestExportHTMLRoundTrip(t *testing.T) {
+	initTestDB(t)
+	bookmarkRepo, folderRepo := seedExportBookmarks(t)
+	want := snapshotBookmarks(t, bookmarkRepo, folderRepo)
+
+	var buf bytes.Buffer
+	if err := NewBookmarkExporter(bookmarkRepo, folderRepo).Export(&buf, ExportFormatHTML, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 书签按文件夹分组在 <H3> 下
+	if !strings.Contains(buf.String(), "<H3") || strings.Count(buf.String(), "<A HREF=") != 4 {
+		t.Fatalf("导出结果缺少文件夹分组:\n%s", buf.String())
+	}
+
+	// 导入到新的数据库
+	db.Close()
+	initTestDB(t)
+	bookmarkRepo = db.NewBookmarkRepository()
+	folderRepo = db.NewFolderRepository(bookmarkRepo)
+	report, err := NewBookmarkImporter(bookmarkRepo, folderRepo).ImportNetscape(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != 0 || report.Skipped != 0 {
+		t.Fatalf("导入报告: %+v", report)
+	}
+
+	got := snapshotBookmarks(t, bookmarkRepo, folderRepo)
+	if len(got) != len(want) {
+		t.Fatalf("导入 %d 个书签，期望 %d 个", len(got), len(want))
+	}
+	for url, w := range want {
+		if g, ok := got[url]; !ok {
+			t.Errorf("缺少书签 %s", url)
+		} else if !reflect.DeepEqual(g, w) {
+			t.Errorf("%s:\n导入后 %+v\n导出前 %+v", url, g, w)
+		}
+	}
+}
+
+func TestExportCSVQuoting(t *testing.T) {
+	initTestDB(t)
+	bookmarkRepo := db.NewBookmarkRepository()
+	folderRepo := db.NewFolderRepository(bookmarkRepo)
+	create := &models.BookmarkCreate{
+		URL:         "https://example.com/?a=1,2",
+		Title:       `标题, 带 "引号"`,
+		Description: "第一行\n第二行",
+		Notes:       `=1+1`,
+		TagNames:    []string{"a", "b"},
+	}
+	if _, err := bookmarkRepo.Create(create); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewBookmarkExporter(bookmarkRepo, folderRepo).Export(&buf, ExportFormatCSV, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+	if !strings.HasPrefix(data, "\uFEFF") {
+		t.Fatal("CSV 缺少 UTF-8 BOM")
+	}
+	if !strings.Contains(data, `"标题, 带 ""引号"""`) {
+		t.Errorf("标题没有按 CSV 规则转义:\n%s", data)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("CSV 共 %d 行，期望表头加 1 行", len(records))
+	}
+	header, row := records[0], records[1]
+	field := func(name string) string {
+		for i, h := range header {
+			if h == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("CSV 缺少列 %s", name)
+		return ""
+	}
+	for name, want := range map[string]string{
+		"url":         create.URL,
+		"title":       create.Title,
+		"description": create.Description,
+		"notes":       create.Notes,
+		"tags":        "a,b",
+		"folders":     "",
+		"is_favorite": "false",
+	} {
+		if got := field(name); got != want {
+			t.Errorf("%s = %q，期望 %q", name, got, want)
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	initTestDB(t)
+	bookmarkRepo, folderRepo := seedExportBookmarks(t)
+
+	var buf bytes.Buffer
+	if err := NewBookmarkExporter(bookmarkRepo, folderRepo).Export(&buf, ExportFormatJSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	var exported struct {
+		ExportedAt string           `json:"exported_at"`
+		Folders    []*models.Folder `json:"folders"`
+		Bookmarks  []struct {
+			models.Bookmark
+			FolderIDs []int `json:"folder_ids"`
+		} `json:"bookmarks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("JSON 无法解析: %v\n%s", err, buf.String())
+	}
+	if _, err := time.Parse(time.RFC3339, exported.ExportedAt); err != nil {
+		t.Errorf("exported_at = %q", exported.ExportedAt)
+	}
+
+	folderNames := make(map[int]string)
+	for _, folder := range exported.Folders {
+		folderNames[folder.ID] = folder.Name
+	}
+	want := snapshotBookmarks(t, bookmarkRepo, folderRepo)
+	if len(exported.Bookmarks) != len(want) {
+		t.Fatalf("导出 %d 个书签，期望 %d 个", len(exported.Bookmarks), len(want))
+	}
+	for _, bm := range exported.Bookmarks {
+		if bm.FolderIDs == nil {
+			t.Errorf("%s 的 folder_ids 为 null", bm.URL)
+		}
+		names := []string{}
+		for _, id := range bm.FolderIDs {
+			names = append(names, folderNames[id])
+		}
+		sort.Strings(names)
+		tags := append([]string{}, bm.TagNames...)
+		sort.Strings(tags)
+		got := exportedSnapshot{
+			Title:       bm.Title,
+			Description: bm.Description,
+			Notes:       bm.Notes,
+			Tags:        tags,
+			Folders:     names,
+			IsFavorite:  bm.IsFavorite,
+			Unread:      bm.Unread,
+			Shared:      bm.Shared,
+			DateAdded:   bm.DateAdded.Unix(),
+		}
+		if !reflect.DeepEqual(got, want[bm.URL]) {
+			t.Errorf("%s:\nJSON %+v\n数据库 %+v", bm.URL, got, want[bm.URL])
+		}
+	}
+}
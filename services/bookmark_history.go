@@ -0,0 +1,116 @@
+package services
+
+import (
+	"log"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// BookmarkHistoryService 书签历史版本服务: 字段级差异和回退
+type BookmarkHistoryService struct {
+	bookmarkRepo *db.BookmarkRepository
+}
+
+// NewBookmarkHistoryService 创建历史版本服务
+func NewBookmarkHistoryService(bookmarkRepo *db.BookmarkRepository) *BookmarkHistoryService {
+	return &BookmarkHistoryService{
+		bookmarkRepo: bookmarkRepo.WithSource(models.RevisionSourceRevert),
+	}
+}
+
+// History 获取书签的历史版本（最新在前），每个版本附带相对上一版本的字段变化
+func (s *BookmarkHistoryService) History(bookmarkID int) ([]*models.BookmarkRevision, error) {
+	if _, err := s.bookmarkRepo.GetActiveByID(bookmarkID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.bookmarkRepo.ListRevisions(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *models.BookmarkSnapshot
+	for _, rev := range revisions {
+		rev.Changes = diffSnapshots(previous, rev.Snapshot)
+		previous = rev.Snapshot
+	}
+
+	// 最新版本在前
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+// Revert 将书签回退到指定历史版本，回退本身会记录为新版本
+func (s *BookmarkHistoryService) Revert(bookmarkID, revisionID int) (*models.Bookmark, error) {
+	rev, err := s.bookmarkRepo.GetRevision(bookmarkID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := rev.Snapshot
+	log.Printf("⏪ 回退书签: ID=%d, 版本=%d", bookmarkID, revisionID)
+	return s.bookmarkRepo.Update(bookmarkID, &models.BookmarkCreate{
+		URL:         snap.URL,
+		Title:       snap.Title,
+		Description: snap.Description,
+		Notes:       snap.Notes,
+		IsFavorite:  snap.IsFavorite,
+		Unread:      snap.Unread,
+		Shared:      snap.Shared,
+		IsArchived:  snap.IsArchived,
+		TagNames:    snap.TagNames,
+	})
+}
+
+// diffSnapshots 比较两个版本的字段，old 为 nil 表示书签的第一个版本
+func diffSnapshots(old, new *models.BookmarkSnapshot) []*models.FieldChange {
+	if old == nil {
+		old = &models.BookmarkSnapshot{TagNames: []string{}}
+	}
+
+	changes := []*models.FieldChange{}
+	addChange := func(field string, oldValue, newValue interface{}) {
+		if oldValue != newValue {
+			changes = append(changes, &models.FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	addChange("url", old.URL, new.URL)
+	addChange("title", old.Title, new.Title)
+	addChange("description", old.Description, new.Description)
+	addChange("notes", old.Notes, new.Notes)
+	addChange("is_favorite", old.IsFavorite, new.IsFavorite)
+	addChange("unread", old.Unread, new.Unread)
+	addChange("shared", old.Shared, new.Shared)
+	addChange("is_archived", old.IsArchived, new.IsArchived)
+
+	added := tagDifference(new.TagNames, old.TagNames)
+	removed := tagDifference(old.TagNames, new.TagNames)
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, &models.FieldChange{
+			Field:   "tag_names",
+			Old:     old.TagNames,
+			New:     new.TagNames,
+			Added:   added,
+			Removed: removed,
+		})
+	}
+	return changes
+}
+
+// tagDifference 返回在 a 中但不在 b 中的标签
+func tagDifference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, tag := range b {
+		inB[tag] = true
+	}
+	diff := []string{}
+	for _, tag := range a {
+		if !inB[tag] {
+			diff = append(diff, tag)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+	"ai-bookmark-service/utils"
+)
+
+// importBatchSize 每个事务导入的书签数量
+const importBatchSize = 200
+
+// BookmarkImporter 书签导入服务
+type BookmarkImporter struct {
+	bookmarkRepo *db.BookmarkRepository
+	folderRepo   *db.FolderRepository
+	batchSize    int
+}
+
+// NewBookmarkImporter 创建书签导入服务
+func NewBookmarkImporter(bookmarkRepo *db.BookmarkRepository, folderRepo *db.FolderRepository) *BookmarkImporter {
+	return &BookmarkImporter{
+		bookmarkRepo: bookmarkRepo,
+		folderRepo:   folderRepo,
+		batchSize:    importBatchSize,
+	}
+}
+
+// ImportNetscape 导入 Netscape HTML 书签文件（Chrome / Firefox / linkding 导出格式）
+func (i *BookmarkImporter) ImportNetscape(r io.Reader) (*models.ImportReport, error) {
+	report := &models.ImportReport{Items: []*models.ImportItemResult{}}
+	folderIDs := make(map[string]int)
+	batch := make([]*models.ImportItem, 0, i.batchSize)
+
+	flushBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := i.bookmarkRepo.ImportBatch(batch)
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			report.Add(res)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := ParseNetscapeBookmarks(r, func(item *models.ImportItem) error {
+		if reason := prepareImportItem(item); reason != "" {
+			report.Add(&models.ImportItemResult{
+				URL:    item.Bookmark.URL,
+				Title:  item.Bookmark.Title,
+				Status: models.ImportStatusSkipped,
+				Folder: item.FolderName,
+				Error:  reason,
+			})
+			return nil
+		}
+
+		if item.FolderName != "" {
+			id, ok := folderIDs[item.FolderName]
+			if !ok {
+				folder, err := i.folderRepo.GetOrCreateByName(item.FolderName)
+				if err != nil {
+					log.Printf("⚠️ 创建导入文件夹失败: %s, 错误: %v", item.FolderName, err)
+				} else {
+					id = folder.ID
+				}
+				folderIDs[item.FolderName] = id
+			}
+			item.FolderID = id
+		}
+
+		batch = append(batch, item)
+		if len(batch) >= i.batchSize {
+			return flushBatch()
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("解析书签文件失败: %w", err)
+	}
+
+	if err := flushBatch(); err != nil {
+		return report, err
+	}
+
+	log.Printf("📥 导入完成: 共%d, 新建%d, 更新%d, 跳过%d, 失败%d",
+		report.Total, report.Created, report.Updated, report.Skipped, report.Failed)
+	return report, nil
+}
+
+// prepareImportItem 规范化并校验导入项，返回非空字符串表示跳过原因
+func prepareImportItem(item *models.ImportItem) string {
+	bm := &item.Bookmark
+	if bm.URL == "" {
+		return "缺少URL"
+	}
+
+	// 与创建接口一致的自动截断
+	bm.Title = utils.TruncateText(bm.Title, 200)
+	bm.Description = utils.TruncateText(bm.Description, 1000)
+	bm.Notes = utils.TruncateText(bm.Notes, 2000)
+	if len(bm.TagNames) > 50 {
+		bm.TagNames = bm.TagNames[:50]
+	}
+
+	if err := utils.ValidateBookmarkCreate(bm); err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// metaCharsetScanSize 查找 <meta charset> 时扫描的最大字节数
+// 规范只要求前 1024 字节，但不少中文网站把声明放在较长的 <head> 之后
+const metaCharsetScanSize = 8 * 1024
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// decodeHTML 识别网页编码并转换为 UTF-8，返回转换后的内容和识别出的编码名称
+func decodeHTML(body []byte, contentType string) ([]byte, string, error) {
+	enc, name := detectHTMLCharset(body, contentType)
+	if name != "utf-8" {
+		decoded, err := enc.NewDecoder().Bytes(body)
+		if err != nil {
+			return nil, name, fmt.Errorf("转换网页编码 %s 失败: %w", name, err)
+		}
+		body = decoded
+	}
+	return bytes.TrimPrefix(body, utf8BOM), name, nil
+}
+
+// detectHTMLCharset 识别网页编码，优先级: BOM > Content-Type 响应头 > <meta charset>/http-equiv
+// 都没有声明时，内容是合法 UTF-8 则按 UTF-8 处理，否则按 GB18030（兼容 GBK/GB2312）处理
+func detectHTMLCharset(body []byte, contentType string) (encoding.Encoding, string) {
+	// 不传 Content-Type 时只有 BOM 的识别结果是确定的
+	if enc, name, certain := charset.DetermineEncoding(body, ""); certain {
+		return enc, name
+	}
+
+	var headerEnc encoding.Encoding
+	var headerName string
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		headerEnc, headerName = charset.Lookup(params["charset"])
+	}
+	metaEnc, metaName := findMetaCharset(body)
+	validUTF8 := isValidUTF8Prefix(body)
+
+	switch {
+	// 服务器默认声明 UTF-8 而页面实际是其他编码的情况很常见，此时以页面内的声明为准
+	case headerEnc != nil && !(headerName == "utf-8" && !validUTF8 && metaEnc != nil):
+		return headerEnc, headerName
+	case metaEnc != nil:
+		return metaEnc, metaName
+	case validUTF8:
+		return encoding.Nop, "utf-8"
+	default:
+		return simplifiedchinese.GB18030, "gb18030"
+	}
+}
+
+// findMetaCharset 在 <head> 中查找 <meta charset> 或 <meta http-equiv="Content-Type"> 声明的编码
+func findMetaCharset(body []byte) (encoding.Encoding, string) {
+	if len(body) > metaCharsetScanSize {
+		body = body[:metaCharsetScanSize]
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return nil, ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return nil, ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "body" {
+				return nil, ""
+			}
+			if string(name) != "meta" {
+				continue
+			}
+
+			var label, content string
+			pragma := false
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					label = string(val)
+				case "http-equiv":
+					pragma = strings.EqualFold(string(val), "content-type")
+				case "content":
+					content = string(val)
+				}
+			}
+			if label == "" && pragma {
+				if _, params, err := mime.ParseMediaType(content); err == nil {
+					label = params["charset"]
+				}
+			}
+			if label == "" {
+				continue
+			}
+			if enc, name := charset.Lookup(label); enc != nil {
+				// 页面内声明 UTF-16 时内容必然是 ASCII 兼容的，按规范视为 UTF-8
+				if strings.HasPrefix(name, "utf-16") {
+					return encoding.Nop, "utf-8"
+				}
+				return enc, name
+			}
+		}
+	}
+}
+
+// isValidUTF8Prefix 内容是否为合法 UTF-8，忽略截断读取时末尾不完整的字符
+func isValidUTF8Prefix(body []byte) bool {
+	for i := len(body) - 1; i >= 0 && i > len(body)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				body = body[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(body)
+}
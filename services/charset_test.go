@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestPage 读取 testdata 下保存的网页
+func loadTestPage(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeHTMLTestPages(t *testing.T) {
+	tests := []struct {
+		file        string
+		contentType string
+		wantName    string
+		wantText    string
+	}{
+		{"gbk.html", "text/html", "gbk", "这是一个使用 GBK 编码的中文网页。"},
+		{"gb18030.html", "text/html", "gb18030", "包含 GBK 之外的字符：𠀀㐀。"},
+		{"big5.html", "text/html", "big5", "這是一個使用 Big5 編碼的繁體中文網頁。"},
+		{"shift_jis.html", "text/html", "shift_jis", "これは Shift_JIS でエンコードされたページです。"},
+		{"utf8_bom.html", "text/html", "utf-8", "带 BOM 的 UTF-8 网页，meta 声明被忽略。"},
+		{"utf16le_bom.html", "text/html", "utf-16le", "带 BOM 的 UTF-16LE 网页。"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			decoded, name, err := decodeHTML(loadTestPage(t, filepath.Join("charset", tc.file)), tc.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tc.wantName {
+				t.Errorf("编码 = %q，期望 %q", name, tc.wantName)
+			}
+			if !strings.Contains(string(decoded), tc.wantText) {
+				t.Errorf("转换结果不包含 %q:\n%s", tc.wantText, decoded)
+			}
+			if !strings.HasPrefix(string(decoded), "<!DOCTYPE html>") {
+				t.Errorf("转换结果开头 = %q", decoded[:16])
+			}
+		})
+	}
+}
+
+func TestDetectHTMLCharsetPrecedence(t *testing.T) {
+	gbkPage := loadTestPage(t, "charset/gbk.html")
+	utf8Page := []byte("<html><head><meta charset=\"gbk\"></head><body>实际是 UTF-8 的页面</body></html>")
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{"BOM 优先于响应头", loadTestPage(t, "charset/utf8_bom.html"), "text/html; charset=big5", "utf-8"},
+		{"UTF-16 BOM 优先于响应头", loadTestPage(t, "charset/utf16le_bom.html"), "text/html; charset=utf-8", "utf-16le"},
+		{"响应头优先于 meta", gbkPage, "text/html; charset=gb18030", "gb18030"},
+		{"内容是合法 UTF-8 时 UTF-8 响应头优先于 meta", utf8Page, "text/html; charset=utf-8", "utf-8"},
+		{"内容不是 UTF-8 时 meta 覆盖 UTF-8 响应头", gbkPage, "text/html; charset=utf-8", "gbk"},
+		{"http-equiv 声明", loadTestPage(t, "charset/big5.html"), "text/html", "big5"},
+		{"无声明的 UTF-8", []byte("<html><body>中文内容</body></html>"), "", "utf-8"},
+		{"无声明时回退到 GB18030", loadTestPage(t, "charset/gb18030.html"), "text/html", "gb18030"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, got := detectHTMLCharset(tc.body, tc.contentType); got != tc.want {
+				t.Errorf("编码 = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectHTMLCharsetTruncatedAtReadLimit(t *testing.T) {
+	// 模拟 scrapeWebPage 只读取前 128KB，最后一个三字节的汉字被截断
+	const readLimit = 128 * 1024
+	var buf bytes.Buffer
+	buf.WriteString("<html><head><title>无编码声明</title></head><body>")
+	for buf.Len() < readLimit {
+		buf.WriteString("中文")
+	}
+	body := buf.Bytes()[:readLimit]
+	if (readLimit-len("<html><head><title>无编码声明</title></head><body>"))%3 == 0 {
+		t.Fatal("测试数据没有截断多字节字符")
+	}
+
+	if _, name := detectHTMLCharset(body, "text/html"); name != "utf-8" {
+		t.Errorf("截断的 UTF-8 页面编码 = %q，期望 utf-8", name)
+	}
+	// 截断位置之前有非法字节时仍回退到 GB18030
+	corrupted := append([]byte{}, body...)
+	corrupted[readLimit-10] = 0xff
+	if _, name := detectHTMLCharset(corrupted, "text/html"); name != "gb18030" {
+		t.Errorf("非法 UTF-8 页面编码 = %q，期望 gb18030", name)
+	}
+}
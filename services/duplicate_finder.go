@@ -0,0 +1,293 @@
+package services
+
+import (
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// DefaultDuplicateThreshold 内容相似度的默认阈值（Jaccard）
+const DefaultDuplicateThreshold = 0.8
+
+const (
+	minDuplicateTitleRunes   = 8  // 过短的标题（如 "Home"）不参与标题查重
+	minDuplicateContentRunes = 30 // 参与内容查重的最短文本
+	contentShingleSize       = 5  // 内容查重使用的字符 n-gram 长度
+	maxShinglePostings       = 50 // 出现在过多书签中的 n-gram 不作为候选依据
+)
+
+// looseHostPrefixes 宽松 URL 比较时忽略的子域名前缀（移动版、AMP）
+var looseHostPrefixes = []string{"www.", "m.", "mobile.", "amp."}
+
+// DuplicateFinder 查找疑似重复的书签并合并
+type DuplicateFinder struct {
+	bookmarkRepo *db.BookmarkRepository
+}
+
+// NewDuplicateFinder 创建重复书签查找服务
+func NewDuplicateFinder(bookmarkRepo *db.BookmarkRepository) *DuplicateFinder {
+	return &DuplicateFinder{bookmarkRepo: bookmarkRepo}
+}
+
+// DuplicateRequestError 合并请求参数错误
+type DuplicateRequestError struct {
+	Message string
+}
+
+func (e *DuplicateRequestError) Error() string {
+	return e.Message
+}
+
+// FindGroups 按宽松规范 URL、相同标题和内容相似度对书签分组，threshold 为内容相似度阈值
+func (f *DuplicateFinder) FindGroups(threshold float64) ([]*models.DuplicateGroup, error) {
+	candidates, err := f.bookmarkRepo.ListDuplicateCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	uf := newDuplicateUnion(len(candidates))
+
+	// 1. 宽松规范 URL 和标题完全相同
+	byURL := map[string]int{}
+	byTitle := map[string]int{}
+	for i, c := range candidates {
+		if key := looseURLKey(c.CanonicalURL); key != "" {
+			if j, ok := byURL[key]; ok {
+				uf.union(j, i, models.DuplicateReasonURL, 0)
+			} else {
+				byURL[key] = i
+			}
+		}
+		if title := normalizeDuplicateText(c.Title); len([]rune(title)) >= minDuplicateTitleRunes {
+			if j, ok := byTitle[title]; ok {
+				uf.union(j, i, models.DuplicateReasonTitle, 0)
+			} else {
+				byTitle[title] = i
+			}
+		}
+	}
+
+	// 2. 内容相似度: 通过 n-gram 倒排索引找候选对，再计算 Jaccard 相似度
+	shingles := make([]map[uint64]bool, len(candidates))
+	postings := map[uint64][]int{}
+	for i, c := range candidates {
+		shingles[i] = contentShingles(c.Title + " " + c.Description)
+		for s := range shingles[i] {
+			postings[s] = append(postings[s], i)
+		}
+	}
+	for i := range candidates {
+		shared := map[int]int{}
+		for s := range shingles[i] {
+			docs := postings[s]
+			if len(docs) > maxShinglePostings {
+				continue
+			}
+			for _, j := range docs {
+				if j > i {
+					shared[j]++
+				}
+			}
+		}
+		for j, n := range shared {
+			similarity := float64(n) / float64(len(shingles[i])+len(shingles[j])-n)
+			if similarity >= threshold {
+				uf.union(i, j, models.DuplicateReasonContent, similarity)
+			}
+		}
+	}
+
+	// 3. 组装分组（候选已按添加时间正序，组内第一个即最早添加）
+	groups := []*models.DuplicateGroup{}
+	for _, members := range uf.components() {
+		root := uf.find(members[0])
+		group := &models.DuplicateGroup{
+			Reasons:    uf.reasonList(root),
+			Similarity: uf.similarity[root],
+			Bookmarks:  make([]*models.Bookmark, 0, len(members)),
+		}
+		for _, i := range members {
+			bm, err := f.bookmarkRepo.GetByID(candidates[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			group.Bookmarks = append(group.Bookmarks, bm)
+		}
+		group.SuggestedTargetID = group.Bookmarks[0].ID
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// Merge 将 req.IDs 合并到 req.TargetID
+func (f *DuplicateFinder) Merge(req *models.MergeRequest) (*models.Bookmark, error) {
+	if req.TargetID <= 0 {
+		return nil, &DuplicateRequestError{Message: "target_id 不能为空"}
+	}
+	seen := map[int]bool{req.TargetID: true}
+	ids := []int{}
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, &DuplicateRequestError{Message: "ids 中至少需要一个不同于 target_id 的书签"}
+	}
+
+	if err := f.bookmarkRepo.Merge(req.TargetID, ids); err != nil {
+		return nil, err
+	}
+	return f.bookmarkRepo.GetByID(req.TargetID)
+}
+
+// looseURLKey 宽松比较用的 URL: 忽略协议、www/m/amp 子域名和 AMP 路径或参数
+func looseURLKey(canonical string) string {
+	u, err := url.Parse(canonical)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := u.Host
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, prefix := range looseHostPrefixes {
+			if strings.HasPrefix(host, prefix) && strings.Count(host, ".") > 1 {
+				host = strings.TrimPrefix(host, prefix)
+				trimmed = true
+			}
+		}
+	}
+
+	path := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/amp")
+	path = strings.TrimSuffix(path, ".amp")
+	if strings.HasPrefix(path, "/amp/") {
+		path = path[len("/amp"):]
+	}
+
+	query := u.Query()
+	query.Del("amp")
+	if strings.EqualFold(query.Get("outputType"), "amp") {
+		query.Del("outputType")
+	}
+
+	key := host + path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+// normalizeDuplicateText 小写并合并空白
+func normalizeDuplicateText(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}), " ")
+}
+
+// contentShingles 文本的字符 n-gram 集合（取哈希），文本过短时返回空集合
+func contentShingles(text string) map[uint64]bool {
+	runes := []rune(normalizeDuplicateText(text))
+	set := map[uint64]bool{}
+	if len(runes) < minDuplicateContentRunes {
+		return set
+	}
+	for i := 0; i+contentShingleSize <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+contentShingleSize])))
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+// duplicateUnion 并查集，记录每个分组的判定依据
+type duplicateUnion struct {
+	parent     []int
+	reasons    map[int]map[string]bool
+	similarity map[int]float64
+}
+
+func newDuplicateUnion(n int) *duplicateUnion {
+	u := &duplicateUnion{
+		parent:     make([]int, n),
+		reasons:    map[int]map[string]bool{},
+		similarity: map[int]float64{},
+	}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *duplicateUnion) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union 合并两个元素所在的分组，根始终取较小的下标（即较早添加的书签）
+func (u *duplicateUnion) union(a, b int, reason string, similarity float64) {
+	ra, rb := u.find(a), u.find(b)
+	if ra > rb {
+		ra, rb = rb, ra
+	}
+	if u.reasons[ra] == nil {
+		u.reasons[ra] = map[string]bool{}
+	}
+	if ra != rb {
+		u.parent[rb] = ra
+		for r := range u.reasons[rb] {
+			u.reasons[ra][r] = true
+		}
+		if u.similarity[rb] > u.similarity[ra] {
+			u.similarity[ra] = u.similarity[rb]
+		}
+		delete(u.reasons, rb)
+		delete(u.similarity, rb)
+	}
+	u.reasons[ra][reason] = true
+	if similarity > u.similarity[ra] {
+		u.similarity[ra] = similarity
+	}
+}
+
+// components 返回成员数大于 1 的分组，组内按下标排序
+func (u *duplicateUnion) components() [][]int {
+	byRoot := map[int][]int{}
+	for i := range u.parent {
+		root := u.find(i)
+		byRoot[root] = append(byRoot[root], i)
+	}
+	roots := []int{}
+	for root, members := range byRoot {
+		if len(members) > 1 {
+			roots = append(roots, root)
+		}
+	}
+	sort.Ints(roots)
+
+	groups := make([][]int, 0, len(roots))
+	for _, root := range roots {
+		groups = append(groups, byRoot[root])
+	}
+	return groups
+}
+
+// reasonList 分组的判定依据（按固定顺序）
+func (u *duplicateUnion) reasonList(root int) []string {
+	reasons := []string{}
+	for _, r := range []string{models.DuplicateReasonURL, models.DuplicateReasonTitle, models.DuplicateReasonContent} {
+		if u.reasons[root][r] {
+			reasons = append(reasons, r)
+		}
+	}
+	return reasons
+}
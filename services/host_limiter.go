@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// hostLimiter 限制同一主机同时进行的请求数
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]*hostSlot
+}
+
+// hostSlot 主机的并发名额，users 为持有或等待名额的请求数，为 0 时从 slots 中移除
+type hostSlot struct {
+	ch    chan struct{}
+	users int
+}
+
+// newHostLimiter 创建主机并发限制器，limit <= 0 时不限制
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, slots: map[string]*hostSlot{}}
+}
+
+// Acquire 等待主机的空闲名额，返回释放名额的函数；ctx 结束时放弃等待
+func (l *hostLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	if l.limit <= 0 {
+		return func() {}, nil
+	}
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	slot, ok := l.slots[host]
+	if !ok {
+		slot = &hostSlot{ch: make(chan struct{}, l.limit)}
+		l.slots[host] = slot
+	}
+	slot.users++
+	l.mu.Unlock()
+
+	select {
+	case slot.ch <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-slot.ch
+				l.leave(host, slot)
+			})
+		}, nil
+	case <-ctx.Done():
+		l.leave(host, slot)
+		return nil, ctx.Err()
+	}
+}
+
+// leave 减少名额的使用数，没有请求使用时移除，避免抓取过的主机一直留在 slots 中
+func (l *hostLimiter) leave(host string, slot *hostSlot) {
+	l.mu.Lock()
+	slot.users--
+	if slot.users == 0 {
+		delete(l.slots, host)
+	}
+	l.mu.Unlock()
+}
+
+// releaseOnClose 关闭响应体时释放主机名额并结束请求的 context
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
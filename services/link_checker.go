@@ -0,0 +1,222 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+const (
+	// linkCheckHostDelay 同一主机两次请求的最小间隔
+	linkCheckHostDelay = 2 * time.Second
+	// linkCheckBatchSize 每轮定时检查最多入队的书签数
+	linkCheckBatchSize = 200
+)
+
+// LinkChecker 后台定时检查书签链接是否可用，记录状态码、重定向地址和连续失败次数
+// 通过固定数量的 worker 限制并发，并对同一主机的请求限速
+type LinkChecker struct {
+	bookmarkRepo *db.BookmarkRepository
+	linkRepo     *db.LinkCheckRepository
+	scraper      *ScraperService
+	interval     time.Duration // 0 表示不定时检查
+	workerCount  int
+	queue        chan int
+	wg           sync.WaitGroup
+	stopChan     chan struct{}
+	started      bool
+
+	mu       sync.Mutex
+	pending  map[int]bool         // 已入队尚未检查的书签
+	hostNext map[string]time.Time // 各主机下一次允许请求的时间
+}
+
+// NewLinkChecker 创建链接健康检查服务，intervalHours 为同一书签两次检查的间隔
+func NewLinkChecker(bookmarkRepo *db.BookmarkRepository, linkRepo *db.LinkCheckRepository, scraper *ScraperService, intervalHours, workerCount int) *LinkChecker {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	return &LinkChecker{
+		bookmarkRepo: bookmarkRepo,
+		linkRepo:     linkRepo,
+		scraper:      scraper,
+		interval:     time.Duration(intervalHours) * time.Hour,
+		workerCount:  workerCount,
+		queue:        make(chan int, linkCheckBatchSize*5),
+		stopChan:     make(chan struct{}),
+		pending:      make(map[int]bool),
+		hostNext:     make(map[string]time.Time),
+	}
+}
+
+// Start 启动 worker 和定时检查，未配置检查间隔时只处理手动提交的检查
+func (c *LinkChecker) Start() {
+	if c.started {
+		return
+	}
+	c.started = true
+	for i := 0; i < c.workerCount; i++ {
+		c.wg.Add(1)
+		go c.worker()
+	}
+
+	if c.interval <= 0 {
+		log.Printf("ℹ️ 链接定时检查已关闭")
+		return
+	}
+	log.Printf("🩺 链接健康检查启动: %d workers, 每 %d 小时检查一次", c.workerCount, int(c.interval.Hours()))
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			c.SubmitDue(time.Now().Add(-c.interval))
+			select {
+			case <-ticker.C:
+			case <-c.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时检查和 worker，等待进行中的检查完成
+func (c *LinkChecker) Stop() {
+	if !c.started {
+		return
+	}
+	close(c.stopChan)
+	c.mu.Lock()
+	c.started = false
+	close(c.queue)
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// SubmitDue 将最近一次检查早于 before 的书签入队，返回入队数量
+func (c *LinkChecker) SubmitDue(before time.Time) int {
+	ids, err := c.linkRepo.ListDue(before, linkCheckBatchSize)
+	if err != nil {
+		log.Printf("⚠️ 查询待检查链接失败: %v", err)
+		return 0
+	}
+	return c.Submit(ids)
+}
+
+// Submit 将书签入队检查，已在队列中的书签会被跳过，返回入队数量
+func (c *LinkChecker) Submit(ids []int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started {
+		return 0
+	}
+
+	submitted := 0
+	for _, id := range ids {
+		if c.pending[id] {
+			continue
+		}
+		select {
+		case c.queue <- id:
+			c.pending[id] = true
+			submitted++
+		default:
+			log.Printf("⚠️ 链接检查队列已满，忽略书签 ID: %d", id)
+			return submitted
+		}
+	}
+	return submitted
+}
+
+// Report 生成链接健康报告
+func (c *LinkChecker) Report() (*models.LinkReport, error) {
+	return c.linkRepo.Report()
+}
+
+// Get 获取书签最近一次的检查结果
+func (c *LinkChecker) Get(bookmarkID int) (*models.LinkCheck, error) {
+	return c.linkRepo.Get(bookmarkID)
+}
+
+func (c *LinkChecker) worker() {
+	defer c.wg.Done()
+	for id := range c.queue {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		if _, err := c.Check(id); err != nil {
+			log.Printf("⚠️ 检查链接失败 ID=%d: %v", id, err)
+		}
+	}
+}
+
+// Check 立即检查单个书签的链接并保存结果
+func (c *LinkChecker) Check(bookmarkID int) (*models.LinkCheck, error) {
+	bm, err := c.bookmarkRepo.GetByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bm.DateDeleted != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	c.waitHost(bm.URL)
+	statusCode, finalURL, err := c.scraper.CheckLink(bm.URL)
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	check, err := c.linkRepo.Record(bookmarkID, statusCode, finalURL, errMsg, linkCheckFailed(statusCode, err))
+	if err != nil {
+		return nil, err
+	}
+	if check.ConsecutiveFailures > 0 {
+		log.Printf("🔗 链接不可用: ID=%d, 状态码=%d, 连续失败=%d, %s", bookmarkID, statusCode, check.ConsecutiveFailures, errMsg)
+	}
+	return check, nil
+}
+
+// waitHost 按主机限速，距离该主机上一次请求不足 linkCheckHostDelay 时等待
+func (c *LinkChecker) waitHost(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+	host := strings.ToLower(u.Hostname())
+
+	c.mu.Lock()
+	now := time.Now()
+	next := c.hostNext[host]
+	if next.Before(now) {
+		next = now
+	}
+	c.hostNext[host] = next.Add(linkCheckHostDelay)
+	// 清理已过期的主机记录，避免无限增长
+	if len(c.hostNext) > 1000 {
+		for h, t := range c.hostNext {
+			if t.Before(now) {
+				delete(c.hostNext, h)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	time.Sleep(time.Until(next))
+}
+
+// linkCheckFailed 判断检查结果是否算作失败
+// 401/403/429 等通常是登录或反爬虫拦截，不代表链接失效
+func linkCheckFailed(statusCode int, err error) bool {
+	if err != nil {
+		return true
+	}
+	return statusCode == 404 || statusCode == 410 || statusCode >= 500
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// mediaLimits 各类媒体的下载大小上限和缩放后的最大边长
+var mediaLimits = map[string]struct {
+	maxSize int64
+	maxDim  int
+}{
+	models.MediaKindFavicon: {maxSize: 1 << 20, maxDim: 64},
+	models.MediaKindPreview: {maxSize: 5 << 20, maxDim: 800},
+}
+
+// mediaMaxPixels 解码前检查的最大像素数，防止解压炸弹
+const mediaMaxPixels = 40_000_000
+
+// mediaFilePattern 缓存文件名: 内容 SHA-256 + 扩展名
+var mediaFilePattern = regexp.MustCompile(`^[0-9a-f]{64}\.(png|jpg|gif|ico|webp|bmp|svg)$`)
+
+// mediaContentTypes 扩展名对应的 Content-Type
+var mediaContentTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".gif":  "image/gif",
+	".ico":  "image/x-icon",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".svg":  "image/svg+xml",
+}
+
+// MediaCache 网站图标和预览图的本地缓存: 下载、按内容识别格式、缩小后保存
+// 文件按内容哈希命名，通过 /api/media/{kind}/{file} 访问
+type MediaCache struct {
+	repo      *db.MediaRepository
+	scraper   *ScraperService
+	dir       string
+	publicURL string // 生成缓存地址时的前缀，为空时使用相对路径
+}
+
+// NewMediaCache 创建媒体缓存，dir 为缓存目录
+func NewMediaCache(repo *db.MediaRepository, scraper *ScraperService, dir, publicURL string) *MediaCache {
+	for kind := range mediaLimits {
+		if err := os.MkdirAll(filepath.Join(dir, kind), 0755); err != nil {
+			log.Printf("⚠️ 创建媒体缓存目录失败: %s, 错误: %v", dir, err)
+		}
+	}
+	return &MediaCache{
+		repo:      repo,
+		scraper:   scraper,
+		dir:       dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// Cache 缓存图片并返回本地访问地址，同一地址只下载一次
+func (c *MediaCache) Cache(kind, sourceURL string) (string, error) {
+	limits, ok := mediaLimits[kind]
+	if !ok {
+		return "", fmt.Errorf("未知的媒体类型: %s", kind)
+	}
+
+	cached, err := c.repo.Get(kind, sourceURL)
+	if err == nil {
+		if _, statErr := os.Stat(filepath.Join(c.dir, kind, cached.File)); statErr == nil {
+			return c.URL(kind, cached.File), nil
+		}
+	} else if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	res, err := c.scraper.Fetch(sourceURL, limits.maxSize)
+	if err != nil {
+		return "", err
+	}
+	data, ext, err := processImage(res.Body, res.ContentType, kind == models.MediaKindFavicon, limits.maxDim)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	file := hex.EncodeToString(sum[:]) + ext
+	path := filepath.Join(c.dir, kind, file)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", fmt.Errorf("写入媒体缓存失败: %w", err)
+		}
+	}
+
+	if err := c.repo.Save(&models.MediaFile{Kind: kind, SourceURL: sourceURL, File: file, ContentType: mediaContentTypes[ext]}); err != nil {
+		return "", err
+	}
+	return c.URL(kind, file), nil
+}
+
+// URL 缓存文件的访问地址
+func (c *MediaCache) URL(kind, file string) string {
+	return c.publicURL + "/api/media/" + kind + "/" + file
+}
+
+// Open 打开缓存文件，返回文件和 Content-Type；文件名不合法或不存在时返回 os.ErrNotExist
+func (c *MediaCache) Open(kind, file string) (*os.File, string, error) {
+	if _, ok := mediaLimits[kind]; !ok || !mediaFilePattern.MatchString(file) {
+		return nil, "", os.ErrNotExist
+	}
+	f, err := os.Open(filepath.Join(c.dir, kind, file))
+	if err != nil {
+		return nil, "", err
+	}
+	return f, mediaContentTypes[filepath.Ext(file)], nil
+}
+
+// processImage 按内容识别图片格式，超过 maxDim 的位图缩小后重新编码
+// 返回保存的数据和扩展名；图标保存为 PNG 以保留透明度，预览图保存为 JPEG
+func processImage(data []byte, declaredType string, favicon bool, maxDim int) ([]byte, string, error) {
+	sniffed := http.DetectContentType(data)
+	switch sniffed {
+	case "image/png", "image/jpeg", "image/gif":
+	case "image/x-icon", "image/vnd.microsoft.icon":
+		return data, ".ico", nil
+	case "image/webp":
+		return data, ".webp", nil
+	case "image/bmp":
+		return data, ".bmp", nil
+	default:
+		// SVG 识别为文本，仅在服务器声明为 SVG 且内容确实是 SVG 时接受
+		mediaType, _, _ := mime.ParseMediaType(declaredType)
+		head := data
+		if len(head) > 1024 {
+			head = head[:1024]
+		}
+		if mediaType == "image/svg+xml" && bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+			return data, ".svg", nil
+		}
+		return nil, "", fmt.Errorf("不是支持的图片格式: %s", sniffed)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("解析图片失败: %w", err)
+	}
+	if cfg.Width*cfg.Height > mediaMaxPixels {
+		return nil, "", fmt.Errorf("图片尺寸过大: %dx%d", cfg.Width, cfg.Height)
+	}
+	if cfg.Width <= maxDim && cfg.Height <= maxDim {
+		ext := "." + format
+		if format == "jpeg" {
+			ext = ".jpg"
+		}
+		return data, ext, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("解码图片失败: %w", err)
+	}
+	resized := resizeImage(img, maxDim)
+
+	var buf bytes.Buffer
+	if favicon {
+		err = png.Encode(&buf, resized)
+		return buf.Bytes(), ".png", err
+	}
+	err = jpeg.Encode(&buf, flattenImage(resized), &jpeg.Options{Quality: 85})
+	return buf.Bytes(), ".jpg", err
+}
+
+// resizeImage 按比例缩小图片使最长边不超过 maxDim（区域平均采样）
+func resizeImage(src image.Image, maxDim int) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := maxDim, maxDim
+	if w > h {
+		dh = max(1, h*maxDim/w)
+	} else {
+		dw = max(1, w*maxDim/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := b.Min.Y+y*h/dh, b.Min.Y+max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := b.Min.X+x*w/dw, b.Min.X+max((x+1)*w/dw, x*w/dw+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					// 按透明度加权，避免透明像素的颜色渗入边缘
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			if a > 0 {
+				dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / a), G: uint8(g / a), B: uint8(bl / a), A: uint8(a / n)})
+			}
+		}
+	}
+	return dst
+}
+
+// flattenImage 将透明区域合成到白色背景上（JPEG 不支持透明度）
+func flattenImage(src *image.NRGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	for i := 0; i < len(src.Pix); i += 4 {
+		a := uint32(src.Pix[i+3])
+		for j := 0; j < 3; j++ {
+			dst.Pix[i+j] = uint8((uint32(src.Pix[i+j])*a + 255*(255-a)) / 255)
+		}
+		dst.Pix[i+3] = 255
+	}
+	return dst
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// testImage 生成指定尺寸的纯色图片
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 100, 50, 255
+	}
+	return img
+}
+
+// encodeTestImage 按格式编码图片
+func encodeTestImage(t *testing.T, format string, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader 只包含签名和 IHDR 块的 PNG，用于声明很大的尺寸而不生成像素数据
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], w)
+	binary.BigEndian.PutUint32(ihdr[8:], h)
+	ihdr[12], ihdr[13] = 8, 6 // 8 位 RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(13))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	smallPNG := encodeTestImage(t, "png", testImage(32, 16))
+	smallJPEG := encodeTestImage(t, "jpeg", testImage(32, 16))
+	smallGIF := encodeTestImage(t, "gif", testImage(32, 16))
+	largePNG := encodeTestImage(t, "png", testImage(200, 100))
+	ico := append([]byte{0, 0, 1, 0, 1, 0, 16, 16}, make([]byte, 64)...)
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="16" height="16"></svg>`)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		declaredType string
+		favicon      bool
+		maxDim       int
+		wantExt      string // 空字符串表示期望返回错误
+		wantSize     image.Point
+		wantSame     bool // 是否原样保存
+	}{
+		{"小 PNG 原样保存", smallPNG, "image/png", true, 64, ".png", image.Pt(32, 16), true},
+		{"小 JPEG 原样保存", smallJPEG, "image/jpeg", false, 800, ".jpg", image.Pt(32, 16), true},
+		{"小 GIF 原样保存", smallGIF, "image/gif", false, 800, ".gif", image.Pt(32, 16), true},
+		{"按内容识别而不是声明的类型", smallPNG, "image/jpeg", true, 64, ".png", image.Pt(32, 16), true},
+		{"缺少 Content-Type", smallJPEG, "", false, 800, ".jpg", image.Pt(32, 16), true},
+		{"大图标缩小为 PNG", largePNG, "image/png", true, 64, ".png", image.Pt(64, 32), false},
+		{"大预览图缩小为 JPEG", largePNG, "image/png", false, 50, ".jpg", image.Pt(50, 25), false},
+		{"ICO 原样保存", ico, "image/x-icon", true, 64, ".ico", image.Point{}, true},
+		{"声明为 SVG 的 SVG", svg, "image/svg+xml; charset=utf-8", true, 64, ".svg", image.Point{}, true},
+		{"未声明为 SVG 的 SVG", svg, "text/xml", true, 64, "", image.Point{}, false},
+		{"伪装成 SVG 的 HTML", []byte("<html><script>alert(1)</script></html>"), "image/svg+xml", true, 64, "", image.Point{}, false},
+		{"声明为 PNG 的 HTML", []byte("<!DOCTYPE html><html><body><svg></svg></body></html>"), "image/png", true, 64, "", image.Point{}, false},
+		{"纯文本", []byte("not an image"), "image/png", false, 800, "", image.Point{}, false},
+		{"损坏的 PNG", smallPNG[:20], "image/png", true, 64, "", image.Point{}, false},
+		{"超过像素上限", pngHeader(10000, 5000), "image/png", false, 800, "", image.Point{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, ext, err := processImage(tc.data, tc.declaredType, tc.favicon, tc.maxDim)
+			if tc.wantExt == "" {
+				if err == nil {
+					t.Fatalf("期望返回错误，得到 %s", ext)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ext != tc.wantExt {
+				t.Errorf("扩展名 = %q，期望 %q", ext, tc.wantExt)
+			}
+			if same := bytes.Equal(data, tc.data); same != tc.wantSame {
+				t.Errorf("原样保存 = %v，期望 %v", same, tc.wantSame)
+			}
+			if tc.wantSize != (image.Point{}) {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := image.Pt(cfg.Width, cfg.Height); got != tc.wantSize {
+					t.Errorf("尺寸 = %v，期望 %v", got, tc.wantSize)
+				}
+			}
+		})
+	}
+
+	// 像素上限在解码前检查，刚好不超过上限的尺寸进入解码阶段
+	if _, _, err := processImage(pngHeader(8000, 5000), "image/png", false, 800); err == nil || strings.Contains(err.Error(), "尺寸过大") {
+		t.Errorf("像素数等于上限时的错误 = %v，期望解码失败", err)
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		w, h, maxDim int
+		want         image.Point
+	}{
+		{200, 100, 64, image.Pt(64, 32)},
+		{100, 200, 64, image.Pt(32, 64)},
+		{100, 100, 10, image.Pt(10, 10)},
+		{1000, 1, 100, image.Pt(100, 1)},
+		{1, 1000, 100, image.Pt(1, 100)},
+	}
+	for _, tc := range tests {
+		if got := resizeImage(testImage(tc.w, tc.h), tc.maxDim).Bounds().Size(); got != tc.want {
+			t.Errorf("resizeImage(%dx%d, %d) = %v，期望 %v", tc.w, tc.h, tc.maxDim, got, tc.want)
+		}
+	}
+
+	// 透明像素的颜色不参与平均
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	src.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 0})
+	src.SetNRGBA(0, 1, color.NRGBA{G: 255, A: 0})
+	src.SetNRGBA(1, 1, color.NRGBA{G: 255, A: 0})
+	if got := resizeImage(src, 1).NRGBAAt(0, 0); got != (color.NRGBA{R: 255, A: 63}) {
+		t.Errorf("缩小后的颜色 = %v，期望 {255 0 0 63}", got)
+	}
+}
+
+func TestMediaCacheReusesSourceURL(t *testing.T) {
+	initTestDB(t)
+	var hits int32
+	body := encodeTestImage(t, "png", testImage(16, 16))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cache := NewMediaCache(db.NewMediaRepository(), newTestScraper(2), dir, "https://bookmarks.example/")
+
+	first, err := cache.Cache(models.MediaKindFavicon, srv.URL+"/favicon.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, "https://bookmarks.example/api/media/favicons/") || !strings.HasSuffix(first, ".png") {
+		t.Errorf("缓存地址 = %q", first)
+	}
+	second, err := cache.Cache(models.MediaKindFavicon, srv.URL+"/favicon.png")
+	if err != nil || second != first {
+		t.Errorf("第二次缓存 = %q, %v，期望 %q", second, err, first)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("图片被下载 %d 次，期望 1 次", n)
+	}
+
+	// 同一图片作为另一种媒体缓存时单独下载
+	if _, err := cache.Cache(models.MediaKindPreview, srv.URL+"/favicon.png"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("图片被下载 %d 次，期望 2 次", n)
+	}
+
+	// 缓存文件被删除后重新下载
+	file := first[strings.LastIndex(first, "/")+1:]
+	if err := os.Remove(filepath.Join(dir, models.MediaKindFavicon, file)); err != nil {
+		t.Fatal(err)
+	}
+	if third, err := cache.Cache(models.MediaKindFavicon, srv.URL+"/favicon.png"); err != nil || third != first {
+		t.Errorf("重新缓存 = %q, %v", third, err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("图片被下载 %d 次，期望 3 次", n)
+	}
+
+	if _, err := cache.Cache("avatars", srv.URL+"/favicon.png"); err == nil {
+		t.Error("未知的媒体类型应返回错误")
+	}
+}
+
+func TestMediaCacheOpen(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewMediaCache(nil, nil, dir, "")
+	file := strings.Repeat("ab", 32) + ".jpg"
+	if err := os.WriteFile(filepath.Join(dir, models.MediaKindPreview, file), []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, contentType, err := cache.Open(models.MediaKindPreview, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if contentType != "image/jpeg" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+
+	for _, tc := range []struct{ kind, file string }{
+		{models.MediaKindFavicon, file},
+		{"..", "secret.txt"},
+		{models.MediaKindPreview, "../secret.txt"},
+		{models.MediaKindPreview, "..%2fsecret.txt"},
+		{models.MediaKindPreview, strings.ToUpper(file[:64]) + ".jpg"},
+		{models.MediaKindPreview, file[:63] + ".jpg"},
+		{models.MediaKindPreview, strings.TrimSuffix(file, ".jpg") + ".html"},
+		{models.MediaKindPreview, file + "/"},
+		{"", file},
+	} {
+		if f, _, err := cache.Open(tc.kind, tc.file); !errors.Is(err, os.ErrNotExist) {
+			if f != nil {
+				f.Close()
+			}
+			t.Errorf("Open(%q, %q) = %v，期望 os.ErrNotExist", tc.kind, tc.file, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+)
+
+// linkding 在描述中嵌入笔记使用的标记
+const (
+	notesStartMarker = "[linkding-notes]"
+	notesEndMarker   = "[/linkding-notes]"
+)
+
+// ParseNetscapeBookmarks 流式解析 Netscape HTML 书签文件
+// 每解析出一个书签就调用一次 emit，emit 返回错误时停止解析
+func ParseNetscapeBookmarks(r io.Reader, emit func(*models.ImportItem) error) error {
+	z := html.NewTokenizer(r)
+
+	var (
+		folders       []string // <DL> 嵌套栈，每层对应一个 <H3> 文件夹名（根为空）
+		pendingFolder string   // 最近一个 <H3> 的名称，等待下一个 <DL> 入栈
+		pending       *models.ImportItem
+		inH3, inA     bool
+		inDD          bool
+		text          strings.Builder
+	)
+
+	currentFolder := func() string {
+		for i := len(folders) - 1; i >= 0; i-- {
+			if folders[i] != "" {
+				return folders[i]
+			}
+		}
+		return ""
+	}
+
+	// endDescription 结束 <DD> 描述的收集
+	endDescription := func() {
+		if inDD && pending != nil {
+			applyNetscapeDescription(&pending.Bookmark, text.String())
+		}
+		inDD = false
+	}
+
+	// flush 输出已解析完成的书签
+	flush := func() error {
+		endDescription()
+		if pending == nil {
+			return nil
+		}
+		item := pending
+		pending = nil
+		return emit(item)
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return flush()
+			}
+			return z.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+
+			switch tag {
+			case "dt", "dl", "h3", "a", "hr":
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+			switch tag {
+			case "h3":
+				inH3 = true
+				text.Reset()
+			case "dl":
+				folders = append(folders, pendingFolder)
+				pendingFolder = ""
+			case "a":
+				pending = &models.ImportItem{FolderName: currentFolder()}
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					applyNetscapeAttr(pending, string(key), string(val))
+				}
+				inA = true
+				text.Reset()
+			case "dd":
+				if pending != nil {
+					inDD = true
+					text.Reset()
+				}
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				if inH3 {
+					pendingFolder = strings.TrimSpace(text.String())
+					inH3 = false
+				}
+			case "a":
+				if inA && pending != nil {
+					pending.Bookmark.Title = strings.TrimSpace(text.String())
+				}
+				inA = false
+			case "dl":
+				if err := flush(); err != nil {
+					return err
+				}
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			}
+
+		case html.TextToken:
+			if inH3 || inA || inDD {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// applyNetscapeAttr 将 <A> 标签属性映射到导入项
+func applyNetscapeAttr(item *models.ImportItem, key, val string) {
+	bm := &item.Bookmark
+	switch key {
+	case "href":
+		bm.URL = strings.TrimSpace(val)
+	case "add_date":
+		if ts, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil && ts > 0 {
+			item.DateAdded = parseNetscapeTimestamp(ts)
+		}
+	case "tags":
+		seen := make(map[string]bool)
+		for _, t := range strings.Split(val, ",") {
+			t = strings.TrimSpace(t)
+			if t != "" && !seen[t] {
+				seen[t] = true
+				bm.TagNames = append(bm.TagNames, t)
+			}
+		}
+	case "private":
+		// linkding: PRIVATE="0" 表示公开分享
+		bm.Shared = strings.TrimSpace(val) == "0"
+	case "toread":
+		bm.Unread = strings.TrimSpace(val) == "1"
+	case "favorite":
+		bm.IsFavorite = strings.TrimSpace(val) == "1"
+	}
+}
+
+// applyNetscapeDescription 解析 <DD> 内容，拆分出 linkding 风格的笔记
+func applyNetscapeDescription(bm *models.BookmarkCreate, desc string) {
+	desc = strings.TrimSpace(desc)
+	if start := strings.Index(desc, notesStartMarker); start >= 0 {
+		rest := desc[start+len(notesStartMarker):]
+		if end := strings.Index(rest, notesEndMarker); end >= 0 {
+			bm.Notes = strings.TrimSpace(rest[:end])
+			desc = desc[:start] + rest[end+len(notesEndMarker):]
+		}
+	}
+	bm.Description = strings.TrimSpace(desc)
+}
+
+// parseNetscapeTimestamp 解析 ADD_DATE，兼容秒、毫秒和微秒
+func parseNetscapeTimestamp(ts int64) time.Time {
+	switch {
+	case ts > 1e15:
+		return time.UnixMicro(ts).UTC()
+	case ts > 1e12:
+		return time.UnixMilli(ts).UTC()
+	default:
+		return time.Unix(ts, 0).UTC()
+	}
+}
+
+// netscapeHeader Netscape 书签文件头
+const netscapeHeader = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+`
+
+// writeNetscapeFolderStart 写入 <H3> 文件夹标题并开启嵌套列表
+func writeNetscapeFolderStart(w io.Writer, folder *models.Folder, indent string) error {
+	_, err := fmt.Fprintf(w, "%s<DT><H3 ADD_DATE=\"%d\">%s</H3>\n%s<DL><p>\n",
+		indent, folder.DateAdded.Unix(), html.EscapeString(folder.Name), indent)
+	return err
+}
+
+// writeNetscapeBookmark 写入单个书签（与 linkding 导出格式兼容）
+func writeNetscapeBookmark(w io.Writer, bm *models.Bookmark, indent string) error {
+	private := "1"
+	if bm.Shared {
+		private = "0"
+	}
+	toread := "0"
+	if bm.Unread {
+		toread = "1"
+	}
+	favorite := "0"
+	if bm.IsFavorite {
+		favorite = "1"
+	}
+
+	_, err := fmt.Fprintf(w,
+		"%s<DT><A HREF=\"%s\" ADD_DATE=\"%d\" LAST_MODIFIED=\"%d\" PRIVATE=\"%s\" TOREAD=\"%s\" FAVORITE=\"%s\" TAGS=\"%s\">%s</A>\n",
+		indent, html.EscapeString(bm.URL), bm.DateAdded.Unix(), bm.DateModified.Unix(),
+		private, toread, favorite, html.EscapeString(strings.Join(bm.TagNames, ",")),
+		html.EscapeString(bm.Title),
+	)
+	if err != nil {
+		return err
+	}
+
+	desc := bm.Description
+	if bm.Notes != "" {
+		desc += notesStartMarker + bm.Notes + notesEndMarker
+	}
+	if desc != "" {
+		_, err = fmt.Fprintf(w, "%s<DD>%s\n", indent, html.EscapeString(desc))
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"log"
+	"sync"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// PageArchiver 后台为书签生成本地网页快照，保存为 snapshot 类型的附件
+type PageArchiver struct {
+	bookmarkRepo *db.BookmarkRepository
+	assetService *AssetService
+	scraper      *ScraperService
+	queue        chan *models.BookmarkAsset
+	workerCount  int
+	wg           sync.WaitGroup
+	started      bool
+}
+
+// NewPageArchiver 创建网页快照服务
+func NewPageArchiver(bookmarkRepo *db.BookmarkRepository, assetService *AssetService, scraper *ScraperService, workerCount int) *PageArchiver {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	return &PageArchiver{
+		bookmarkRepo: bookmarkRepo,
+		assetService: assetService,
+		scraper:      scraper,
+		queue:        make(chan *models.BookmarkAsset, 1000),
+		workerCount:  workerCount,
+	}
+}
+
+// Start 启动后台 worker
+func (a *PageArchiver) Start() {
+	if a.started {
+		return
+	}
+	a.started = true
+	for i := 0; i < a.workerCount; i++ {
+		a.wg.Add(1)
+		go a.worker()
+	}
+	log.Printf("📸 网页快照服务启动: %d workers", a.workerCount)
+}
+
+// Stop 停止后台 worker，等待进行中的快照完成
+func (a *PageArchiver) Stop() {
+	if !a.started {
+		return
+	}
+	close(a.queue)
+	a.wg.Wait()
+}
+
+// Submit 为书签创建待生成的快照附件并入队，返回该附件；服务未启动时返回 nil
+func (a *PageArchiver) Submit(bookmarkID int) (*models.BookmarkAsset, error) {
+	if !a.started {
+		return nil, nil
+	}
+
+	asset, err := a.assetService.CreateSnapshot(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case a.queue <- asset:
+	default:
+		log.Printf("⚠️ 网页快照队列已满，忽略书签 ID: %d", bookmarkID)
+		a.assetService.FailSnapshot(asset)
+	}
+	return asset, nil
+}
+
+func (a *PageArchiver) worker() {
+	defer a.wg.Done()
+	for asset := range a.queue {
+		if err := a.archive(asset); err != nil {
+			log.Printf("⚠️ 生成网页快照失败 ID=%d: %v", asset.BookmarkID, err)
+			a.assetService.FailSnapshot(asset)
+		}
+	}
+}
+
+// archive 抓取书签页面并保存快照
+func (a *PageArchiver) archive(asset *models.BookmarkAsset) error {
+	bm, err := a.bookmarkRepo.GetActiveByID(asset.BookmarkID)
+	if err != nil {
+		return err
+	}
+
+	content, err := a.scraper.BuildSnapshot(bm.URL)
+	if err != nil {
+		return err
+	}
+	return a.assetService.CompleteSnapshot(asset, content)
+}
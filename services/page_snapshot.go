@@ -0,0 +1,395 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// snapshotMaxPageSize 快照页面本身的大小上限
+	snapshotMaxPageSize = 10 << 20
+	// snapshotMaxResourceSize 单个内联资源（样式表、图片、字体）的大小上限
+	snapshotMaxResourceSize = 2 << 20
+	// snapshotMaxTotalSize 单个快照内联资源的总大小上限，超出后的资源保留原地址
+	snapshotMaxTotalSize = 30 << 20
+	// snapshotMaxImportDepth 样式表 @import 的最大嵌套层数
+	snapshotMaxImportDepth = 3
+)
+
+var (
+	// cssURLPattern 匹配 CSS 中的 url(...)
+	cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"]*))\s*\)`)
+	// cssImportPattern 匹配 CSS 中的 @import "..." / @import url(...)
+	cssImportPattern = regexp.MustCompile(`@import\s+(?:url\(\s*)?["']?([^"')\s;]+)["']?\s*\)?([^;]*);`)
+)
+
+// snapshotRemovedTags 快照中移除的元素: 脚本和需要外部上下文的嵌入内容
+var snapshotRemovedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Base:     true,
+	atom.Template: true,
+}
+
+// snapshotRemovedLinkRels 快照中移除的 <link rel>，这些资源离线时没有意义
+var snapshotRemovedLinkRels = map[string]bool{
+	"preload":       true,
+	"prefetch":      true,
+	"modulepreload": true,
+	"preconnect":    true,
+	"dns-prefetch":  true,
+	"prerender":     true,
+	"manifest":      true,
+	"serviceworker": true,
+}
+
+// snapshotBuilder 生成自包含的网页快照: 内联样式表和图片，移除脚本
+type snapshotBuilder struct {
+	scraper   *ScraperService
+	cache     map[string]string // 资源地址 -> data URI，失败时为空字符串
+	totalSize int
+}
+
+// BuildSnapshot 抓取网页并生成自包含的 HTML 快照
+func (s *ScraperService) BuildSnapshot(url string) ([]byte, error) {
+	page, err := s.Fetch(url, snapshotMaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(page.ContentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("不支持的页面类型: %s", page.ContentType)
+	}
+
+	body, _, err := decodeHTML(page.Body, page.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("HTML解析失败: %w", err)
+	}
+
+	// 页面通过 <base href> 指定相对地址的基准时以它为准，<base> 本身在快照中移除
+	base := page.URL
+	if href := findBaseHref(doc); href != "" {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	b := &snapshotBuilder{scraper: s, cache: make(map[string]string)}
+	b.process(doc, base)
+
+	if head := findFirst(doc, atom.Head); head != nil {
+		head.InsertBefore(&html.Node{
+			Type:     html.ElementNode,
+			Data:     "meta",
+			DataAtom: atom.Meta,
+			Attr:     []html.Attribute{{Key: "charset", Val: "utf-8"}},
+		}, head.FirstChild)
+	}
+
+	// 在文档开头注明来源和保存时间
+	comment := &html.Node{
+		Type: html.CommentNode,
+		Data: fmt.Sprintf(" LinkGenie snapshot of %s saved at %s ", page.URL, time.Now().UTC().Format(time.RFC3339)),
+	}
+	doc.InsertBefore(comment, doc.FirstChild)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, fmt.Errorf("生成快照失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// process 递归处理节点，返回 false 表示该节点应被移除
+func (b *snapshotBuilder) process(n *html.Node, base *neturl.URL) bool {
+	if n.Type == html.ElementNode {
+		if snapshotRemovedTags[n.DataAtom] {
+			return false
+		}
+		if !b.processElement(n, base) {
+			return false
+		}
+	}
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if !b.process(c, base) {
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+	return true
+}
+
+// processElement 处理单个元素的属性和资源，返回 false 表示该元素应被移除
+func (b *snapshotBuilder) processElement(n *html.Node, base *neturl.URL) bool {
+	// 移除事件处理属性和 javascript: 链接
+	attrs := n.Attr[:0]
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		if strings.HasPrefix(key, "on") {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Val)), "javascript:") {
+			continue
+		}
+		if key == "style" {
+			attr.Val = b.inlineCSS(attr.Val, base, 0)
+		}
+		attrs = append(attrs, attr)
+	}
+	n.Attr = attrs
+
+	switch n.DataAtom {
+	case atom.Meta:
+		equiv := strings.ToLower(getAttr(n, "http-equiv"))
+		if equiv == "refresh" || equiv == "content-security-policy" {
+			return false
+		}
+		// 快照统一保存为 UTF-8，原有的编码声明移除后在 <head> 开头重新声明
+		if equiv == "content-type" || getAttr(n, "charset") != "" {
+			return false
+		}
+	case atom.Link:
+		return b.processLink(n, base)
+	case atom.Style:
+		if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+			n.FirstChild.Data = b.inlineCSS(n.FirstChild.Data, base, 0)
+		}
+	case atom.Img:
+		src := getAttr(n, "src")
+		// 懒加载图片的真实地址通常放在 data-src / data-original 中
+		for _, key := range []string{"data-src", "data-original", "data-lazy-src"} {
+			if lazy := getAttr(n, key); lazy != "" && (src == "" || strings.HasPrefix(src, "data:")) {
+				src = lazy
+				break
+			}
+		}
+		if src != "" {
+			setAttr(n, "src", b.inlineResource(src, base))
+		}
+		removeAttrs(n, "srcset", "sizes", "loading", "data-src", "data-original", "data-lazy-src")
+	case atom.Source:
+		// <picture> 中的 <source srcset> 无法离线使用，交给同级的 <img>
+		if n.Parent != nil && n.Parent.DataAtom == atom.Picture {
+			return false
+		}
+		absolutizeAttr(n, base, "src")
+	case atom.Input:
+		if strings.EqualFold(getAttr(n, "type"), "image") {
+			setAttr(n, "src", b.inlineResource(getAttr(n, "src"), base))
+		}
+	case atom.A, atom.Area:
+		absolutizeAttr(n, base, "href")
+	case atom.Form:
+		absolutizeAttr(n, base, "action")
+	case atom.Video, atom.Audio, atom.Track:
+		absolutizeAttr(n, base, "src")
+		absolutizeAttr(n, base, "poster")
+	}
+	return true
+}
+
+// processLink 内联样式表和图标，移除预加载类的 <link>
+func (b *snapshotBuilder) processLink(n *html.Node, base *neturl.URL) bool {
+	rels := strings.Fields(strings.ToLower(getAttr(n, "rel")))
+	href := getAttr(n, "href")
+	for _, rel := range rels {
+		if snapshotRemovedLinkRels[rel] {
+			return false
+		}
+	}
+
+	for _, rel := range rels {
+		switch rel {
+		case "stylesheet":
+			css, cssURL, ok := b.fetchCSS(href, base)
+			if !ok {
+				absolutizeAttr(n, base, "href")
+				return true
+			}
+			// 将 <link> 替换为同样 media 的 <style>
+			style := &html.Node{Type: html.ElementNode, Data: "style", DataAtom: atom.Style}
+			if media := getAttr(n, "media"); media != "" {
+				style.Attr = []html.Attribute{{Key: "media", Val: media}}
+			}
+			style.AppendChild(&html.Node{Type: html.TextNode, Data: b.inlineCSS(css, cssURL, 0)})
+			n.Parent.InsertBefore(style, n)
+			return false
+		case "icon", "apple-touch-icon":
+			setAttr(n, "href", b.inlineResource(href, base))
+			return true
+		}
+	}
+
+	absolutizeAttr(n, base, "href")
+	return true
+}
+
+// fetchCSS 抓取样式表，返回内容和样式表地址（用于解析其中的相对地址）
+func (b *snapshotBuilder) fetchCSS(href string, base *neturl.URL) (string, *neturl.URL, bool) {
+	abs := resolveURL(base, href)
+	if abs == "" || !b.reserve(0) {
+		return "", nil, false
+	}
+	res, err := b.scraper.Fetch(abs, snapshotMaxResourceSize)
+	if err != nil || !b.reserve(len(res.Body)) {
+		return "", nil, false
+	}
+	return string(res.Body), res.URL, true
+}
+
+// inlineCSS 内联 CSS 中的 @import 和 url(...) 资源
+func (b *snapshotBuilder) inlineCSS(css string, base *neturl.URL, depth int) string {
+	if depth < snapshotMaxImportDepth {
+		css = cssImportPattern.ReplaceAllStringFunc(css, func(m string) string {
+			sub := cssImportPattern.FindStringSubmatch(m)
+			imported, importURL, ok := b.fetchCSS(sub[1], base)
+			if !ok {
+				return m
+			}
+			imported = b.inlineCSS(imported, importURL, depth+1)
+			if media := strings.TrimSpace(sub[2]); media != "" {
+				return "@media " + media + " {\n" + imported + "\n}"
+			}
+			return imported
+		})
+	}
+
+	return cssURLPattern.ReplaceAllStringFunc(css, func(m string) string {
+		sub := cssURLPattern.FindStringSubmatch(m)
+		ref := sub[1] + sub[2] + sub[3]
+		if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return m
+		}
+		return `url("` + b.inlineResource(ref, base) + `")`
+	})
+}
+
+// inlineResource 将资源转换为 data URI，失败或超出总大小时返回绝对地址
+func (b *snapshotBuilder) inlineResource(ref string, base *neturl.URL) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ref
+	}
+	abs := resolveURL(base, ref)
+	if abs == "" {
+		return ref
+	}
+
+	if dataURI, ok := b.cache[abs]; ok {
+		if dataURI == "" {
+			return abs
+		}
+		return dataURI
+	}
+
+	b.cache[abs] = ""
+	if !b.reserve(0) {
+		return abs
+	}
+	res, err := b.scraper.Fetch(abs, snapshotMaxResourceSize)
+	if err != nil || !b.reserve(len(res.Body)) {
+		return abs
+	}
+
+	contentType, _, _ := mime.ParseMediaType(res.ContentType)
+	if contentType == "" || contentType == "application/octet-stream" || contentType == "text/plain" {
+		contentType = http.DetectContentType(res.Body)
+	}
+	dataURI := "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(res.Body)
+	b.cache[abs] = dataURI
+	return dataURI
+}
+
+// reserve 记录已内联的资源大小，超出总大小上限时返回 false
+func (b *snapshotBuilder) reserve(size int) bool {
+	if b.totalSize+size > snapshotMaxTotalSize {
+		return false
+	}
+	b.totalSize += size
+	return true
+}
+
+// findBaseHref 查找文档中第一个 <base href>
+func findBaseHref(n *html.Node) string {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Base {
+		if href := getAttr(n, "href"); href != "" {
+			return href
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if href := findBaseHref(c); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+// getAttr 获取元素属性值
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// setAttr 设置元素属性值，属性不存在时追加
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// removeAttrs 删除元素属性
+func removeAttrs(n *html.Node, keys ...string) {
+	attrs := n.Attr[:0]
+	for _, attr := range n.Attr {
+		remove := false
+		for _, key := range keys {
+			if attr.Key == key {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			attrs = append(attrs, attr)
+		}
+	}
+	n.Attr = attrs
+}
+
+// absolutizeAttr 将属性中的相对地址改为绝对地址，使快照中的链接离线后仍指向原站
+func absolutizeAttr(n *html.Node, base *neturl.URL, key string) {
+	val := getAttr(n, key)
+	if val == "" || strings.HasPrefix(val, "#") || strings.HasPrefix(val, "data:") {
+		return
+	}
+	if u, err := base.Parse(strings.TrimSpace(val)); err == nil {
+		setAttr(n, key, u.String())
+	}
+}
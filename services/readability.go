@@ -0,0 +1,704 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"mime"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 阅读模式正文提取，打分规则参考 Arc90 Readability:
+// 以段落为单位按文字长度和逗号数打分并累加到祖先元素，得分最高（链接密度修正后）的元素即正文容器
+
+var (
+	// readabilityUnlikely class/id 命中时通常不是正文（同时命中 readabilityMaybe 的除外）
+	readabilityUnlikely = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|gdpr|header|legends|menu|newsletter|pager|pagination|popup|recommend|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental`)
+	readabilityMaybe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	// readabilityPositive / readabilityNegative class/id 的加减分
+	readabilityPositive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story|rich_media`)
+	readabilityNegative = regexp.MustCompile(`(?i)-ad-|hidden|banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	// readabilityBylineClass 作者信息所在元素的 class/id
+	readabilityBylineClass = regexp.MustCompile(`(?i)byline|author|writtenby`)
+)
+
+// readabilityRemovedTags 提取正文前移除的元素
+var readabilityRemovedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Canvas: true, atom.Svg: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Link: true, atom.Meta: true,
+}
+
+// readabilityBlockTags 块级元素，只包含行内内容的 div 按段落处理
+var readabilityBlockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Div: true, atom.Dl: true,
+	atom.Figure: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Table: true, atom.Ul: true,
+}
+
+// maxArticleRunes 保存的正文最大字符数
+const maxArticleRunes = 500_000
+
+// articleMaxPageSize 提取正文时页面的最大字节数
+const articleMaxPageSize = 5 << 20
+
+// ScrapeArticle 抓取网页并以阅读模式提取正文
+func (s *ScraperService) ScrapeArticle(url string) (*models.Article, error) {
+	page, err := s.Fetch(url, articleMaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(page.ContentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("不支持的页面类型: %s", page.ContentType)
+	}
+
+	body, _, err := decodeHTML(page.Body, page.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("HTML解析失败: %w", err)
+	}
+
+	base := page.URL
+	if href := findBaseHref(doc); href != "" {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+	return extractArticle(doc, base), nil
+}
+
+// extractArticle 从解析后的网页中提取正文，返回的 Article 不含 BookmarkID
+func extractArticle(doc *html.Node, base *neturl.URL) *models.Article {
+	article := &models.Article{}
+	meta := collectArticleMeta(doc)
+	article.Title = firstNonEmpty(meta["og:title"], meta["title"])
+	article.Byline = firstNonEmpty(meta["author"], meta["article:author"])
+	article.Excerpt = firstNonEmpty(meta["og:description"], meta["description"])
+
+	body := findFirst(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+	if article.Byline == "" {
+		article.Byline = findByline(body)
+	}
+	prepareArticle(body)
+
+	content := pickArticleContent(body)
+	md := &articleRenderer{base: base}
+	txt := &articleRenderer{base: base, plain: true}
+	var markdown, text strings.Builder
+	for _, n := range content {
+		md.render(n, &markdown)
+		markdown.WriteString("\n\n")
+		txt.render(n, &text)
+		text.WriteString("\n\n")
+	}
+	article.Markdown = truncateRunes(normalizeBlocks(markdown.String()), maxArticleRunes)
+	article.Text = truncateRunes(normalizeBlocks(text.String()), maxArticleRunes)
+	article.WordCount = countWords(article.Text)
+
+	if article.Excerpt == "" {
+		for _, para := range strings.Split(article.Text, "\n\n") {
+			if utf8.RuneCountInString(para) >= 40 {
+				article.Excerpt = truncateRunes(para, 200)
+				break
+			}
+		}
+	}
+	return article
+}
+
+// collectArticleMeta 收集 <title> 和 <meta name/property> 的值（取第一个非空值）
+func collectArticleMeta(doc *html.Node) map[string]string {
+	meta := map[string]string{}
+	walkElements(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if meta["title"] == "" {
+				meta["title"] = strings.TrimSpace(textContent(n))
+			}
+		case atom.Meta:
+			key := strings.ToLower(firstNonEmpty(getAttr(n, "property"), getAttr(n, "name")))
+			if content := strings.TrimSpace(getAttr(n, "content")); key != "" && content != "" && meta[key] == "" {
+				meta[key] = content
+			}
+		case atom.Body:
+			return false
+		}
+		return true
+	})
+	// article:author 经常是作者主页地址，不作为作者名
+	if strings.HasPrefix(meta["article:author"], "http") {
+		delete(meta, "article:author")
+	}
+	return meta
+}
+
+// findByline 查找 rel=author、itemprop=author 或 class 含 byline/author 的短文本作为作者
+func findByline(body *html.Node) string {
+	byline := ""
+	walkElements(body, func(n *html.Node) bool {
+		if byline != "" {
+			return false
+		}
+		if getAttr(n, "rel") == "author" || getAttr(n, "itemprop") == "author" ||
+			readabilityBylineClass.MatchString(getAttr(n, "class")+" "+getAttr(n, "id")) {
+			text := collapseSpaces(textContent(n))
+			if text != "" && utf8.RuneCountInString(text) < 100 {
+				byline = text
+				return false
+			}
+		}
+		return true
+	})
+	return byline
+}
+
+// prepareArticle 移除脚本、导航、隐藏元素和不太可能是正文的元素
+func prepareArticle(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode {
+			n.RemoveChild(c)
+		} else if c.Type == html.ElementNode {
+			if shouldRemoveFromArticle(c) {
+				n.RemoveChild(c)
+			} else {
+				prepareArticle(c)
+			}
+		}
+		c = next
+	}
+}
+
+// shouldRemoveFromArticle 判断元素是否应在提取正文前移除
+func shouldRemoveFromArticle(n *html.Node) bool {
+	if readabilityRemovedTags[n.DataAtom] {
+		return true
+	}
+	if _, hidden := findAttr(n, "hidden"); hidden || getAttr(n, "aria-hidden") == "true" {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(getAttr(n, "style")), " ", "")
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	if n.DataAtom == atom.Header && !hasAncestor(n, atom.Article) {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.A, atom.Table, atom.Tbody, atom.Tr, atom.Td, atom.Pre, atom.Code:
+		return false
+	}
+	classID := getAttr(n, "class") + " " + getAttr(n, "id")
+	return readabilityUnlikely.MatchString(classID) && !readabilityMaybe.MatchString(classID)
+}
+
+// pickArticleContent 选出正文容器及与其同级、得分足够高的兄弟元素
+func pickArticleContent(body *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	walkElements(body, func(n *html.Node) bool {
+		if !isParagraphLike(n) {
+			return true
+		}
+		text := collapseSpaces(textContent(n))
+		length := utf8.RuneCountInString(text)
+		if length < 25 {
+			return true
+		}
+
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "、"))
+		score += math.Min(float64(length)/100, 3)
+
+		ancestor := n.Parent
+		for level := 0; level < 3 && ancestor != nil && ancestor.Type == html.ElementNode; level++ {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+			}
+			divider := 1.0
+			if level == 1 {
+				divider = 2
+			} else if level > 1 {
+				divider = float64(level * 3)
+			}
+			scores[ancestor] += score / divider
+			ancestor = ancestor.Parent
+		}
+		return true
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for n, score := range scores {
+		score *= 1 - linkDensity(n)
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+	if top == nil || top.Parent == nil {
+		return []*html.Node{body}
+	}
+
+	threshold := math.Max(10, topScore*0.2)
+	content := []*html.Node{}
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type != html.ElementNode {
+			continue
+		}
+		include := s == top
+		if !include {
+			bonus := 0.0
+			if class := getAttr(top, "class"); class != "" && getAttr(s, "class") == class {
+				bonus = topScore * 0.2
+			}
+			if score, ok := scores[s]; ok && score+bonus >= threshold {
+				include = true
+			} else if s.DataAtom == atom.P {
+				text := collapseSpaces(textContent(s))
+				length := utf8.RuneCountInString(text)
+				density := linkDensity(s)
+				include = (length > 80 && density < 0.25) ||
+					(length > 0 && length <= 80 && density == 0 && strings.ContainsAny(text, ".。"))
+			}
+		}
+		if include {
+			content = append(content, s)
+		}
+	}
+	return content
+}
+
+// isParagraphLike 段落类元素: p、pre、td，以及不含块级子元素的 div/section
+func isParagraphLike(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td:
+		return true
+	case atom.Div, atom.Section:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && readabilityBlockTags[c.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore 候选元素的初始分: 按标签和 class/id 加减分
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, v := range []string{getAttr(n, "class"), getAttr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if readabilityNegative.MatchString(v) {
+			score -= 25
+		}
+		if readabilityPositive.MatchString(v) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity 元素中链接文字占全部文字的比例
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(collapseSpaces(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walkElements(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += utf8.RuneCountInString(collapseSpaces(textContent(c)))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// articleRenderer 将正文节点转换为 Markdown 或纯文本
+type articleRenderer struct {
+	base  *neturl.URL
+	plain bool // 纯文本: 不输出标记、链接和图片
+}
+
+// render 输出节点内容，块级元素前后用空行分隔
+func (r *articleRenderer) render(n *html.Node, b *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		writeInlineText(b, collapseWhitespace(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		r.renderChildren(n, b)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.TrimSpace(r.inline(n))
+		if text == "" {
+			return
+		}
+		b.WriteString("\n\n")
+		if !r.plain {
+			level, _ := strconv.Atoi(n.Data[1:])
+			b.WriteString(strings.Repeat("#", level) + " ")
+		}
+		b.WriteString(text + "\n\n")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Figure, atom.Figcaption, atom.Dl, atom.Dd, atom.Dt, atom.Address:
+		b.WriteString("\n\n")
+		r.renderChildren(n, b)
+		b.WriteString("\n\n")
+	case atom.Br:
+		b.WriteString("\n")
+	case atom.Hr:
+		if !r.plain {
+			b.WriteString("\n\n---\n\n")
+		}
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		if r.plain {
+			b.WriteString("\n\n" + code + "\n\n")
+		} else {
+			b.WriteString("\n\n```\n" + code + "\n```\n\n")
+		}
+	case atom.Blockquote:
+		inner := normalizeBlocks(r.renderToString(n))
+		if inner == "" {
+			return
+		}
+		if !r.plain {
+			inner = prefixLines(inner, "> ", "> ")
+		}
+		b.WriteString("\n\n" + inner + "\n\n")
+	case atom.Ul, atom.Ol:
+		r.renderList(n, b)
+	case atom.Table:
+		r.renderTable(n, b)
+	case atom.A:
+		text := strings.TrimSpace(r.inline(n))
+		href := resolveURL(r.base, getAttr(n, "href"))
+		if r.plain || text == "" || href == "" {
+			writeInlineText(b, text)
+			return
+		}
+		b.WriteString("[" + text + "](" + href + ")")
+	case atom.Img:
+		if r.plain {
+			return
+		}
+		src := resolveURL(r.base, firstNonEmpty(getAttr(n, "data-src"), getAttr(n, "data-original"), getAttr(n, "src")))
+		if src != "" {
+			b.WriteString("![" + collapseSpaces(getAttr(n, "alt")) + "](" + src + ")")
+		}
+	case atom.Strong, atom.B:
+		r.wrapInline(n, b, "**")
+	case atom.Em, atom.I:
+		r.wrapInline(n, b, "*")
+	case atom.Del, atom.S, atom.Strike:
+		r.wrapInline(n, b, "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		r.wrapInline(n, b, "`")
+	default:
+		r.renderChildren(n, b)
+	}
+}
+
+func (r *articleRenderer) renderChildren(n *html.Node, b *strings.Builder) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c, b)
+	}
+}
+
+// renderToString 将子节点输出为独立的字符串
+func (r *articleRenderer) renderToString(n *html.Node) string {
+	var b strings.Builder
+	r.renderChildren(n, &b)
+	return b.String()
+}
+
+// inline 输出行内内容，换行折叠为空格
+func (r *articleRenderer) inline(n *html.Node) string {
+	return collapseSpaces(r.renderToString(n))
+}
+
+// wrapInline 用标记包裹行内内容，内容为空时不输出标记
+func (r *articleRenderer) wrapInline(n *html.Node, b *strings.Builder, mark string) {
+	text := strings.TrimSpace(r.inline(n))
+	if text == "" {
+		return
+	}
+	if r.plain {
+		writeInlineText(b, text)
+		return
+	}
+	writeInlineText(b, mark+text+mark)
+}
+
+// renderList 输出列表，列表项中的后续行按标记宽度缩进
+func (r *articleRenderer) renderList(n *html.Node, b *strings.Builder) {
+	b.WriteString("\n\n")
+	index := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		item := normalizeBlocks(r.renderToString(li))
+		if item == "" {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		b.WriteString(prefixLines(item, marker, strings.Repeat(" ", len(marker))) + "\n")
+	}
+	b.WriteString("\n")
+}
+
+// renderTable 输出表格，第一行作为表头
+func (r *articleRenderer) renderTable(n *html.Node, b *strings.Builder) {
+	rows := [][]string{}
+	walkElements(n, func(c *html.Node) bool {
+		if c.DataAtom != atom.Tr {
+			return c.DataAtom != atom.Table || c == n
+		}
+		cells := []string{}
+		for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				text := strings.TrimSpace(r.inline(cell))
+				if !r.plain {
+					text = strings.ReplaceAll(text, "|", "\\|")
+				}
+				cells = append(cells, text)
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("\n\n")
+	for i, row := range rows {
+		if r.plain {
+			b.WriteString(strings.Join(row, "\t") + "\n")
+			continue
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeInlineText 写入行内文字，行首不写入空白
+func writeInlineText(b *strings.Builder, text string) {
+	s := b.String()
+	if s == "" || strings.HasSuffix(s, "\n") {
+		text = strings.TrimLeft(text, " ")
+	}
+	b.WriteString(text)
+}
+
+// normalizeBlocks 去除行尾空白并将连续空行合并为一个
+func normalizeBlocks(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	inCode := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if !inCode {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" && !inCode {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
+
+// prefixLines 为第一行加 first 前缀、其余非空行加 rest 前缀
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line != "":
+			lines[i] = rest + line
+		case strings.TrimSpace(rest) != "":
+			lines[i] = strings.TrimRight(rest, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// countWords 统计字数: 中日韩文字每字计一，其他文字按连续字母数字计一个词
+func countWords(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			count++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				count++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
+
+// textContent 元素内的全部文字
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// collapseWhitespace 将连续空白合并为一个空格（保留首尾空格的存在）
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// collapseSpaces 合并空白并去除首尾空白
+func collapseSpaces(s string) string {
+	return strings.TrimSpace(collapseWhitespace(s))
+}
+
+// walkElements 深度优先遍历元素，fn 返回 false 时不进入该元素的子节点
+func walkElements(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if !fn(c) {
+				continue
+			}
+		}
+		walkElements(c, fn)
+	}
+}
+
+// findFirst 查找第一个指定标签的元素
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walkElements(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// findAttr 查找元素属性，返回值和是否存在
+func findAttr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasAncestor 元素是否位于指定标签内
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncateRunes 按字符数截断
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
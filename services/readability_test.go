@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractArticleTestPages(t *testing.T) {
+	tests := []struct {
+		file        string
+		wantTitle   string
+		wantByline  string
+		wantExcerpt string
+		wantText    []string // 纯文本中应包含的内容
+		noise       []string // 导航、侧边栏、评论等不应出现在正文中的内容
+	}{
+		{
+			file:        "blog_post",
+			wantTitle:   "Go 并发模式实践 - 示例博客",
+			wantByline:  "作者：王小明",
+			wantExcerpt: "Go 语言的并发模型建立在 goroutine 和 channel 之上，写法简洁，但在真实项目中，如何组织它们往往比语法本身更重要。",
+			wantText:    []string{"- 工作池：限制同时运行的 goroutine 数量", "模式\t耗时", "工作池 | 8\t180ms", "完整示例代码见 示例仓库，"},
+			noise:       []string{"侧边栏热门文章", "归档", "订阅 RSS", "评论区：", "分享到微博", "版权所有", "window.analytics", "font-family"},
+		},
+		{
+			file:        "news_meta",
+			wantTitle:   "City Opens New Public Library",
+			wantByline:  "Jane Doe",
+			wantExcerpt: "The new central library opens on Monday with extended hours.",
+			wantText:    []string{"The city's new central library", "Opening hours will be extended"},
+			noise:       []string{"Related story", "Advertisement", "Hidden tracking", "Home", "Page title"},
+		},
+	}
+
+	base, _ := neturl.Parse("https://blog.example.com/posts/go")
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			doc, err := html.Parse(bytes.NewReader(loadTestPage(t, filepath.Join("readability", tc.file+".html"))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			article := extractArticle(doc, base)
+
+			if article.Title != tc.wantTitle {
+				t.Errorf("Title = %q，期望 %q", article.Title, tc.wantTitle)
+			}
+			if article.Byline != tc.wantByline {
+				t.Errorf("Byline = %q，期望 %q", article.Byline, tc.wantByline)
+			}
+			if article.Excerpt != tc.wantExcerpt {
+				t.Errorf("Excerpt = %q，期望 %q", article.Excerpt, tc.wantExcerpt)
+			}
+			// Markdown 与保存的期望结果逐字比较，覆盖列表、表格、代码块、引用、链接和图片
+			wantMarkdown := strings.TrimSuffix(string(loadTestPage(t, filepath.Join("readability", tc.file+".md"))), "\n")
+			if article.Markdown != wantMarkdown {
+				t.Errorf("Markdown 与 %s.md 不一致:\n%s", tc.file, article.Markdown)
+			}
+			for _, want := range tc.wantText {
+				if !strings.Contains(article.Text, want) {
+					t.Errorf("Text 不包含 %q:\n%s", want, article.Text)
+				}
+			}
+			for _, noise := range tc.noise {
+				if strings.Contains(article.Markdown, noise) || strings.Contains(article.Text, noise) {
+					t.Errorf("正文包含非正文内容 %q", noise)
+				}
+			}
+			for _, mark := range []string{"**", "`", "](", "## ", "| --- |", "> "} {
+				if strings.Contains(article.Text, mark) {
+					t.Errorf("Text 包含 Markdown 标记 %q", mark)
+				}
+			}
+			if article.WordCount == 0 || article.WordCount != countWords(article.Text) {
+				t.Errorf("WordCount = %d", article.WordCount)
+			}
+		})
+	}
+}
+
+func TestExtractArticleTruncatesLongText(t *testing.T) {
+	var page strings.Builder
+	page.WriteString("<html><body><article>")
+	para := "<p>" + strings.Repeat("长文本，", 250) + "</p>"
+	for page.Len() < maxArticleRunes*4 {
+		page.WriteString(para)
+	}
+	page.WriteString("</article></body></html>")
+
+	doc, err := html.Parse(strings.NewReader(page.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	article := extractArticle(doc, &neturl.URL{Scheme: "https", Host: "example.com"})
+	if n := utf8.RuneCountInString(article.Markdown); n != maxArticleRunes {
+		t.Errorf("Markdown 字符数 = %d，期望 %d", n, maxArticleRunes)
+	}
+	if n := utf8.RuneCountInString(article.Text); n != maxArticleRunes {
+		t.Errorf("Text 字符数 = %d，期望 %d", n, maxArticleRunes)
+	}
+	if !utf8.ValidString(article.Markdown) || !utf8.ValidString(article.Text) {
+		t.Error("截断后的正文不是合法的 UTF-8")
+	}
+	if n := utf8.RuneCountInString(article.Excerpt); n != 200 {
+		t.Errorf("Excerpt 字符数 = %d，期望 200", n)
+	}
+}
+
+func TestScrapeArticleContentTypes(t *testing.T) {
+	page := loadTestPage(t, filepath.Join("readability", "news_meta.html"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		w.Write(page)
+	}))
+	defer srv.Close()
+	s := newTestScraper(2)
+
+	for _, contentType := range []string{"text/html; charset=utf-8", "application/xhtml+xml"} {
+		article, err := s.ScrapeArticle(srv.URL + "/?type=" + neturl.QueryEscape(contentType))
+		if err != nil {
+			t.Errorf("%s: %v", contentType, err)
+			continue
+		}
+		if article.Title != "City Opens New Public Library" {
+			t.Errorf("%s: Title = %q", contentType, article.Title)
+		}
+	}
+
+	for _, contentType := range []string{"application/pdf", "text/plain", "application/json", "image/png", "not a media type"} {
+		if _, err := s.ScrapeArticle(srv.URL + "/?type=" + neturl.QueryEscape(contentType)); err == nil || !strings.Contains(err.Error(), "不支持的页面类型") {
+			t.Errorf("%s: %v，期望不支持的页面类型", contentType, err)
+		}
+	}
+}
+
+func TestScrapeArticleResolvesRelativeURLs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><base href="/static/"></head><body><article>
+<p>这一段正文足够长，用来确认相对地址按照 base 标签解析，<a href="doc.html">相关文档</a>，以及图片地址。</p>
+<p><img src="pic.png" alt="图片"></p>
+</article></body></html>`)
+	}))
+	defer srv.Close()
+
+	article, err := newTestScraper(2).ScrapeArticle(srv.URL + "/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[相关文档](" + srv.URL + "/static/doc.html)", "![图片](" + srv.URL + "/static/pic.png)"} {
+		if !strings.Contains(article.Markdown, want) {
+			t.Errorf("Markdown 不包含 %q:\n%s", want, article.Markdown)
+		}
+	}
+}
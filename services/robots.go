@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowedByRobots 目标地址被网站的 robots.txt 禁止抓取
+var ErrDisallowedByRobots = errors.New("robots.txt 禁止抓取该地址")
+
+// robotsUserAgent 检查 robots.txt 时附加到 User-Agent 的产品标识，与 robotsAgent 对应，
+// 使网站能把请求和 robots.txt 中 User-agent: LinkGenie 的分组对应起来
+const robotsUserAgent = "LinkGenie/1.0 (+https://github.com/riccilnl/LinkGenie)"
+
+const (
+	robotsAgent        = "linkgenie" // 匹配 robots.txt 中 User-agent 的产品名（小写）
+	robotsMaxSize      = 512 * 1024
+	robotsCacheTTL     = 24 * time.Hour
+	robotsErrorTTL     = 10 * time.Minute // 获取失败时按允许处理，较短时间后重试
+	robotsFetchTimeout = 10 * time.Second
+)
+
+// robotsRule robots.txt 中的一条 Allow/Disallow 规则
+type robotsRule struct {
+	length int // 原始规则长度，最长匹配的规则生效
+	allow  bool
+	re     *regexp.Regexp
+}
+
+// robotsRules 适用于本服务的规则，nil 表示全部允许
+type robotsRules []robotsRule
+
+// Allowed 路径（含查询参数）是否允许抓取，按最长匹配，长度相同时 Allow 优先
+func (rules robotsRules) Allowed(path string) bool {
+	best, allowed := -1, true
+	for _, r := range rules {
+		if r.length < best || (r.length == best && !r.allow) || !r.re.MatchString(path) {
+			continue
+		}
+		best, allowed = r.length, r.allow
+	}
+	return allowed
+}
+
+// parseRobots 解析 robots.txt，返回 agent 适用的规则
+// 有专门针对 agent 的分组时只使用这些分组，否则使用 User-agent: * 的分组
+func parseRobots(body []byte, agent string) robotsRules {
+	var specific, generic robotsRules
+	hasSpecific := false
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// 规则之后出现的 User-agent 开始新的分组
+			if inRules {
+				groupAgents, inRules = nil, false
+			}
+			value = strings.ToLower(value)
+			groupAgents = append(groupAgents, value)
+			if value == agent {
+				hasSpecific = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			// 空的 Disallow 表示全部允许
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{length: len(value), allow: key == "allow", re: robotsPattern(value)}
+			for _, a := range groupAgents {
+				if a == agent {
+					specific = append(specific, rule)
+				} else if a == "*" {
+					generic = append(generic, rule)
+				}
+			}
+		}
+	}
+	if hasSpecific {
+		return specific
+	}
+	return generic
+}
+
+// robotsPattern 将规则转换为正则: * 匹配任意字符，末尾的 $ 表示路径结尾
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsEntry 缓存的 robots.txt 规则
+type robotsEntry struct {
+	rules   robotsRules
+	expires time.Time
+}
+
+// robotsChecker 按主机获取并缓存 robots.txt
+type robotsChecker struct {
+	do    func(ctx context.Context, url string) (*http.Response, error) // 发送 GET 请求
+	mu    sync.Mutex
+	cache map[string]robotsEntry // scheme://host -> 规则
+}
+
+func newRobotsChecker(do func(ctx context.Context, url string) (*http.Response, error)) *robotsChecker {
+	return &robotsChecker{do: do, cache: map[string]robotsEntry{}}
+}
+
+// Allowed 检查地址是否允许抓取，robots.txt 本身总是允许
+func (c *robotsChecker) Allowed(ctx context.Context, u *neturl.URL) bool {
+	if u.Path == "/robots.txt" {
+		return true
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	c.mu.Lock()
+	entry, ok := c.cache[origin]
+	c.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		entry = c.fetch(ctx, origin)
+		c.mu.Lock()
+		c.cache[origin] = entry
+		c.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return entry.rules.Allowed(path)
+}
+
+// fetch 获取 robots.txt: 4xx 表示没有限制；网络错误和 5xx 同样按允许处理，但只缓存较短时间
+func (c *robotsChecker) fetch(ctx context.Context, origin string) robotsEntry {
+	ctx, cancel := context.WithTimeout(ctx, robotsFetchTimeout)
+	defer cancel()
+
+	rules, err := c.get(ctx, origin+"/robots.txt")
+	if err != nil {
+		log.Printf("⚠️ 获取 robots.txt 失败: %s, 错误: %v", origin, err)
+		return robotsEntry{expires: time.Now().Add(robotsErrorTTL)}
+	}
+	return robotsEntry{rules: rules, expires: time.Now().Add(robotsCacheTTL)}
+}
+
+func (c *robotsChecker) get(ctx context.Context, robotsURL string) (robotsRules, error) {
+	resp, err := c.do(ctx, robotsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("返回错误状态: %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseRobots(body, robotsAgent), nil
+}
+
+// getRobots 请求 robots.txt，与抓取网页一样使用目标域名的抓取配置（请求头、Cookie、代理）并占用主机名额
+func (s *ScraperService) getRobots(ctx context.Context, robotsURL string) (*http.Response, error) {
+	req, err := s.newRequest(ctx, "GET", robotsURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, false)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		paths map[string]bool // 路径 -> 是否允许
+	}{
+		{
+			name: "最长匹配",
+			body: "User-agent: *\nDisallow: /private\nAllow: /private/public\n",
+			paths: map[string]bool{
+				"/private":          false,
+				"/private/x":        false,
+				"/private/public":   true,
+				"/private/public/a": true,
+				"/privacy":          true,
+				"/":                 true,
+			},
+		},
+		{
+			name: "长度相同时 Allow 优先",
+			body: "User-agent: *\nDisallow: /page\nAllow: /page\nDisallow: /a*c\nAllow: /abc\n",
+			paths: map[string]bool{
+				"/page":  true,
+				"/page2": true,
+				"/abc":   true,
+				"/axc":   false,
+			},
+		},
+		{
+			name: "* 通配符",
+			body: "User-agent: *\nDisallow: /*.pdf\nDisallow: /*?sessionid=\nDisallow: /user/*/settings\n",
+			paths: map[string]bool{
+				"/docs/a.pdf":          false,
+				"/docs/a.pdf?x=1":      false,
+				"/docs/a.html":         true,
+				"/page?sessionid=1":    false,
+				"/page?lang=zh":        true,
+				"/user/42/settings":    false,
+				"/user/42/profile":     true,
+				"/user/settings":       true,
+				"/docs/a.pdf.html":     false,
+				"/docs/report(1).html": true,
+			},
+		},
+		{
+			name: "$ 匹配路径结尾",
+			body: "User-agent: *\nDisallow: /*.pdf$\nDisallow: /exact$\n",
+			paths: map[string]bool{
+				"/a.pdf":            false,
+				"/a.pdf?download=1": true,
+				"/a.pdf.html":       true,
+				"/exact":            false,
+				"/exact/child":      true,
+			},
+		},
+		{
+			name: "专门的分组覆盖 *",
+			body: "User-agent: *\nDisallow: /\n\nUser-agent: LinkGenie\nDisallow: /private\n",
+			paths: map[string]bool{
+				"/":        true,
+				"/public":  true,
+				"/private": false,
+			},
+		},
+		{
+			name: "连续多行 User-agent 共用一个分组",
+			body: "User-agent: Googlebot\nUser-agent: linkgenie\nDisallow: /shared\n\nUser-agent: *\nDisallow: /\n",
+			paths: map[string]bool{
+				"/shared": false,
+				"/other":  true,
+			},
+		},
+		{
+			name: "规则之后的 User-agent 开始新分组",
+			body: "User-agent: *\nDisallow: /generic\nUser-agent: Googlebot\nDisallow: /google\n",
+			paths: map[string]bool{
+				"/generic": false,
+				"/google":  true,
+			},
+		},
+		{
+			name: "同一 agent 的多个分组合并",
+			body: "User-agent: linkgenie\nDisallow: /a\n\nUser-agent: Googlebot\nDisallow: /b\n\nUser-agent: LinkGenie\nDisallow: /c\n",
+			paths: map[string]bool{
+				"/a": false,
+				"/b": true,
+				"/c": false,
+			},
+		},
+		{
+			name: "只针对其他爬虫的规则",
+			body: "User-agent: Googlebot\nDisallow: /\n",
+			paths: map[string]bool{
+				"/":     true,
+				"/page": true,
+			},
+		},
+		{
+			name: "空的 Disallow 表示全部允许",
+			body: "User-agent: *\nDisallow:\n",
+			paths: map[string]bool{
+				"/":     true,
+				"/page": true,
+			},
+		},
+		{
+			name: "注释、大小写和没有分组的规则",
+			body: "Disallow: /orphan\n# 注释\nUSER-AGENT: * # 所有爬虫\nDISALLOW: /tmp # 临时文件\nSitemap: https://example.com/sitemap.xml\n",
+			paths: map[string]bool{
+				"/tmp/a":  false,
+				"/orphan": true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := parseRobots([]byte(tc.body), robotsAgent)
+			for path, want := range tc.paths {
+				if got := rules.Allowed(path); got != want {
+					t.Errorf("Allowed(%q) = %v，期望 %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRobotsUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	agents := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.URL.Path] = r.Header.Get("User-Agent")
+		mu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /\n\nUser-agent: LinkGenie\nAllow: /\n")
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>页面</title></head></html>`)
+	}))
+	defer srv.Close()
+
+	cfg := newTestConfig(2)
+	cfg.ScraperRespectRobots = true
+	s := NewScraperService(cfg)
+	if _, err := s.ScrapeWebPage(srv.URL + "/page"); err != nil {
+		t.Fatalf("robots.txt 允许 LinkGenie 抓取: %v", err)
+	}
+	mu.Lock()
+	for _, path := range []string{"/robots.txt", "/page"} {
+		if ua := agents[path]; !strings.HasPrefix(ua, browserHeaders["User-Agent"]) || !strings.HasSuffix(ua, " "+robotsUserAgent) {
+			t.Errorf("%s 的 User-Agent = %q，期望浏览器 User-Agent 加上 %q", path, ua, robotsUserAgent)
+		}
+	}
+	mu.Unlock()
+
+	// 抓取配置中的 User-Agent 优先
+	s.SetProfiles([]*models.ScraperProfile{{Domain: "127.0.0.1", Headers: map[string]string{"User-Agent": "custom-agent"}}})
+	if _, err := s.ScrapeWebPage(srv.URL + "/custom"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if ua := agents["/custom"]; ua != "custom-agent" {
+		t.Errorf("User-Agent = %q，期望 custom-agent", ua)
+	}
+	mu.Unlock()
+
+	// 不检查 robots.txt 时只使用浏览器 User-Agent，且不修改共用的默认请求头
+	if _, err := newTestScraper(2).ScrapeWebPage(srv.URL + "/plain"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if ua := agents["/plain"]; ua != browserHeaders["User-Agent"] || strings.Contains(browserHeaders["User-Agent"], "LinkGenie") {
+		t.Errorf("User-Agent = %q", ua)
+	}
+	mu.Unlock()
+}
+
+func TestMetadataCacheEviction(t *testing.T) {
+	c := newMetadataCache(time.Hour)
+	for i := 0; i < metadataCacheMaxEntries; i++ {
+		c.Set(fmt.Sprintf("https://example.com/%d", i), &models.PageMetadata{Title: fmt.Sprint(i)})
+	}
+	// 让第 5 条最早过期
+	oldest := "https://example.com/5"
+	entry := c.entries[oldest]
+	entry.expires = time.Now().Add(time.Minute)
+	c.entries[oldest] = entry
+
+	// 覆盖已有条目不移除其他条目
+	c.Set("https://example.com/0", &models.PageMetadata{Title: "更新"})
+	if len(c.entries) != metadataCacheMaxEntries {
+		t.Fatalf("条目数 = %d，期望 %d", len(c.entries), metadataCacheMaxEntries)
+	}
+	if got, ok := c.Get("https://example.com/0"); !ok || got.Title != "更新" {
+		t.Errorf("更新后的缓存 = %v, %v", got, ok)
+	}
+
+	// 缓存已满时移除最早过期的条目
+	c.Set("https://example.com/new", &models.PageMetadata{Title: "新"})
+	if len(c.entries) != metadataCacheMaxEntries {
+		t.Errorf("条目数 = %d，期望 %d", len(c.entries), metadataCacheMaxEntries)
+	}
+	if _, ok := c.Get(oldest); ok {
+		t.Error("最早过期的条目没有被移除")
+	}
+	if _, ok := c.Get("https://example.com/new"); !ok {
+		t.Error("新条目没有被缓存")
+	}
+
+	// 有过期条目时先清除过期条目，不移除未过期的条目
+	for i := 10; i < 20; i++ {
+		u := fmt.Sprintf("https://example.com/%d", i)
+		entry := c.entries[u]
+		entry.expires = time.Now().Add(-time.Second)
+		c.entries[u] = entry
+	}
+	c.Set("https://example.com/after-expired", &models.PageMetadata{})
+	if want := metadataCacheMaxEntries - 10 + 1; len(c.entries) != want {
+		t.Errorf("条目数 = %d，期望 %d", len(c.entries), want)
+	}
+	if _, ok := c.Get("https://example.com/20"); !ok {
+		t.Error("未过期的条目被移除")
+	}
+
+	// Get 返回副本
+	got, _ := c.Get("https://example.com/new")
+	got.Title = "修改"
+	if again, _ := c.Get("https://example.com/new"); again.Title != "新" {
+		t.Errorf("修改返回值影响了缓存: %q", again.Title)
+	}
+
+	// ttl <= 0 时不缓存
+	disabled := newMetadataCache(0)
+	disabled.Set("https://example.com/", &models.PageMetadata{})
+	if _, ok := disabled.Get("https://example.com/"); ok || len(disabled.entries) != 0 {
+		t.Error("ttl 为 0 时不应缓存")
+	}
+}
@@ -1,63 +1,278 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strings"
 	"time"
 
+	"ai-bookmark-service/config"
 	"ai-bookmark-service/models"
 
 	"golang.org/x/net/html"
 )
 
 // ScraperService 网页抓取服务
+// 所有请求共用一个 http.Client（复用连接），并限制同一主机的并发数
 type ScraperService struct {
-	timeout time.Duration
+	client     *http.Client
+	guard      *ssrfGuard        // 禁止访问本机和内网地址（白名单除外）
+	headers    map[string]string // 默认请求头，见 browserHeaders
+	timeout    time.Duration
+	maxBody    int64 // 单个响应体的最大字节数
+	redirects  int   // 最多跟随的重定向次数
+	hosts      *hostLimiter
+	robots     *robotsChecker // 为 nil 时不检查 robots.txt
+	cache      *metadataCache
+	inflight   *metadataFlight        // 合并同一地址同时进行的元数据抓取
+	profiles   scraperProfiles        // 按域名的抓取配置（请求头、Cookie、代理、超时）
+	extractors *SiteExtractorRegistry // 特定网站的元数据提取器
 }
 
 // NewScraperService 创建抓取服务
-func NewScraperService() *ScraperService {
-	return &ScraperService{
-		timeout: 30 * time.Second,
+func NewScraperService(cfg *config.Config) *ScraperService {
+	timeout := time.Duration(cfg.ScraperTimeout) * time.Second
+	guard := newSSRFGuard(strings.Split(cfg.ScraperAllowedHosts, ","))
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = guard.DialContext
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = cfg.ScraperHostConcurrency
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = timeout
+	// 超时由每次请求的 context 控制，便于检查接口使用更短的超时
+	client := &http.Client{Transport: transport}
+
+	s := &ScraperService{
+		client:     client,
+		guard:      guard,
+		headers:    browserHeaders,
+		timeout:    timeout,
+		maxBody:    int64(cfg.ScraperMaxBodyMB) << 20,
+		redirects:  cfg.ScraperMaxRedirects,
+		hosts:      newHostLimiter(cfg.ScraperHostConcurrency),
+		cache:      newMetadataCache(time.Duration(cfg.ScraperCacheTTL) * time.Minute),
+		inflight:   newMetadataFlight(),
+		extractors: DefaultSiteExtractors(),
+	}
+	// 每次重定向都重新检查目标地址，并按目标域名的抓取配置设置请求头
+	client.CheckRedirect = s.checkRedirect
+	if cfg.ScraperRespectRobots {
+		s.robots = newRobotsChecker(s.getRobots)
+		// 按 robots.txt 中 LinkGenie 分组的规则抓取时，User-Agent 中也要带上 LinkGenie，便于网站识别
+		s.headers = make(map[string]string, len(browserHeaders))
+		for name, value := range browserHeaders {
+			s.headers[name] = value
+		}
+		s.headers["User-Agent"] += " " + robotsUserAgent
 	}
+	return s
 }
 
-// ScrapeWebPage 抓取网页元数据
-func (s *ScraperService) ScrapeWebPage(url string) (*models.PageMetadata, error) {
-	// 创建请求
-	req, err := http.NewRequest("GET", url, nil)
+// browserHeaders 模拟浏览器访问的默认请求头，避免被反爬虫拦截
+var browserHeaders = map[string]string{
+	"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+	"Accept-Language": "zh-CN,zh;q=0.9,en;q=0.8",
+	"Referer":         "https://www.google.com/",
+}
+
+// newRequest 创建带浏览器请求头的请求，目标域名有抓取配置时按配置覆盖请求头和 Cookie
+func (s *ScraperService) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 
-	// 设置User-Agent,模拟浏览器访问,避免被反爬虫拦截
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
-	req.Header.Set("Referer", "https://www.google.com/")
+	// 设置User-Agent等请求头,模拟浏览器访问,避免被反爬虫拦截
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	applyProfile(req, s.profileFor(req.URL.Hostname()))
+	return req, nil
+}
 
-	// 发送请求
-	client := &http.Client{
-		Timeout: s.timeout,
+// do 发送请求: 检查目标地址，等待主机的空闲名额，按抓取配置选择代理
+// fetchContent 为 true 时表示抓取网页内容: 域名被配置为禁止抓取时返回 ErrScrapingDisabled，启用了 robots.txt 检查时先确认允许抓取
+// 重定向时按目标域名的抓取配置重新设置请求头，目标域名需要使用不同的代理时使用对应的 client 重新发送请求
+// 响应体关闭时释放名额，调用方必须关闭响应体
+func (s *ScraperService) do(req *http.Request, fetchContent bool) (*http.Response, error) {
+	// 连接时还会再次检查解析到的地址；这里提前检查，使用代理时同样生效
+	if err := s.guard.CheckURL(req.Context(), req.URL); err != nil {
+		return nil, err
 	}
-	
+	profile := s.profileFor(req.URL.Hostname())
+	if fetchContent && profile != nil && profile.Disabled {
+		return nil, ErrScrapingDisabled
+	}
+	// robots.txt 同样经 do 请求，需要在占用名额之前检查
+	if fetchContent && s.robots != nil && !s.robots.Allowed(req.Context(), req.URL) {
+		return nil, ErrDisallowedByRobots
+	}
+	if fetchContent {
+		req = req.WithContext(context.WithValue(req.Context(), fetchContentKey{}, true))
+	}
+	release, err := s.hosts.Acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("等待抓取名额超时: %w", err)
+	}
+
+	client := s.clientFor(profile)
 	resp, err := client.Do(req)
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
+	if target := s.proxyRedirectTarget(resp, client); target != nil {
+		resp.Body.Close()
+		release()
+		return s.redirectWithProxy(req, target, fetchContent)
+	}
+	if resp.ContentLength > s.maxBody {
+		resp.Body.Close()
+		release()
+		return nil, fmt.Errorf("%w: %d 字节", ErrBodyTooLarge, resp.ContentLength)
+	}
+	resp.Body = &releaseOnClose{ReadCloser: &limitedBody{ReadCloser: resp.Body, remaining: s.maxBody}, release: release}
+	return resp, nil
+}
+
+// CheckLink 检查链接可用性，返回最终状态码和重定向后的地址
+// 先发送 HEAD 请求，服务器不支持 HEAD 时改用 GET；只检查状态，不受 robots.txt 和禁止抓取的配置限制
+func (s *ScraperService) CheckLink(url string) (int, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeoutFor(url))
+	defer cancel()
+
+	var resp *http.Response
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := s.newRequest(ctx, method, url)
+		if err != nil {
+			return 0, "", err
+		}
+		resp, err = s.do(req, false)
+		if err != nil {
+			return 0, "", err
+		}
+		resp.Body.Close()
+		if method == "HEAD" && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusForbidden) {
+			continue
+		}
+		break
+	}
+
+	return resp.StatusCode, resp.Request.URL.String(), nil
+}
+
+// FetchedResource 抓取到的资源内容
+type FetchedResource struct {
+	Body        []byte
+	ContentType string
+	URL         *neturl.URL // 跟随重定向后的地址
+}
+
+// Fetch 抓取资源的完整内容，超过 maxSize 字节时返回错误
+func (s *ScraperService) Fetch(url string, maxSize int64) (*FetchedResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeoutFor(url))
+	defer cancel()
+	return s.fetch(ctx, url, maxSize)
+}
+
+func (s *ScraperService) fetch(ctx context.Context, url string, maxSize int64) (*FetchedResource, error) {
+	req, err := s.newRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, true)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("返回错误状态: %d %s", resp.StatusCode, resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("内容过大: %d 字节", resp.ContentLength)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取内容失败: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("内容超过 %d 字节", maxSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return &FetchedResource{Body: body, ContentType: contentType, URL: resp.Request.URL}, nil
+}
+
+// ScrapeWebPage 抓取网页元数据
+func (s *ScraperService) ScrapeWebPage(url string) (*models.PageMetadata, error) {
+	return s.ScrapeWebPageTimeout(url, s.timeoutFor(url))
+}
+
+// ScrapeWebPageTimeout 使用指定超时抓取网页元数据，缓存未过期时直接返回缓存的结果
+func (s *ScraperService) ScrapeWebPageTimeout(url string, timeout time.Duration) (*models.PageMetadata, error) {
+	if metadata, ok := s.cache.Get(url); ok {
+		return metadata, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// 元数据加载、AI 增强和检查接口可能同时抓取同一网页，只发送一次请求
+	return s.inflight.Do(ctx, url, func() (*models.PageMetadata, error) {
+		metadata, err := s.scrapeWebPage(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(url, metadata)
+		return metadata, nil
+	})
+}
+
+func (s *ScraperService) scrapeWebPage(ctx context.Context, url string) (*models.PageMetadata, error) {
+	// 创建请求
+	req, err := s.newRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := s.do(req, true)
+	if err != nil {
+		return nil, err
+	}
 	
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("网页返回错误状态: %d %s", resp.StatusCode, resp.Status)
 	}
 	
 	// 限制读取大小为128KB (增加到128KB以获取更多内容)
-	limitedReader := io.LimitReader(resp.Body, 128*1024)
-	
-	// 解析HTML
-	doc, err := html.Parse(limitedReader)
+	// 读取后立即关闭响应体释放主机名额，后面的 oEmbed 请求可能需要同一主机的名额
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 128*1024))
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取网页失败: %w", err)
+	}
+
+	// 转换为 UTF-8 后解析HTML，避免 GBK/Big5 等编码的网页出现乱码
+	body, _, err = decodeHTML(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("HTML解析失败: %w", err)
 	}
@@ -106,6 +321,28 @@ func (s *ScraperService) ScrapeWebPage(url string) (*models.PageMetadata, error)
 				if name == "twitter:description" && metadata.OGDesc == "" {
 					metadata.OGDesc = content
 				}
+
+				// 提取预览图
+				if property == "og:image" || (name == "twitter:image" && metadata.Image == "") {
+					metadata.Image = content
+				}
+			case "link":
+				var rel, href string
+				for _, attr := range n.Attr {
+					switch attr.Key {
+					case "rel":
+						rel = strings.ToLower(attr.Val)
+					case "href":
+						href = attr.Val
+					}
+				}
+
+				// 提取图标，apple-touch-icon 仅在没有普通图标时使用
+				for _, r := range strings.Fields(rel) {
+					if r == "icon" || (r == "apple-touch-icon" && metadata.Favicon == "") {
+						metadata.Favicon = href
+					}
+				}
 			}
 		}
 		
@@ -114,6 +351,33 @@ func (s *ScraperService) ScrapeWebPage(url string) (*models.PageMetadata, error)
 		}
 	}
 	f(doc)
-	
+
+	// 特定网站的提取器优先于通用的结构化元数据
+	base := resp.Request.URL
+	s.extractors.Apply(base, doc, metadata)
+
+	// 图片地址解析为绝对地址，页面未声明图标时使用站点根目录的 favicon.ico
+	metadata.Image = resolveURL(base, metadata.Image)
+	if metadata.Favicon == "" {
+		metadata.Favicon = "/favicon.ico"
+	}
+	metadata.Favicon = resolveURL(base, metadata.Favicon)
+
+	// 结构化元数据（作者、发布时间、内容类型等），估算阅读时间时会修改 doc，放在最后
+	s.extractStructuredMetadata(ctx, doc, base, metadata)
+
 	return metadata, nil
 }
+
+// resolveURL 将页面中的相对地址解析为绝对地址，无法解析时返回空字符串
+func resolveURL(base *neturl.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// metadataCacheMaxEntries 网页元数据缓存的最大条目数
+const metadataCacheMaxEntries = 1000
+
+// metadataCacheEntry 缓存的网页元数据
+type metadataCacheEntry struct {
+	metadata *models.PageMetadata
+	expires  time.Time
+}
+
+// metadataCache 按地址缓存抓取到的网页元数据，避免检查接口、元数据加载和 AI 增强短时间内重复抓取同一网页
+type metadataCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]metadataCacheEntry
+}
+
+// newMetadataCache 创建元数据缓存，ttl <= 0 时不缓存
+func newMetadataCache(ttl time.Duration) *metadataCache {
+	return &metadataCache{ttl: ttl, entries: map[string]metadataCacheEntry{}}
+}
+
+// Get 获取未过期的缓存，返回副本
+func (c *metadataCache) Get(url string) (*models.PageMetadata, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, url)
+		return nil, false
+	}
+	metadata := *entry.metadata
+	return &metadata, true
+}
+
+// Clear 清空缓存
+func (c *metadataCache) Clear() {
+	c.mu.Lock()
+	c.entries = map[string]metadataCacheEntry{}
+	c.mu.Unlock()
+}
+
+// Set 缓存元数据；缓存已满时先清除过期条目，仍然已满则移除最早过期的条目
+func (c *metadataCache) Set(url string, metadata *models.PageMetadata) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, exists := c.entries[url]; !exists && len(c.entries) >= metadataCacheMaxEntries {
+		oldestURL, oldest := "", time.Time{}
+		for u, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, u)
+			} else if oldestURL == "" || e.expires.Before(oldest) {
+				oldestURL, oldest = u, e.expires
+			}
+		}
+		if len(c.entries) >= metadataCacheMaxEntries {
+			delete(c.entries, oldestURL)
+		}
+	}
+
+	copied := *metadata
+	c.entries[url] = metadataCacheEntry{metadata: &copied, expires: now.Add(c.ttl)}
+}
+
+// metadataCall 正在进行的网页抓取
+type metadataCall struct {
+	done     chan struct{}
+	metadata *models.PageMetadata
+	err      error
+}
+
+// metadataFlight 合并同一地址同时进行的抓取，缓存未命中时多个调用方只发送一次请求
+type metadataFlight struct {
+	mu    sync.Mutex
+	calls map[string]*metadataCall
+}
+
+// newMetadataFlight 创建抓取合并器
+func newMetadataFlight() *metadataFlight {
+	return &metadataFlight{calls: map[string]*metadataCall{}}
+}
+
+// Do 同一地址已有抓取在进行时等待其结果（ctx 结束时放弃等待），否则调用 fn 抓取；等待的调用方得到结果的副本
+func (f *metadataFlight) Do(ctx context.Context, url string, fn func() (*models.PageMetadata, error)) (*models.PageMetadata, error) {
+	f.mu.Lock()
+	if call, ok := f.calls[url]; ok {
+		f.mu.Unlock()
+		select {
+		case <-call.done:
+			if call.err != nil {
+				return nil, call.err
+			}
+			metadata := *call.metadata
+			return &metadata, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &metadataCall{done: make(chan struct{}), err: errors.New("抓取网页中断")}
+	f.calls[url] = call
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, url)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+	call.metadata, call.err = fn()
+	return call.metadata, call.err
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+// ErrScrapingDisabled 目标域名的抓取配置禁止抓取网页内容
+var ErrScrapingDisabled = errors.New("该域名已禁止抓取")
+
+// scraperProfiles 按域名的抓取配置（数据库中配置的内存副本），以及使用代理的配置对应的 http.Client
+type scraperProfiles struct {
+	mu       sync.RWMutex
+	profiles []*models.ScraperProfile
+	clients  map[string]*http.Client // 代理地址 -> 经该代理请求的 client
+}
+
+// SetProfiles 替换按域名的抓取配置，并清空网页元数据缓存（缓存可能是按旧配置抓取的）
+func (s *ScraperService) SetProfiles(profiles []*models.ScraperProfile) {
+	clients := map[string]*http.Client{}
+	for _, p := range profiles {
+		if p.ProxyURL == "" || clients[p.ProxyURL] != nil {
+			continue
+		}
+		client, err := s.newProxyClient(p.ProxyURL)
+		if err != nil {
+			// 保存时已校验过代理地址，这里只记录无法使用的配置
+			log.Printf("⚠️ 抓取配置 %s 的代理地址无效: %v", p.Domain, err)
+			continue
+		}
+		clients[p.ProxyURL] = client
+	}
+
+	s.profiles.mu.Lock()
+	old := s.profiles.clients
+	s.profiles.profiles = profiles
+	s.profiles.clients = clients
+	s.profiles.mu.Unlock()
+
+	for _, c := range old {
+		c.CloseIdleConnections()
+	}
+	s.cache.Clear()
+}
+
+// profileFor 返回主机名匹配的抓取配置（域名最长者优先），没有时返回 nil
+func (s *ScraperService) profileFor(host string) *models.ScraperProfile {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	s.profiles.mu.RLock()
+	defer s.profiles.mu.RUnlock()
+
+	var best *models.ScraperProfile
+	for _, p := range s.profiles.profiles {
+		if (host == p.Domain || strings.HasSuffix(host, "."+p.Domain)) && (best == nil || len(p.Domain) > len(best.Domain)) {
+			best = p
+		}
+	}
+	return best
+}
+
+// clientFor 返回请求应使用的 client: 配置了代理时使用对应的代理 client
+func (s *ScraperService) clientFor(profile *models.ScraperProfile) *http.Client {
+	if profile == nil || profile.ProxyURL == "" {
+		return s.client
+	}
+	s.profiles.mu.RLock()
+	defer s.profiles.mu.RUnlock()
+	if client := s.profiles.clients[profile.ProxyURL]; client != nil {
+		return client
+	}
+	return s.client
+}
+
+// timeoutFor 返回抓取地址使用的超时: 抓取配置中设置了超时时使用配置的值
+func (s *ScraperService) timeoutFor(rawURL string) time.Duration {
+	if u, err := neturl.Parse(rawURL); err == nil {
+		if p := s.profileFor(u.Hostname()); p != nil && p.Timeout > 0 {
+			return time.Duration(p.Timeout) * time.Second
+		}
+	}
+	return s.timeout
+}
+
+// applyProfile 按抓取配置设置请求头和 Cookie，请求头的值为空时删除该请求头
+func applyProfile(req *http.Request, profile *models.ScraperProfile) {
+	if profile == nil {
+		return
+	}
+	for name, value := range profile.Headers {
+		if value == "" {
+			req.Header.Del(name)
+		} else {
+			req.Header.Set(name, value)
+		}
+	}
+	if profile.Cookies != "" {
+		req.Header.Set("Cookie", profile.Cookies)
+	}
+}
+
+// fetchContentKey 标记抓取网页内容的请求（context 中的值），重定向时据此检查目标域名是否禁止抓取
+type fetchContentKey struct{}
+
+// redirectCountKey 切换代理重新发送请求时，在 context 中记录之前已跟随的重定向次数
+type redirectCountKey struct{}
+
+// checkRedirect 限制重定向次数并检查目标地址，按目标域名的抓取配置处理每一次重定向
+// Go 跟随重定向时会把首个请求的自定义请求头带到新主机，这里先去掉首个请求按配置设置的请求头和 Cookie，再应用目标域名的配置；
+// 抓取网页内容时目标域名禁止抓取则停止；目标域名需要使用不同的代理时不跟随，由 do 使用对应的 client 重新发送请求
+func (s *ScraperService) checkRedirect(req *http.Request, via []*http.Request) error {
+	previous, _ := req.Context().Value(redirectCountKey{}).(int)
+	if previous+len(via) > s.redirects {
+		return fmt.Errorf("重定向次数超过 %d 次", s.redirects)
+	}
+	if err := s.guard.CheckURL(req.Context(), req.URL); err != nil {
+		return err
+	}
+
+	profile := s.profileFor(req.URL.Hostname())
+	if fetchContent, _ := req.Context().Value(fetchContentKey{}).(bool); fetchContent && profile != nil && profile.Disabled {
+		return ErrScrapingDisabled
+	}
+	initial := s.profileFor(via[0].URL.Hostname())
+	if s.clientFor(profile) != s.clientFor(initial) {
+		return http.ErrUseLastResponse
+	}
+	if initial != nil {
+		for name := range initial.Headers {
+			req.Header.Del(name)
+			if value, ok := s.headers[http.CanonicalHeaderKey(name)]; ok {
+				req.Header.Set(name, value)
+			}
+		}
+		if initial.Cookies != "" {
+			req.Header.Del("Cookie")
+		}
+	}
+	applyProfile(req, profile)
+	return nil
+}
+
+// proxyRedirectTarget 返回 checkRedirect 因需要切换代理而未跟随的重定向地址，没有时返回 nil
+func (s *ScraperService) proxyRedirectTarget(resp *http.Response, client *http.Client) *neturl.URL {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil
+	}
+	target, err := resp.Location()
+	if err != nil || s.clientFor(s.profileFor(target.Hostname())) == client {
+		return nil
+	}
+	return target
+}
+
+// redirectWithProxy 按目标域名的抓取配置重新发送重定向请求（经 do 检查目标地址、选择代理）
+func (s *ScraperService) redirectWithProxy(req *http.Request, target *neturl.URL, fetchContent bool) (*http.Response, error) {
+	count, _ := req.Context().Value(redirectCountKey{}).(int)
+	count++
+	if count > s.redirects {
+		return nil, fmt.Errorf("重定向次数超过 %d 次", s.redirects)
+	}
+	next, err := s.newRequest(context.WithValue(req.Context(), redirectCountKey{}, count), req.Method, target.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.do(next, fetchContent)
+}
+
+// CheckProxyURL 检查代理地址: 代理主机解析后同样不能是本机或内网地址，除非已加入 SCRAPER_ALLOWED_HOSTS
+func (s *ScraperService) CheckProxyURL(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	u, err := neturl.Parse(proxyURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if _, err := s.guard.resolve(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return fmt.Errorf("代理地址%w（使用内网代理时需要把代理地址加入 SCRAPER_ALLOWED_HOSTS）", err)
+		}
+		return fmt.Errorf("无法解析代理地址: %w", err)
+	}
+	return nil
+}
+
+// newProxyClient 创建经代理请求的 client
+// 连接代理时沿用 ssrfGuard 的检查，内网代理需要加入白名单；目标地址在 do 和重定向时检查
+func (s *ScraperService) newProxyClient(proxyURL string) (*http.Client, error) {
+	u, err := neturl.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	base := s.client.Transport.(*http.Transport)
+	transport := base.Clone()
+	transport.Proxy = http.ProxyURL(u)
+	return &http.Client{Transport: transport, CheckRedirect: s.client.CheckRedirect}, nil
+}
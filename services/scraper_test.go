@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/config"
+	"ai-bookmark-service/models"
+)
+
+// newTestConfig 允许访问本机测试服务的抓取配置
+func newTestConfig(hostConcurrency int) *config.Config {
+	return &config.Config{
+		ScraperTimeout:         5,
+		ScraperHostConcurrency: hostConcurrency,
+		ScraperAllowedHosts:    "127.0.0.1,localhost,::1",
+		ScraperMaxRedirects:    5,
+		ScraperMaxBodyMB:       1,
+	}
+}
+
+// newTestScraper 创建允许访问本机测试服务的抓取服务
+func newTestScraper(hostConcurrency int) *ScraperService {
+	return NewScraperService(newTestConfig(hostConcurrency))
+}
+
+// newRedirectServers 创建重定向测试服务: origin（127.0.0.1）把 /page 重定向到 target（localhost）的 /page，
+// 两者主机名不同，分别匹配不同的抓取配置；target 记录收到的请求头
+func newRedirectServers(t *testing.T) (origin *httptest.Server, targetURL string, received func() http.Header) {
+	var mu sync.Mutex
+	var header http.Header
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		header = r.Header.Clone()
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>目标页面</title></head><body></body></html>`)
+	}))
+	t.Cleanup(target.Close)
+	targetURL = fmt.Sprintf("http://localhost:%d/page", target.Listener.Addr().(*net.TCPAddr).Port)
+
+	origin = httptest.NewServer(http.RedirectHandler(targetURL, http.StatusFound))
+	t.Cleanup(origin.Close)
+	return origin, targetURL, func() http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return header
+	}
+}
+
+func TestScrapeWebPageReleasesHostSlotBeforeOEmbed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>视频</title><link rel="alternate" type="application/json+oembed" href="/oembed"></head><body></body></html>`)
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type":"video","author_name":"作者","provider_name":"测试站点"}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// 同一主机只有一个名额: 抓取网页时仍占用名额的话，oEmbed 请求会一直等到超时
+	s := newTestScraper(1)
+	start := time.Now()
+	metadata, err := s.ScrapeWebPageTimeout(srv.URL+"/page", 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Author != "作者" || metadata.SiteName != "测试站点" {
+		t.Errorf("oEmbed 未生效: Author=%q SiteName=%q", metadata.Author, metadata.SiteName)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("抓取耗时 %v，oEmbed 请求在等待主机名额", elapsed)
+	}
+	if n := len(s.hosts.slots); n != 0 {
+		t.Errorf("抓取结束后仍有 %d 个主机名额", n)
+	}
+}
+
+func TestScrapeWebPageMergesConcurrentRequests(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>同一网页</title></head><body></body></html>`)
+	}))
+	defer srv.Close()
+
+	// 不使用缓存，确认是合并请求而不是缓存命中
+	s := newTestScraper(4)
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			metadata, err := s.ScrapeWebPageTimeout(srv.URL+"/", 2*time.Second)
+			errs[i] = err
+			if err == nil {
+				results[i] = metadata.Title
+			}
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i] != "同一网页" {
+			t.Errorf("第 %d 个请求: %q %v", i, results[i], errs[i])
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("网页被请求 %d 次，期望 1 次", n)
+	}
+}
+
+func TestHostLimiterRemovesIdleHosts(t *testing.T) {
+	l := newHostLimiter(1)
+	release, err := l.Acquire(context.Background(), "Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 名额已满时等待超时，不影响持有者
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "example.com"); err == nil {
+		t.Fatal("名额已满时应等待超时")
+	}
+	if slot := l.slots["example.com"]; slot == nil || slot.users != 1 {
+		t.Fatalf("等待超时后名额状态 = %+v", slot)
+	}
+
+	release()
+	release()
+	if len(l.slots) != 0 {
+		t.Errorf("释放后仍有 %d 个主机名额", len(l.slots))
+	}
+	if _, err := l.Acquire(context.Background(), "example.com"); err != nil {
+		t.Errorf("释放后无法再次获取名额: %v", err)
+	}
+}
+
+func TestRedirectAppliesTargetProfile(t *testing.T) {
+	origin, _, received := newRedirectServers(t)
+	s := newTestScraper(2)
+	s.SetProfiles([]*models.ScraperProfile{
+		{Domain: "127.0.0.1", Headers: map[string]string{"X-Origin-Secret": "a", "User-Agent": "origin-agent"}, Cookies: "origin=1"},
+		{Domain: "localhost", Headers: map[string]string{"X-Target-Token": "b"}, Cookies: "target=2"},
+	})
+
+	metadata, err := s.ScrapeWebPage(origin.URL + "/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Title != "目标页面" {
+		t.Fatalf("标题 = %q", metadata.Title)
+	}
+	h := received()
+	if h.Get("X-Origin-Secret") != "" || h.Get("User-Agent") != browserHeaders["User-Agent"] {
+		t.Errorf("原域名配置的请求头被带到重定向目标: X-Origin-Secret=%q User-Agent=%q", h.Get("X-Origin-Secret"), h.Get("User-Agent"))
+	}
+	if h.Get("X-Target-Token") != "b" || h.Get("Cookie") != "target=2" {
+		t.Errorf("未应用目标域名的配置: X-Target-Token=%q Cookie=%q", h.Get("X-Target-Token"), h.Get("Cookie"))
+	}
+
+	// 目标域名没有配置时不带任何配置的请求头
+	s.SetProfiles([]*models.ScraperProfile{
+		{Domain: "127.0.0.1", Headers: map[string]string{"X-Origin-Secret": "a"}, Cookies: "origin=1"},
+	})
+	if _, err := s.ScrapeWebPage(origin.URL + "/page"); err != nil {
+		t.Fatal(err)
+	}
+	if h := received(); h.Get("X-Origin-Secret") != "" || h.Get("Cookie") != "" {
+		t.Errorf("原域名配置的请求头被带到重定向目标: X-Origin-Secret=%q Cookie=%q", h.Get("X-Origin-Secret"), h.Get("Cookie"))
+	}
+}
+
+func TestRedirectToDisabledDomain(t *testing.T) {
+	origin, targetURL, _ := newRedirectServers(t)
+	s := newTestScraper(2)
+	s.SetProfiles([]*models.ScraperProfile{{Domain: "localhost", Disabled: true}})
+
+	if _, err := s.ScrapeWebPage(origin.URL + "/page"); !errors.Is(err, ErrScrapingDisabled) {
+		t.Errorf("重定向到禁止抓取的域名: %v，期望 ErrScrapingDisabled", err)
+	}
+	// 链接检查不受禁止抓取的配置限制
+	status, finalURL, err := s.CheckLink(origin.URL + "/page")
+	if err != nil || status != http.StatusOK || finalURL != targetURL {
+		t.Errorf("CheckLink = %d %q %v", status, finalURL, err)
+	}
+}
+
+func TestRedirectSwitchesProxy(t *testing.T) {
+	origin, targetURL, received := newRedirectServers(t)
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>经代理</title></head><body></body></html>`)
+	}))
+	defer proxy.Close()
+
+	s := newTestScraper(2)
+	s.SetProfiles([]*models.ScraperProfile{{Domain: "localhost", ProxyURL: proxy.URL}})
+
+	metadata, err := s.ScrapeWebPage(origin.URL + "/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Title != "经代理" || proxied != targetURL {
+		t.Errorf("重定向目标未经代理请求: 标题=%q 代理收到=%q", metadata.Title, proxied)
+	}
+	if received() != nil {
+		t.Error("重定向目标被直接请求")
+	}
+}
+
+func TestProxyAddressChecked(t *testing.T) {
+	s := newTestScraper(2)
+	s.guard.resolver = stubResolver{
+		"proxy.internal": {netip.MustParseAddr("10.0.0.8")},
+		"proxy.public":   {netip.MustParseAddr("127.0.0.1")}, // 127.0.0.1 在白名单中
+	}
+	for proxyURL, blocked := range map[string]bool{
+		"":                              false,
+		"http://proxy.public:8080":      false,
+		"http://127.0.0.1:3128":         false,
+		"http://10.0.0.8:3128":          true,
+		"http://proxy.internal:3128":    true,
+		"socks5://169.254.169.254:1080": true,
+		"http://[::ffff:10.0.0.8]:3128": true,
+	} {
+		if err := s.CheckProxyURL(proxyURL); errors.Is(err, ErrBlockedAddress) != blocked || (!blocked && err != nil) {
+			t.Errorf("CheckProxyURL(%q) = %v，期望 blocked=%v", proxyURL, err, blocked)
+		}
+	}
+	if err := s.CheckProxyURL("http://unknown.example:3128"); err == nil || errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("无法解析的代理地址 = %v，期望解析错误", err)
+	}
+
+	// 加入 SCRAPER_ALLOWED_HOSTS 后允许使用内网代理
+	cfg := newTestConfig(2)
+	cfg.ScraperAllowedHosts += ",10.0.0.0/8"
+	if err := NewScraperService(cfg).CheckProxyURL("http://10.0.0.8:3128"); err != nil {
+		t.Errorf("白名单中的代理地址: %v", err)
+	}
+
+	// 已保存的内网代理（例如白名单被修改）在连接时被拒绝，不会连接到代理
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	s.SetProfiles([]*models.ScraperProfile{{Domain: "127.0.0.1", ProxyURL: "http://proxy.internal:3128"}})
+	req, err := s.newRequest(context.Background(), "GET", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.do(req, false)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("经内网代理请求 = %v，期望 ErrBlockedAddress", err)
+	}
+}
+
+func TestRobotsUsesProfileAndHostLimiter(t *testing.T) {
+	var robotsToken string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		robotsToken = r.Header.Get("X-Token")
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>公开页面</title></head></html>`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// 同一主机只有一个名额，robots.txt 请求不能和网页请求同时占用名额
+	cfg := newTestConfig(1)
+	cfg.ScraperRespectRobots = true
+	s := NewScraperService(cfg)
+	s.SetProfiles([]*models.ScraperProfile{{Domain: "127.0.0.1", Headers: map[string]string{"X-Token": "secret"}}})
+
+	if _, err := s.ScrapeWebPageTimeout(srv.URL+"/private", 2*time.Second); !errors.Is(err, ErrDisallowedByRobots) {
+		t.Errorf("抓取禁止的路径: %v，期望 ErrDisallowedByRobots", err)
+	}
+	if robotsToken != "secret" {
+		t.Errorf("robots.txt 请求未使用抓取配置: X-Token=%q", robotsToken)
+	}
+	if metadata, err := s.ScrapeWebPageTimeout(srv.URL+"/public", 2*time.Second); err != nil || metadata.Title != "公开页面" {
+		t.Errorf("抓取允许的路径: %v", err)
+	}
+	if n := len(s.hosts.slots); n != 0 {
+		t.Errorf("抓取结束后仍有 %d 个主机名额", n)
+	}
+}
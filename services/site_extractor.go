@@ -0,0 +1,230 @@
+package services
+
+import (
+	neturl "net/url"
+	"strings"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SitePage 提供给特定网站提取器的页面
+type SitePage struct {
+	URL  *neturl.URL // 跟随重定向后的地址
+	Doc  *html.Node
+	Meta map[string][]string // <meta> 的 content，按 property/name/itemprop（小写）分组
+}
+
+// NewSitePage 从解析后的页面创建 SitePage
+func NewSitePage(pageURL *neturl.URL, doc *html.Node) *SitePage {
+	meta := map[string][]string{}
+	walkElements(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Meta {
+			key := strings.ToLower(firstNonEmpty(getAttr(n, "property"), getAttr(n, "name"), getAttr(n, "itemprop")))
+			if content := strings.TrimSpace(getAttr(n, "content")); key != "" && content != "" {
+				meta[key] = append(meta[key], content)
+			}
+		}
+		return true
+	})
+	return &SitePage{URL: pageURL, Doc: doc, Meta: meta}
+}
+
+// MetaValue 第一个同名 <meta> 的 content
+func (p *SitePage) MetaValue(key string) string {
+	if values := p.Meta[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// PathParts 地址路径按 / 拆分后的非空部分
+func (p *SitePage) PathParts() []string {
+	parts := []string{}
+	for _, part := range strings.Split(p.URL.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// SiteExtractor 特定网站的元数据提取器
+// Extract 覆盖或补充 metadata 中的字段，不适用于该页面（如非仓库页面）时不做修改
+type SiteExtractor interface {
+	Extract(page *SitePage, metadata *models.PageMetadata)
+}
+
+// SiteExtractorFunc 函数形式的 SiteExtractor
+type SiteExtractorFunc func(page *SitePage, metadata *models.PageMetadata)
+
+// Extract 调用函数本身
+func (f SiteExtractorFunc) Extract(page *SitePage, metadata *models.PageMetadata) {
+	f(page, metadata)
+}
+
+// siteExtractorEntry 注册的提取器及其主机名模式
+type siteExtractorEntry struct {
+	pattern   string
+	extractor SiteExtractor
+}
+
+// SiteExtractorRegistry 按主机名模式查找特定网站的提取器
+// 模式为完整主机名（github.com，同时匹配 www.github.com）或 *.域名（匹配该域名及其所有子域名）
+type SiteExtractorRegistry struct {
+	entries []siteExtractorEntry
+}
+
+// NewSiteExtractorRegistry 创建空的提取器注册表
+func NewSiteExtractorRegistry() *SiteExtractorRegistry {
+	return &SiteExtractorRegistry{}
+}
+
+// Register 注册提取器，先注册的优先匹配
+func (r *SiteExtractorRegistry) Register(pattern string, extractor SiteExtractor) {
+	r.entries = append(r.entries, siteExtractorEntry{pattern: strings.ToLower(pattern), extractor: extractor})
+}
+
+// Lookup 查找主机名对应的提取器，没有时返回 nil
+func (r *SiteExtractorRegistry) Lookup(host string) SiteExtractor {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for _, e := range r.entries {
+		if domain, ok := strings.CutPrefix(e.pattern, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return e.extractor
+			}
+		} else if host == strings.TrimPrefix(e.pattern, "www.") {
+			return e.extractor
+		}
+	}
+	return nil
+}
+
+// Apply 使用页面地址对应的提取器处理页面，返回是否找到提取器
+func (r *SiteExtractorRegistry) Apply(pageURL *neturl.URL, doc *html.Node, metadata *models.PageMetadata) bool {
+	extractor := r.Lookup(pageURL.Hostname())
+	if extractor == nil {
+		return false
+	}
+	if metadata.Details == nil {
+		metadata.Details = map[string]string{}
+	}
+	extractor.Extract(NewSitePage(pageURL, doc), metadata)
+	return true
+}
+
+// DefaultSiteExtractors 内置的特定网站提取器
+func DefaultSiteExtractors() *SiteExtractorRegistry {
+	r := NewSiteExtractorRegistry()
+	r.Register("github.com", SiteExtractorFunc(extractGitHub))
+	r.Register("youtube.com", SiteExtractorFunc(extractYouTube))
+	r.Register("m.youtube.com", SiteExtractorFunc(extractYouTube))
+	r.Register("youtu.be", SiteExtractorFunc(extractYouTube))
+	r.Register("arxiv.org", SiteExtractorFunc(extractArxiv))
+	r.Register("*.arxiv.org", SiteExtractorFunc(extractArxiv))
+	for _, host := range []string{"stackoverflow.com", "*.stackexchange.com", "superuser.com", "serverfault.com", "askubuntu.com", "mathoverflow.net"} {
+		r.Register(host, SiteExtractorFunc(extractStackExchange))
+	}
+	r.Register("*.bilibili.com", SiteExtractorFunc(extractBilibili))
+	r.Register("*.zhihu.com", SiteExtractorFunc(extractZhihu))
+	return r
+}
+
+// findElement 查找第一个满足条件的元素
+func findElement(n *html.Node, match func(*html.Node) bool) *html.Node {
+	var found *html.Node
+	walkElements(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if match(c) {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// findAllElements 查找所有满足条件的元素（不进入已匹配元素的子节点）
+func findAllElements(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	found := []*html.Node{}
+	walkElements(n, func(c *html.Node) bool {
+		if match(c) {
+			found = append(found, c)
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// byItemprop 按 itemprop 匹配元素
+func byItemprop(prop string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return hasToken(getAttr(n, "itemprop"), prop)
+	}
+}
+
+// byClass 按 class 匹配元素
+func byClass(class string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return hasToken(getAttr(n, "class"), class)
+	}
+}
+
+// byID 按 id 匹配元素
+func byID(id string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return getAttr(n, "id") == id
+	}
+}
+
+// elementTexts 元素文字列表（去重，忽略空文字）
+func elementTexts(nodes []*html.Node) []string {
+	seen := map[string]bool{}
+	texts := []string{}
+	for _, n := range nodes {
+		if text := collapseSpaces(textContent(n)); text != "" && !seen[text] {
+			seen[text] = true
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// addSiteTags 追加建议标签（去重，不区分大小写）
+func addSiteTags(metadata *models.PageMetadata, tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > 100 {
+			continue
+		}
+		exists := false
+		for _, t := range metadata.Tags {
+			if strings.EqualFold(t, tag) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			metadata.Tags = append(metadata.Tags, tag)
+		}
+	}
+}
+
+// splitKeywords 拆分 keywords（逗号或中文逗号分隔），最多返回 limit 个
+func splitKeywords(keywords string, limit int) []string {
+	words := []string{}
+	for _, w := range strings.FieldsFunc(keywords, func(r rune) bool { return r == ',' || r == '，' }) {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+			if len(words) >= limit {
+				break
+			}
+		}
+	}
+	return words
+}
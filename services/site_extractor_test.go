@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bytes"
+	neturl "net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+)
+
+func TestDefaultSiteExtractorsApply(t *testing.T) {
+	tests := []struct {
+		file            string
+		url             string
+		wantContentType string
+		wantAuthor      string
+		wantDetails     map[string]string
+		wantTags        []string
+	}{
+		{
+			file:            "github_repo.html",
+			url:             "https://github.com/golang/go",
+			wantContentType: models.ContentTypeRepo,
+			wantAuthor:      "golang",
+			wantDetails: map[string]string{
+				"repo":     "golang/go",
+				"language": "Go",
+				"stars":    "125,321",
+				"forks":    "17,654",
+				"topics":   "go, golang, programming-language, language",
+			},
+			wantTags: []string{"github", "go", "golang", "programming-language", "language"},
+		},
+		{
+			file:            "youtube_video.html",
+			url:             "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantContentType: models.ContentTypeVideo,
+			wantAuthor:      "Rick Astley",
+			wantDetails: map[string]string{
+				"channel":  "Rick Astley",
+				"duration": "3:33",
+				"views":    "1523456789",
+			},
+			wantTags: []string{"youtube", "视频"},
+		},
+		{
+			file:            "arxiv_abs.html",
+			url:             "https://arxiv.org/abs/1706.03762v7",
+			wantContentType: models.ContentTypePaper,
+			wantAuthor:      "Ashish Vaswani, Noam Shazeer, Niki Parmar",
+			wantDetails: map[string]string{
+				"arxiv_id":        "1706.03762",
+				"abstract":        "The dominant sequence transduction models are based on complex recurrent or convolutional neural networks in an encoder-decoder configuration. We propose a new simple network architecture, the Transformer, based solely on attention mechanisms.",
+				"primary_subject": "Computation and Language (cs.CL)",
+			},
+			wantTags: []string{"arxiv", "论文", "cs.CL"},
+		},
+		{
+			file:            "stackoverflow_question.html",
+			url:             "https://stackoverflow.com/questions/11227809/why-is-processing-a-sorted-array-faster-than-processing-an-unsorted-array",
+			wantContentType: models.ContentTypeArticle,
+			wantDetails: map[string]string{
+				"accepted_answer": "true",
+				"answers":         "26",
+				"score":           "27250",
+			},
+			wantTags: []string{"stackoverflow", "java", "c++", "performance", "cpu-architecture", "branch-prediction"},
+		},
+		{
+			file:            "bilibili_video.html",
+			url:             "https://www.bilibili.com/video/BV1GJ411x7h7/",
+			wantContentType: models.ContentTypeVideo,
+			wantAuthor:      "索尼音乐中国",
+			wantDetails: map[string]string{
+				"channel":  "索尼音乐中国",
+				"duration": "3:33",
+				"views":    "12345678",
+				"category": "音乐综合",
+			},
+			wantTags: []string{"bilibili", "视频", "音乐综合", "音乐", "欧美", "MV"},
+		},
+		{
+			file:            "zhihu_question.html",
+			url:             "https://www.zhihu.com/question/19550225",
+			wantContentType: models.ContentTypeArticle,
+			wantDetails: map[string]string{
+				"answers":   "2856",
+				"followers": "183521",
+				"topics":    "编程, 程序员, 计算机, 编程学习",
+			},
+			wantTags: []string{"知乎", "编程", "程序员", "计算机", "编程学习"},
+		},
+	}
+
+	registry := DefaultSiteExtractors()
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			doc, err := html.Parse(bytes.NewReader(loadTestPage(t, filepath.Join("sites", tc.file))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pageURL, _ := neturl.Parse(tc.url)
+
+			var metadata models.PageMetadata
+			if !registry.Apply(pageURL, doc, &metadata) {
+				t.Fatalf("没有找到 %s 的提取器", pageURL.Host)
+			}
+			if metadata.ContentType != tc.wantContentType {
+				t.Errorf("ContentType = %q，期望 %q", metadata.ContentType, tc.wantContentType)
+			}
+			if metadata.Author != tc.wantAuthor {
+				t.Errorf("Author = %q，期望 %q", metadata.Author, tc.wantAuthor)
+			}
+			if !reflect.DeepEqual(metadata.Details, tc.wantDetails) {
+				t.Errorf("Details = %v，期望 %v", metadata.Details, tc.wantDetails)
+			}
+			if !reflect.DeepEqual(metadata.Tags, tc.wantTags) {
+				t.Errorf("Tags = %q，期望 %q", metadata.Tags, tc.wantTags)
+			}
+		})
+	}
+}
+
+func TestSiteExtractorsSkipNonContentPages(t *testing.T) {
+	doc, err := html.Parse(bytes.NewReader(loadTestPage(t, filepath.Join("sites", "github_repo.html"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := DefaultSiteExtractors()
+	for _, rawURL := range []string{
+		"https://github.com/trending",
+		"https://www.youtube.com/@RickAstleyYT",
+		"https://arxiv.org/list/cs.CL/recent",
+		"https://stackoverflow.com/questions/tagged/java",
+		"https://space.bilibili.com/1234567",
+		"https://www.zhihu.com/people/someone",
+	} {
+		pageURL, _ := neturl.Parse(rawURL)
+		var metadata models.PageMetadata
+		registry.Apply(pageURL, doc, &metadata)
+		if metadata.ContentType != "" || len(metadata.Tags) > 0 || len(metadata.Details) > 0 {
+			t.Errorf("%s 不应被提取: %q %v %v", rawURL, metadata.ContentType, metadata.Tags, metadata.Details)
+		}
+	}
+}
+
+// namedExtractor 只用于区分查找结果的提取器
+type namedExtractor string
+
+func (namedExtractor) Extract(*SitePage, *models.PageMetadata) {}
+
+func TestSiteExtractorRegistryLookup(t *testing.T) {
+	registry := NewSiteExtractorRegistry()
+	registry.Register("example.com", namedExtractor("exact"))
+	registry.Register("*.example.org", namedExtractor("wildcard"))
+	registry.Register("www.example.net", namedExtractor("www"))
+	registry.Register("*.example.com", namedExtractor("later"))
+
+	tests := []struct {
+		host string
+		want SiteExtractor // nil 表示没有匹配
+	}{
+		{"example.com", namedExtractor("exact")},
+		{"www.example.com", namedExtractor("exact")},
+		{"EXAMPLE.COM", namedExtractor("exact")},
+		{"blog.example.com", namedExtractor("later")},
+		{"notexample.com", nil},
+		{"example.org", namedExtractor("wildcard")},
+		{"www.example.org", namedExtractor("wildcard")},
+		{"zhuanlan.example.org", namedExtractor("wildcard")},
+		{"a.b.example.org", namedExtractor("wildcard")},
+		{"badexample.org", nil},
+		{"example.org.evil.com", nil},
+		{"example.net", namedExtractor("www")},
+		{"www.example.net", namedExtractor("www")},
+		{"cdn.example.net", nil},
+	}
+	for _, tc := range tests {
+		if got := registry.Lookup(tc.host); got != tc.want {
+			t.Errorf("Lookup(%q) = %v，期望 %v", tc.host, got, tc.want)
+		}
+	}
+
+	// 内置提取器的子域名和 www. 前缀
+	defaults := DefaultSiteExtractors()
+	for _, host := range []string{"www.github.com", "m.youtube.com", "export.arxiv.org", "math.stackexchange.com", "www.bilibili.com", "bilibili.com", "zhuanlan.zhihu.com"} {
+		if defaults.Lookup(host) == nil {
+			t.Errorf("内置提取器没有匹配 %s", host)
+		}
+	}
+	for _, host := range []string{"gist.github.com", "stackexchange.com.cn", "notzhihu.com"} {
+		if defaults.Lookup(host) != nil {
+			t.Errorf("内置提取器不应匹配 %s", host)
+		}
+	}
+}
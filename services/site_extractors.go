@@ -0,0 +1,344 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// siteTagLimit 从页面关键词/话题中取的默认标签数量上限
+const siteTagLimit = 5
+
+// githubReservedPaths GitHub 上不是用户名的一级路径
+var githubReservedPaths = map[string]bool{
+	"about": true, "apps": true, "collections": true, "explore": true, "features": true, "login": true,
+	"marketplace": true, "notifications": true, "orgs": true, "pricing": true, "search": true,
+	"settings": true, "signup": true, "sponsors": true, "topics": true, "trending": true,
+}
+
+// githubDescriptionSuffix GitHub 页面描述末尾的固定文字
+var githubDescriptionSuffix = regexp.MustCompile(`\s*(-\s*[\w.-]+/[\w.-]+|Contribute to [\w.-]+/[\w.-]+ development by creating an account on GitHub\.)\s*$`)
+
+// extractGitHub GitHub 仓库: 所有者、描述、主要语言、星标数、Fork 数和 topics
+func extractGitHub(p *SitePage, m *models.PageMetadata) {
+	parts := p.PathParts()
+	if len(parts) < 2 || githubReservedPaths[parts[0]] {
+		return
+	}
+	repo := parts[0] + "/" + parts[1]
+	m.Author = parts[0]
+	m.SiteName = "GitHub"
+	m.Details["repo"] = repo
+	// issue、PR 和讨论页面只记录所属仓库
+	if len(parts) > 2 && (parts[2] == "issues" || parts[2] == "pull" || parts[2] == "discussions") {
+		addSiteTags(m, "github")
+		return
+	}
+	m.ContentType = models.ContentTypeRepo
+
+	if desc := githubDescriptionSuffix.ReplaceAllString(firstNonEmpty(p.MetaValue("og:description"), p.MetaValue("description")), ""); desc != "" {
+		m.OGDesc = desc
+	}
+
+	language := ""
+	if n := findElement(p.Doc, byItemprop("programmingLanguage")); n != nil {
+		language = collapseSpaces(textContent(n))
+	} else if n := findElement(p.Doc, byClass("Progress-item")); n != nil {
+		// 语言比例条: aria-label="Go 95.3"
+		label := getAttr(n, "aria-label")
+		if i := strings.LastIndex(label, " "); i > 0 {
+			language = label[:i]
+		}
+	}
+	if language != "" {
+		m.Details["language"] = language
+	}
+	if n := findElement(p.Doc, byID("repo-stars-counter-star")); n != nil {
+		m.Details["stars"] = firstNonEmpty(getAttr(n, "title"), collapseSpaces(textContent(n)))
+	}
+	if n := findElement(p.Doc, byID("repo-network-counter")); n != nil {
+		m.Details["forks"] = firstNonEmpty(getAttr(n, "title"), collapseSpaces(textContent(n)))
+	}
+
+	topics := elementTexts(findAllElements(p.Doc, byClass("topic-tag")))
+	if len(topics) > siteTagLimit {
+		topics = topics[:siteTagLimit]
+	}
+	if len(topics) > 0 {
+		m.Details["topics"] = strings.Join(topics, ", ")
+	}
+	addSiteTags(m, "github", strings.ToLower(language))
+	addSiteTags(m, topics...)
+}
+
+// extractYouTube YouTube 视频: 频道、时长、发布时间
+func extractYouTube(p *SitePage, m *models.PageMetadata) {
+	if p.URL.Host != "youtu.be" && !strings.HasPrefix(p.URL.Path, "/watch") && !strings.HasPrefix(p.URL.Path, "/shorts/") {
+		return
+	}
+	m.ContentType = models.ContentTypeVideo
+	m.SiteName = "YouTube"
+
+	if n := findElement(p.Doc, byItemprop("author")); n != nil {
+		if channel := microdataName(n); channel != "" {
+			m.Author = channel
+			m.Details["channel"] = channel
+		}
+	}
+	if seconds := parseISODurationSeconds(p.MetaValue("duration")); seconds > 0 {
+		m.Details["duration"] = formatDuration(int(seconds))
+	}
+	if t := parseMetadataDate(firstNonEmpty(p.MetaValue("datepublished"), p.MetaValue("uploaddate"))); t != nil {
+		m.PublishedAt = t
+	}
+	if views := p.MetaValue("interactioncount"); views != "" {
+		m.Details["views"] = views
+	}
+	addSiteTags(m, "youtube", "视频")
+}
+
+// arxivIDPattern arXiv 论文编号（不含版本号），如 1706.03762 或 cs/0112017
+var arxivIDPattern = regexp.MustCompile(`^/(?:abs|pdf)/(\d{4}\.\d{4,5}|[a-z-]+(?:\.[A-Z]{2})?/\d{7})(v\d+)?(?:\.pdf)?/?$`)
+
+// arxivSubjectCode 学科名称末尾括号中的代码，如 Machine Learning (cs.LG)
+var arxivSubjectCode = regexp.MustCompile(`\(([\w-]+\.[\w-]+|[\w-]+)\)\s*$`)
+
+// extractArxiv arXiv 论文: 作者、摘要、提交时间、主学科
+func extractArxiv(p *SitePage, m *models.PageMetadata) {
+	match := arxivIDPattern.FindStringSubmatch(p.URL.Path)
+	if match == nil {
+		return
+	}
+	m.ContentType = models.ContentTypePaper
+	m.SiteName = "arXiv"
+	m.Details["arxiv_id"] = match[1]
+
+	if title := p.MetaValue("citation_title"); title != "" {
+		m.OGTitle = title
+	}
+	// citation_author 为 "姓, 名" 形式，转换为 "名 姓"
+	authors := []string{}
+	for _, a := range p.Meta["citation_author"] {
+		if last, first, ok := strings.Cut(a, ","); ok {
+			a = strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+		}
+		authors = append(authors, a)
+	}
+	if len(authors) > 0 {
+		m.Author = strings.Join(authors, ", ")
+	}
+	if t := parseMetadataDate(firstNonEmpty(p.MetaValue("citation_date"), p.MetaValue("citation_online_date"))); t != nil {
+		m.PublishedAt = t
+	}
+
+	abstract := p.MetaValue("citation_abstract")
+	if abstract == "" {
+		if n := findElement(p.Doc, byClass("abstract")); n != nil {
+			abstract = strings.TrimPrefix(collapseSpaces(textContent(n)), "Abstract:")
+		}
+	}
+	if abstract = collapseSpaces(abstract); abstract != "" {
+		m.OGDesc = abstract
+		m.Details["abstract"] = abstract
+	}
+
+	tags := []string{"arxiv", "论文"}
+	if n := findElement(p.Doc, byClass("primary-subject")); n != nil {
+		subject := collapseSpaces(textContent(n))
+		m.Details["primary_subject"] = subject
+		if code := arxivSubjectCode.FindStringSubmatch(subject); code != nil {
+			tags = append(tags, code[1])
+		}
+	}
+	addSiteTags(m, tags...)
+}
+
+// extractStackExchange Stack Overflow 及 Stack Exchange 问题: 问题标签、回答数、是否有采纳的回答、得分
+func extractStackExchange(p *SitePage, m *models.PageMetadata) {
+	parts := p.PathParts()
+	if len(parts) < 2 || parts[0] != "questions" {
+		return
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return
+	}
+	m.ContentType = models.ContentTypeArticle
+
+	question := findElement(p.Doc, byID("question"))
+	if question == nil {
+		question = p.Doc
+	}
+	tags := elementTexts(findAllElements(question, byClass("post-tag")))
+
+	accepted := findElement(p.Doc, func(n *html.Node) bool {
+		return hasToken(getAttr(n, "itemprop"), "acceptedAnswer") || hasToken(getAttr(n, "class"), "accepted-answer")
+	}) != nil
+	m.Details["accepted_answer"] = strconv.FormatBool(accepted)
+
+	if n := findElement(p.Doc, byItemprop("answerCount")); n != nil {
+		m.Details["answers"] = microdataValue(n)
+	} else if n := findElement(p.Doc, func(n *html.Node) bool { return getAttr(n, "data-answercount") != "" }); n != nil {
+		m.Details["answers"] = getAttr(n, "data-answercount")
+	}
+	if n := findElement(question, func(n *html.Node) bool {
+		return hasToken(getAttr(n, "class"), "js-vote-count") || hasToken(getAttr(n, "itemprop"), "upvoteCount")
+	}); n != nil {
+		m.Details["score"] = firstNonEmpty(getAttr(n, "data-value"), microdataValue(n))
+	}
+	if t := parseMetadataDate(getAttr(findElementOrEmpty(question, byItemprop("dateCreated")), "datetime")); t != nil {
+		m.PublishedAt = t
+	}
+
+	site := strings.TrimSuffix(strings.TrimPrefix(p.URL.Hostname(), "www."), ".com")
+	addSiteTags(m, strings.ReplaceAll(site, ".", "-"))
+	if len(tags) > siteTagLimit {
+		tags = tags[:siteTagLimit]
+	}
+	addSiteTags(m, tags...)
+}
+
+// bilibiliState 哔哩哔哩视频页 window.__INITIAL_STATE__ 中用到的字段
+type bilibiliState struct {
+	VideoData struct {
+		Duration int    `json:"duration"` // 秒
+		PubDate  int64  `json:"pubdate"`  // Unix 时间戳
+		TName    string `json:"tname"`    // 分区
+		Owner    struct {
+			Name string `json:"name"`
+		} `json:"owner"`
+		Stat struct {
+			View int `json:"view"`
+			Like int `json:"like"`
+		} `json:"stat"`
+	} `json:"videoData"`
+	Tags []struct {
+		TagName string `json:"tag_name"`
+	} `json:"tags"`
+}
+
+// extractBilibili 哔哩哔哩视频: UP 主、时长、分区、播放量和视频标签
+func extractBilibili(p *SitePage, m *models.PageMetadata) {
+	if !strings.HasPrefix(p.URL.Path, "/video/") {
+		return
+	}
+	m.ContentType = models.ContentTypeVideo
+	m.SiteName = "哔哩哔哩"
+
+	var state bilibiliState
+	walkElements(p.Doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.Script {
+			return true
+		}
+		text := textContent(n)
+		if i := strings.Index(text, "window.__INITIAL_STATE__="); i >= 0 {
+			// 脚本形如 window.__INITIAL_STATE__={...};(function(){...})()，只解码第一个 JSON 值
+			json.NewDecoder(strings.NewReader(text[i+len("window.__INITIAL_STATE__="):])).Decode(&state)
+		}
+		return false
+	})
+
+	video := state.VideoData
+	if uploader := firstNonEmpty(video.Owner.Name, p.MetaValue("author")); uploader != "" {
+		m.Author = uploader
+		m.Details["channel"] = uploader
+	}
+	if video.Duration > 0 {
+		m.Details["duration"] = formatDuration(video.Duration)
+	}
+	if video.PubDate > 0 {
+		t := time.Unix(video.PubDate, 0).UTC()
+		m.PublishedAt = &t
+	} else if t := parseMetadataDate(p.MetaValue("uploaddate")); t != nil {
+		m.PublishedAt = t
+	}
+	if video.Stat.View > 0 {
+		m.Details["views"] = strconv.Itoa(video.Stat.View)
+	}
+	if video.TName != "" {
+		m.Details["category"] = video.TName
+	}
+
+	tags := []string{}
+	for _, t := range state.Tags {
+		tags = append(tags, t.TagName)
+	}
+	if len(tags) == 0 {
+		// 没有初始数据时使用 keywords，第一个通常是视频标题，末尾是站点名
+		keywords := splitKeywords(p.MetaValue("keywords"), siteTagLimit+2)
+		for i, k := range keywords {
+			if i > 0 && !strings.Contains(k, "哔哩哔哩") && !strings.Contains(strings.ToLower(k), "bilibili") {
+				tags = append(tags, k)
+			}
+		}
+	}
+	if len(tags) > siteTagLimit {
+		tags = tags[:siteTagLimit]
+	}
+	addSiteTags(m, "bilibili", "视频", video.TName)
+	addSiteTags(m, tags...)
+}
+
+// extractZhihu 知乎问题和专栏文章: 话题、回答数、关注数、作者
+func extractZhihu(p *SitePage, m *models.PageMetadata) {
+	parts := p.PathParts()
+	isQuestion := len(parts) >= 2 && parts[0] == "question"
+	isArticle := strings.HasPrefix(p.URL.Hostname(), "zhuanlan.") && len(parts) >= 2 && parts[0] == "p"
+	if !isQuestion && !isArticle {
+		return
+	}
+	m.ContentType = models.ContentTypeArticle
+	m.SiteName = "知乎"
+
+	if isQuestion {
+		if answers := p.MetaValue("answercount"); answers != "" {
+			m.Details["answers"] = answers
+		}
+		if followers := p.MetaValue("zhihu:followercount"); followers != "" {
+			m.Details["followers"] = followers
+		}
+		if t := parseMetadataDate(p.MetaValue("datecreated")); t != nil {
+			m.PublishedAt = t
+		}
+	} else {
+		if n := findElement(p.Doc, byItemprop("author")); n != nil {
+			if author := microdataName(n); author != "" {
+				m.Author = author
+			}
+		}
+		if t := parseMetadataDate(firstNonEmpty(p.MetaValue("datepublished"), p.MetaValue("article:published_time"))); t != nil {
+			m.PublishedAt = t
+		}
+	}
+
+	topics := splitKeywords(p.MetaValue("keywords"), siteTagLimit)
+	if len(topics) > 0 {
+		m.Details["topics"] = strings.Join(topics, ", ")
+	}
+	addSiteTags(m, "知乎")
+	addSiteTags(m, topics...)
+}
+
+// findElementOrEmpty 查找元素，找不到时返回空元素（便于直接读取属性）
+func findElementOrEmpty(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if found := findElement(n, match); found != nil {
+		return found
+	}
+	return &html.Node{Type: html.ElementNode}
+}
+
+// formatDuration 将秒数格式化为 m:ss 或 h:mm:ss
+func formatDuration(seconds int) string {
+	h, m, s := seconds/3600, seconds%3600/60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
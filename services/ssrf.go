@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ErrBlockedAddress 目标地址指向本机、内网或其他保留地址
+var ErrBlockedAddress = errors.New("不允许访问本机或内网地址")
+
+// ErrBodyTooLarge 响应体超过抓取服务允许的最大大小
+var ErrBodyTooLarge = errors.New("响应内容超过最大允许大小")
+
+// blockedPrefixes 禁止访问的地址段（除 IsPrivate/IsLoopback 等已覆盖的范围外）
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),   // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF 协议分配
+	netip.MustParsePrefix("192.0.2.0/24"),    // 文档示例
+	netip.MustParsePrefix("198.18.0.0/15"),   // 基准测试
+	netip.MustParsePrefix("198.51.100.0/24"), // 文档示例
+	netip.MustParsePrefix("203.0.113.0/24"),  // 文档示例
+	netip.MustParsePrefix("240.0.0.0/4"),     // 保留及广播
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64，可映射到任意 IPv4 地址
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // 本地 NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // 文档示例
+}
+
+// isBlockedIP 是否为本机、内网、链路本地（含云服务元数据地址 169.254.169.254）或其他保留地址
+func isBlockedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ssrfGuard 限制抓取服务只能访问公网地址，白名单中的主机和地址段除外
+type ssrfGuard struct {
+	hosts    []string       // 允许的主机名，*.域名 匹配该域名及其子域名
+	prefixes []netip.Prefix // 允许的 IP 地址段
+	dialer   *net.Dialer
+	resolver ipResolver
+}
+
+// ipResolver 解析主机名的地址，*net.Resolver 实现了该接口
+type ipResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// newSSRFGuard 创建地址检查器，allowlist 为主机名、*.域名、IP 或 CIDR 地址段
+func newSSRFGuard(allowlist []string) *ssrfGuard {
+	g := &ssrfGuard{
+		dialer:   &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+		resolver: net.DefaultResolver,
+	}
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.prefixes = append(g.prefixes, prefix.Masked())
+		} else if ip, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil {
+			g.prefixes = append(g.prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+		} else {
+			g.hosts = append(g.hosts, entry)
+		}
+	}
+	return g
+}
+
+// hostAllowed 主机名是否在白名单中
+func (g *ssrfGuard) hostAllowed(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, h := range g.hosts {
+		if domain, ok := strings.CutPrefix(h, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// ipAllowed 地址是否可以访问: 公网地址，或在白名单地址段中
+func (g *ssrfGuard) ipAllowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range g.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return !isBlockedIP(ip)
+}
+
+// resolve 解析主机名并检查所有地址，任意一个地址被禁止时返回 ErrBlockedAddress
+// 白名单中的主机名返回 nil 地址列表，由调用方按原主机名连接
+func (g *ssrfGuard) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if g.hostAllowed(host) {
+		return nil, nil
+	}
+	if ip, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		if !g.ipAllowed(ip) {
+			return nil, fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return []netip.Addr{ip}, nil
+	}
+
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("无法解析主机: %s", host)
+	}
+	for _, ip := range addrs {
+		if !g.ipAllowed(ip) {
+			return nil, fmt.Errorf("%w: %s 解析为 %s", ErrBlockedAddress, host, ip)
+		}
+	}
+	return addrs, nil
+}
+
+// CheckURL 检查请求地址: 只允许 http/https，且主机解析后的地址可以访问
+func (g *ssrfGuard) CheckURL(ctx context.Context, u *neturl.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("不支持的协议: %s", u.Scheme)
+	}
+	_, err := g.resolve(ctx, u.Hostname())
+	return err
+}
+
+// DialContext 解析后只连接检查通过的地址，避免 DNS 在检查和连接之间被改为内网地址
+func (g *ssrfGuard) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if addrs == nil {
+		return g.dialer.DialContext(ctx, network, addr)
+	}
+
+	var lastErr error
+	for _, ip := range addrs {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// limitedBody 读取超过 limit 字节时返回 ErrBodyTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// 恰好读完 limit 字节时确认是否还有剩余内容
+		var one [1]byte
+		if _, err := io.ReadFull(b.ReadCloser, one[:]); err == nil {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubResolver 按主机名返回固定地址，替代 DNS 解析
+type stubResolver map[string][]netip.Addr
+
+func (r stubResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, fmt.Errorf("无法解析主机: %s", host)
+	}
+	return addrs, nil
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1":            true,
+		"127.1.2.3":            true,
+		"::1":                  true,
+		"0.0.0.0":              true,
+		"::":                   true,
+		"10.0.0.1":             true,
+		"172.16.0.1":           true,
+		"172.31.255.255":       true,
+		"172.32.0.1":           false,
+		"192.168.1.1":          true,
+		"169.254.169.254":      true, // 云服务元数据地址
+		"fe80::1":              true,
+		"fc00::1":              true,
+		"100.64.0.1":           true, // 运营商级 NAT
+		"100.127.255.255":      true,
+		"100.128.0.1":          false,
+		"::ffff:127.0.0.1":     true, // IPv4 映射的 IPv6 地址
+		"::ffff:10.0.0.1":      true,
+		"::ffff:169.254.1.1":   true,
+		"::ffff:8.8.8.8":       false,
+		"64:ff9b::a9fe:a9fe":   true, // NAT64 映射的 169.254.169.254
+		"64:ff9b::808:808":     true,
+		"64:ff9b:1::1":         true,
+		"224.0.0.1":            true,
+		"255.255.255.255":      true,
+		"192.0.2.1":            true,
+		"2001:db8::1":          true,
+		"8.8.8.8":              false,
+		"93.184.216.34":        false,
+		"2606:4700:4700::1111": false,
+	}
+	for addr, want := range tests {
+		if got := isBlockedIP(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isBlockedIP(%s) = %v，期望 %v", addr, got, want)
+		}
+	}
+}
+
+func TestSSRFGuardAllowlist(t *testing.T) {
+	g := newSSRFGuard([]string{" 10.1.2.3/16 ", "192.168.1.5", "[fd00::1]", "*.Corp.Example", "intranet", ""})
+
+	ips := map[string]bool{
+		"10.1.0.1":           true,
+		"10.1.255.255":       true,
+		"10.2.0.1":           false,
+		"192.168.1.5":        true,
+		"::ffff:192.168.1.5": true,
+		"192.168.1.6":        false,
+		"fd00::1":            true,
+		"fd00::2":            false,
+		"127.0.0.1":          false,
+		"8.8.8.8":            true,
+	}
+	for addr, want := range ips {
+		if got := g.ipAllowed(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("ipAllowed(%s) = %v，期望 %v", addr, got, want)
+		}
+	}
+
+	hosts := map[string]bool{
+		"corp.example":       true,
+		"wiki.corp.example":  true,
+		"WIKI.Corp.Example.": true,
+		"a.b.corp.example":   true,
+		"evilcorp.example":   false,
+		"corp.example.evil":  false,
+		"intranet":           true,
+		"wiki.intranet":      false,
+		"":                   false,
+	}
+	for host, want := range hosts {
+		if got := g.hostAllowed(host); got != want {
+			t.Errorf("hostAllowed(%q) = %v，期望 %v", host, got, want)
+		}
+	}
+}
+
+func TestSSRFGuardResolve(t *testing.T) {
+	g := newSSRFGuard([]string{"*.corp.example"})
+	g.resolver = stubResolver{
+		"public.example":    {netip.MustParseAddr("93.184.216.34")},
+		"intranet.example":  {netip.MustParseAddr("10.0.0.5")},
+		"mixed.example":     {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("192.168.0.1")},
+		"mapped.example":    {netip.MustParseAddr("::ffff:127.0.0.1")},
+		"wiki.corp.example": {netip.MustParseAddr("10.0.0.6")},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://public.example/page", false},
+		{"https://intranet.example/", true},
+		{"https://mixed.example/", true}, // 任意一个地址被禁止即拒绝
+		{"https://mapped.example/", true},
+		{"https://wiki.corp.example/", false}, // 白名单中的主机名不检查解析结果
+		{"http://127.0.0.1:8080/", true},
+		{"http://[::1]/", true},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://8.8.8.8/", false},
+	}
+	for _, tc := range tests {
+		u, _ := neturl.Parse(tc.url)
+		err := g.CheckURL(ctx, u)
+		if blocked := errors.Is(err, ErrBlockedAddress); blocked != tc.blocked || (!tc.blocked && err != nil) {
+			t.Errorf("CheckURL(%s) = %v，期望 blocked=%v", tc.url, err, tc.blocked)
+		}
+	}
+
+	for _, raw := range []string{"file:///etc/passwd", "ftp://public.example/", "gopher://public.example/"} {
+		u, _ := neturl.Parse(raw)
+		if err := g.CheckURL(ctx, u); err == nil || !strings.Contains(err.Error(), "不支持的协议") {
+			t.Errorf("CheckURL(%s) = %v，期望不支持的协议", raw, err)
+		}
+	}
+
+	u, _ := neturl.Parse("https://unknown.example/")
+	if err := g.CheckURL(ctx, u); err == nil || errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("无法解析的主机 = %v，期望解析错误", err)
+	}
+
+	// 连接时同样检查解析结果，不会连接到内网地址
+	if conn, err := g.DialContext(ctx, "tcp", "intranet.example:80"); !errors.Is(err, ErrBlockedAddress) {
+		if conn != nil {
+			conn.Close()
+		}
+		t.Errorf("DialContext = %v，期望 ErrBlockedAddress", err)
+	}
+}
+
+func TestRedirectToBlockedAddress(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	}))
+	defer srv.Close()
+	_, port, _ := strings.Cut(srv.Listener.Addr().String(), ":")
+
+	// public.example 解析为测试服务器的地址（127.0.0.1 在白名单中），代表可以访问的公网网站
+	s := newTestScraper(2)
+	s.guard.resolver = stubResolver{
+		"public.example":   {netip.MustParseAddr("127.0.0.1")},
+		"metadata.example": {netip.MustParseAddr("169.254.169.254")},
+	}
+	start := "http://public.example:" + port + "/?to="
+
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://metadata.example/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://[::ffff:192.168.0.1]/",
+	} {
+		req, err := s.newRequest(context.Background(), "GET", start+neturl.QueryEscape(target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := s.do(req, false)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("重定向到 %s: %v，期望 ErrBlockedAddress", target, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 4 {
+		t.Errorf("起始地址被请求 %d 次，期望 4 次", n)
+	}
+}
+
+func TestMaxRedirects(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		max, redirects int
+		ok             bool
+	}{
+		{3, 3, true},
+		{3, 4, false},
+		{0, 0, true},
+		{0, 1, false},
+	} {
+		cfg := newTestConfig(2)
+		cfg.ScraperMaxRedirects = tc.max
+		s := NewScraperService(cfg)
+		atomic.StoreInt32(&hits, 0)
+
+		status, _, err := s.CheckLink(srv.URL + "/" + strconv.Itoa(tc.redirects))
+		if tc.ok {
+			if err != nil || status != http.StatusOK {
+				t.Errorf("最多 %d 次，重定向 %d 次: %d, %v", tc.max, tc.redirects, status, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("重定向次数超过 %d 次", tc.max)) {
+			t.Errorf("最多 %d 次，重定向 %d 次: %v，期望超过重定向次数", tc.max, tc.redirects, err)
+		}
+		// 超过次数后不再请求下一个地址
+		if n := atomic.LoadInt32(&hits); n != int32(tc.max+1) {
+			t.Errorf("最多 %d 次: 请求了 %d 次，期望 %d 次", tc.max, n, tc.max+1)
+		}
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	const limit = 16
+	for _, tc := range []struct {
+		size int
+		err  error
+	}{
+		{0, nil},
+		{limit - 1, nil},
+		{limit, nil},
+		{limit + 1, ErrBodyTooLarge},
+		{limit * 4, ErrBodyTooLarge},
+	} {
+		body := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader(strings.Repeat("a", tc.size))), remaining: limit}
+		data, err := io.ReadAll(body)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%d 字节: err = %v，期望 %v", tc.size, err, tc.err)
+		}
+		if tc.err == nil && len(data) != tc.size {
+			t.Errorf("%d 字节: 读取了 %d 字节", tc.size, len(data))
+		}
+		if len(data) > limit {
+			t.Errorf("%d 字节: 读取了 %d 字节，超过上限", tc.size, len(data))
+		}
+	}
+
+	// 经抓取服务请求: 没有 Content-Length 时读取超过上限返回错误，声明的 Content-Length 超过上限时直接拒绝
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, strings.Repeat("a", n))
+	}))
+	defer srv.Close()
+	s := newTestScraper(2)
+	s.maxBody = limit
+
+	for _, tc := range []struct {
+		query string
+		err   error
+	}{
+		{fmt.Sprintf("n=%d&chunked=1", limit), nil},
+		{fmt.Sprintf("n=%d&chunked=1", limit+1), ErrBodyTooLarge},
+		{fmt.Sprintf("n=%d", limit), nil},
+		{fmt.Sprintf("n=%d", limit+1), ErrBodyTooLarge},
+	} {
+		req, err := s.newRequest(context.Background(), "GET", srv.URL+"/?"+tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := s.do(req, false)
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v，期望 %v", tc.query, err, tc.err)
+		}
+	}
+}
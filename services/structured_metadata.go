@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"mime"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-bookmark-service/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// readingWordsPerMinute 估算阅读时间的速度（中文约 300-500 字/分钟，英文约 200-250 词/分钟）
+const readingWordsPerMinute = 300
+
+// readingMinWords 正文少于该字数时不估算阅读时间（通常不是文章页）
+const readingMinWords = 50
+
+// oembedMaxSize oEmbed 响应的最大字节数
+const oembedMaxSize = 256 * 1024
+
+// schemaContentTypes schema.org 类型对应的内容类型
+var schemaContentTypes = map[string]string{
+	"article":                models.ContentTypeArticle,
+	"newsarticle":            models.ContentTypeArticle,
+	"blogposting":            models.ContentTypeArticle,
+	"techarticle":            models.ContentTypeArticle,
+	"report":                 models.ContentTypeArticle,
+	"socialmediaposting":     models.ContentTypeArticle,
+	"discussionforumposting": models.ContentTypeArticle,
+	"scholarlyarticle":       models.ContentTypePaper,
+	"videoobject":            models.ContentTypeVideo,
+	"movie":                  models.ContentTypeVideo,
+	"tvepisode":              models.ContentTypeVideo,
+	"clip":                   models.ContentTypeVideo,
+	"audioobject":            models.ContentTypeAudio,
+	"podcastepisode":         models.ContentTypeAudio,
+	"musicrecording":         models.ContentTypeAudio,
+	"imageobject":            models.ContentTypeImage,
+	"photograph":             models.ContentTypeImage,
+	"softwaresourcecode":     models.ContentTypeRepo,
+	"book":                   models.ContentTypeBook,
+	"product":                models.ContentTypeProduct,
+}
+
+// publishedMetaNames 表示发布时间的 <meta name/property>，按优先级排列
+var publishedMetaNames = []string{
+	"article:published_time", "citation_publication_date", "citation_date",
+	"og:video:release_date", "pubdate", "publishdate", "publish_date", "date", "dc.date", "dcterms.created",
+}
+
+// metadataDateLayouts 发布时间支持的格式
+var metadataDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"2006/1/2",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// isoDurationPattern ISO 8601 时长，如 PT1H5M、P0DT12M30S
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// extractStructuredMetadata 从 JSON-LD、微数据、OpenGraph/meta 标签和 <html lang> 提取结构化元数据
+// 优先级依次降低，只填充 metadata 中仍为空的字段；会修改 doc（估算阅读时间时提取正文）
+func (s *ScraperService) extractStructuredMetadata(ctx context.Context, doc *html.Node, base *neturl.URL, metadata *models.PageMetadata) {
+	var oembedURL string
+	var wordCount int
+	meta := map[string][]string{}
+	lang := ""
+	walkElements(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Html:
+			lang = getAttr(n, "lang")
+		case atom.Script:
+			if strings.EqualFold(strings.TrimSpace(getAttr(n, "type")), "application/ld+json") {
+				applyJSONLD(textContent(n), metadata, &wordCount)
+			}
+			return false
+		case atom.Meta:
+			key := strings.ToLower(firstNonEmpty(getAttr(n, "property"), getAttr(n, "name"), getAttr(n, "itemprop"), getAttr(n, "http-equiv")))
+			if content := strings.TrimSpace(getAttr(n, "content")); key != "" && content != "" {
+				meta[key] = append(meta[key], content)
+			}
+		case atom.Link:
+			rel := strings.ToLower(getAttr(n, "rel"))
+			switch {
+			case hasToken(rel, "canonical") && metadata.CanonicalURL == "":
+				metadata.CanonicalURL = resolveURL(base, getAttr(n, "href"))
+			case hasToken(rel, "alternate") && strings.EqualFold(getAttr(n, "type"), "application/json+oembed") && oembedURL == "":
+				oembedURL = resolveURL(base, getAttr(n, "href"))
+			}
+		}
+		return true
+	})
+
+	applyMicrodata(doc, metadata)
+	applyMetaTags(meta, base, metadata)
+	if metadata.Language == "" {
+		metadata.Language = normalizeLanguage(lang)
+	}
+
+	if oembedURL != "" {
+		s.applyOEmbed(ctx, oembedURL, metadata)
+	}
+
+	// 阅读时间: JSON-LD 的 timeRequired/wordCount，其次按正文字数估算（仅文章类页面）
+	if metadata.ReadingTime == 0 && wordCount > 0 {
+		metadata.ReadingTime = readingMinutes(wordCount)
+	}
+	if metadata.ReadingTime == 0 && (metadata.ContentType == "" || metadata.ContentType == models.ContentTypeArticle || metadata.ContentType == models.ContentTypePaper) {
+		if words := extractArticle(doc, base).WordCount; words >= readingMinWords {
+			metadata.ReadingTime = readingMinutes(words)
+		}
+	}
+}
+
+// applyJSONLD 解析 JSON-LD 脚本，支持单个对象、数组和 @graph
+func applyJSONLD(raw string, metadata *models.PageMetadata, wordCount *int) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &data); err != nil {
+		return
+	}
+
+	var nodes []map[string]interface{}
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch x := v.(type) {
+		case []interface{}:
+			for _, item := range x {
+				collect(item)
+			}
+		case map[string]interface{}:
+			if graph, ok := x["@graph"]; ok {
+				collect(graph)
+			}
+			if _, ok := x["@type"]; ok {
+				nodes = append(nodes, x)
+			}
+		}
+	}
+	collect(data)
+
+	// 先处理能确定内容类型的主体（文章、视频等），再用 WebSite/WebPage 等补充站点名和语言
+	for _, primary := range []bool{true, false} {
+		for _, node := range nodes {
+			contentType := ""
+			for _, t := range jsonLDTypes(node["@type"]) {
+				if ct, ok := schemaContentTypes[strings.ToLower(t)]; ok {
+					contentType = ct
+					break
+				}
+			}
+			if (contentType != "") != primary {
+				continue
+			}
+
+			if primary {
+				if metadata.ContentType == "" {
+					metadata.ContentType = contentType
+				}
+				if metadata.Author == "" {
+					metadata.Author = jsonLDNames(node["author"])
+				}
+				if metadata.PublishedAt == nil {
+					metadata.PublishedAt = parseMetadataDate(firstNonEmpty(jsonLDString(node["datePublished"]), jsonLDString(node["uploadDate"]), jsonLDString(node["dateCreated"])))
+				}
+				if metadata.ReadingTime == 0 {
+					metadata.ReadingTime = parseISODurationMinutes(jsonLDString(node["timeRequired"]))
+				}
+				if *wordCount == 0 {
+					*wordCount, _ = strconv.Atoi(jsonLDString(node["wordCount"]))
+				}
+				if metadata.SiteName == "" {
+					metadata.SiteName = jsonLDNames(node["publisher"])
+				}
+			} else if metadata.SiteName == "" && containsFold(jsonLDTypes(node["@type"]), "WebSite") {
+				metadata.SiteName = jsonLDString(node["name"])
+			}
+			if metadata.Language == "" {
+				metadata.Language = normalizeLanguage(jsonLDString(node["inLanguage"]))
+			}
+		}
+	}
+}
+
+// jsonLDTypes @type 可以是字符串或字符串数组
+func jsonLDTypes(v interface{}) []string {
+	switch x := v.(type) {
+	case string:
+		return []string{x}
+	case []interface{}:
+		types := []string{}
+		for _, item := range x {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// jsonLDString 读取字符串或数字值；对象取 @value / name，数组取第一个元素
+func jsonLDString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case map[string]interface{}:
+		return firstNonEmpty(jsonLDString(x["@value"]), jsonLDString(x["name"]))
+	case []interface{}:
+		if len(x) > 0 {
+			return jsonLDString(x[0])
+		}
+	}
+	return ""
+}
+
+// jsonLDNames 读取人名或机构名，可以是字符串、{name} 对象或它们的数组，多个名称以逗号分隔
+func jsonLDNames(v interface{}) string {
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+	names := []string{}
+	for _, item := range items {
+		if name := jsonLDString(item); name != "" && !strings.HasPrefix(name, "http") {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyMicrodata 读取 schema.org 微数据（itemscope/itemtype/itemprop）
+func applyMicrodata(doc *html.Node, metadata *models.PageMetadata) {
+	walkElements(doc, func(n *html.Node) bool {
+		if itemType := getAttr(n, "itemtype"); itemType != "" && metadata.ContentType == "" {
+			name := itemType[strings.LastIndex(itemType, "/")+1:]
+			metadata.ContentType = schemaContentTypes[strings.ToLower(name)]
+		}
+
+		switch getAttr(n, "itemprop") {
+		case "author", "creator":
+			if metadata.Author == "" {
+				metadata.Author = microdataName(n)
+			}
+			return false
+		case "publisher":
+			if metadata.SiteName == "" {
+				metadata.SiteName = microdataName(n)
+			}
+			return false
+		case "datePublished", "uploadDate":
+			if metadata.PublishedAt == nil {
+				metadata.PublishedAt = parseMetadataDate(microdataValue(n))
+			}
+		case "inLanguage":
+			if metadata.Language == "" {
+				metadata.Language = normalizeLanguage(microdataValue(n))
+			}
+		case "timeRequired":
+			if metadata.ReadingTime == 0 {
+				metadata.ReadingTime = parseISODurationMinutes(microdataValue(n))
+			}
+		}
+		return true
+	})
+}
+
+// microdataValue 属性值: content / datetime 属性，否则为元素文字
+func microdataValue(n *html.Node) string {
+	return firstNonEmpty(getAttr(n, "content"), getAttr(n, "datetime"), collapseSpaces(textContent(n)))
+}
+
+// microdataName 嵌套条目取 itemprop=name，否则取元素文字
+func microdataName(n *html.Node) string {
+	if _, scoped := findAttr(n, "itemscope"); scoped {
+		name := ""
+		walkElements(n, func(c *html.Node) bool {
+			if name == "" && getAttr(c, "itemprop") == "name" {
+				name = microdataValue(c)
+			}
+			return name == ""
+		})
+		return name
+	}
+	value := microdataValue(n)
+	if len([]rune(value)) > 100 {
+		return ""
+	}
+	return value
+}
+
+// applyMetaTags 读取 OpenGraph article 标签、citation_* 学术标签和常见的 meta 标签
+func applyMetaTags(meta map[string][]string, base *neturl.URL, metadata *models.PageMetadata) {
+	first := func(key string) string {
+		if values := meta[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if metadata.ContentType == "" {
+		metadata.ContentType = ogContentType(first("og:type"))
+		if metadata.ContentType == "" && first("citation_title") != "" {
+			metadata.ContentType = models.ContentTypePaper
+		}
+	}
+	if metadata.Author == "" {
+		authors := []string{}
+		for _, key := range []string{"citation_author", "article:author", "author", "twitter:creator"} {
+			for _, v := range meta[key] {
+				if !strings.HasPrefix(v, "http") {
+					authors = append(authors, v)
+				}
+			}
+			if len(authors) > 0 {
+				break
+			}
+		}
+		metadata.Author = strings.Join(authors, ", ")
+	}
+	if metadata.PublishedAt == nil {
+		for _, key := range publishedMetaNames {
+			if t := parseMetadataDate(first(key)); t != nil {
+				metadata.PublishedAt = t
+				break
+			}
+		}
+	}
+	if metadata.SiteName == "" {
+		metadata.SiteName = firstNonEmpty(first("og:site_name"), first("application-name"), first("citation_journal_title"))
+	}
+	if metadata.CanonicalURL == "" {
+		metadata.CanonicalURL = resolveURL(base, first("og:url"))
+	}
+	if metadata.Language == "" {
+		metadata.Language = normalizeLanguage(firstNonEmpty(first("content-language"), first("og:locale"), first("citation_language")))
+	}
+}
+
+// ogContentType og:type 对应的内容类型
+func ogContentType(ogType string) string {
+	ogType = strings.ToLower(ogType)
+	switch {
+	case ogType == "article":
+		return models.ContentTypeArticle
+	case strings.HasPrefix(ogType, "video"):
+		return models.ContentTypeVideo
+	case strings.HasPrefix(ogType, "music"):
+		return models.ContentTypeAudio
+	case ogType == "book" || strings.HasPrefix(ogType, "books."):
+		return models.ContentTypeBook
+	case ogType == "product" || strings.HasPrefix(ogType, "product."):
+		return models.ContentTypeProduct
+	}
+	return ""
+}
+
+// oembedResponse oEmbed 响应中用到的字段
+type oembedResponse struct {
+	Type         string `json:"type"` // photo | video | link | rich
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+// applyOEmbed 请求页面声明的 oEmbed 地址，补充作者、站点名、内容类型和预览图
+func (s *ScraperService) applyOEmbed(ctx context.Context, oembedURL string, metadata *models.PageMetadata) {
+	res, err := s.fetch(ctx, oembedURL, oembedMaxSize)
+	if err != nil {
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.ContentType); !strings.Contains(mediaType, "json") {
+		return
+	}
+	var oembed oembedResponse
+	if err := json.Unmarshal(res.Body, &oembed); err != nil {
+		return
+	}
+
+	if metadata.Author == "" {
+		metadata.Author = strings.TrimSpace(oembed.AuthorName)
+	}
+	if metadata.SiteName == "" {
+		metadata.SiteName = strings.TrimSpace(oembed.ProviderName)
+	}
+	if metadata.ContentType == "" {
+		switch oembed.Type {
+		case "video":
+			metadata.ContentType = models.ContentTypeVideo
+		case "photo":
+			metadata.ContentType = models.ContentTypeImage
+		}
+	}
+	if metadata.Image == "" {
+		metadata.Image = resolveURL(res.URL, oembed.ThumbnailURL)
+	}
+}
+
+// parseMetadataDate 解析发布时间，无法解析时返回 nil
+func parseMetadataDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range metadataDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+// parseISODurationMinutes 将 ISO 8601 时长转换为分钟数（向上取整），无法解析时返回 0
+func parseISODurationMinutes(value string) int {
+	return int(math.Ceil(parseISODurationSeconds(value) / 60))
+}
+
+// parseISODurationSeconds 将 ISO 8601 时长转换为秒数，无法解析时返回 0
+func parseISODurationSeconds(value string) float64 {
+	m := isoDurationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if m == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(m[1])
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	seconds, _ := strconv.ParseFloat(m[4], 64)
+	return float64((days*24+hours)*3600+minutes*60) + seconds
+}
+
+// readingMinutes 按字数估算阅读分钟数，至少 1 分钟；没有字数时返回 0
+func readingMinutes(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return (words + readingWordsPerMinute - 1) / readingWordsPerMinute
+}
+
+// normalizeLanguage 规范化语言代码: zh_CN → zh-CN，en-us → en-US
+func normalizeLanguage(lang string) string {
+	lang = strings.TrimSpace(strings.SplitN(lang, ",", 2)[0])
+	parts := strings.Split(strings.ReplaceAll(lang, "_", "-"), "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 {
+		return ""
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// hasToken 空格分隔的属性值中是否包含 token
+func hasToken(value, token string) bool {
+	for _, f := range strings.Fields(value) {
+		if f == token {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold 字符串列表中是否包含 s（不区分大小写）
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-bookmark-service/models"
+)
+
+func TestApplyJSONLD(t *testing.T) {
+	published := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		raw           string
+		want          models.PageMetadata
+		wantWordCount int
+	}{
+		{
+			name:          "单个对象，作者为对象",
+			raw:           `{"@context":"https://schema.org","@type":"NewsArticle","author":{"@type":"Person","name":"张三"},"datePublished":"2024-03-05T16:00:00+08:00","publisher":{"@type":"Organization","name":"示例新闻"},"inLanguage":"zh_cn","wordCount":"1200"}`,
+			want:          models.PageMetadata{ContentType: models.ContentTypeArticle, Author: "张三", PublishedAt: &published, SiteName: "示例新闻", Language: "zh-CN"},
+			wantWordCount: 1200,
+		},
+		{
+			name: "作者为数组，跳过主页地址",
+			raw:  `{"@type":"BlogPosting","author":[{"name":"Alice"},"Bob","https://example.com/carol"],"timeRequired":"PT7M30S"}`,
+			want: models.PageMetadata{ContentType: models.ContentTypeArticle, Author: "Alice, Bob", ReadingTime: 8},
+		},
+		{
+			name: "作者为字符串",
+			raw:  `{"@type":"Book","author":"鲁迅","dateCreated":"1923-08-01"}`,
+			want: models.PageMetadata{ContentType: models.ContentTypeBook, Author: "鲁迅", PublishedAt: timePtr(time.Date(1923, 8, 1, 0, 0, 0, 0, time.UTC))},
+		},
+		{
+			name: "@type 为数组",
+			raw:  `{"@type":["CreativeWork","VideoObject"],"uploadDate":"2024-03-05T08:00:00Z","author":{"@type":"Person","name":"UP 主"}}`,
+			want: models.PageMetadata{ContentType: models.ContentTypeVideo, Author: "UP 主", PublishedAt: &published},
+		},
+		{
+			name: "@graph 中主体优先于 WebSite，WebSite 补充站点名",
+			raw: `{"@context":"https://schema.org","@graph":[
+				{"@type":"WebSite","name":"示例站点","inLanguage":"en-us"},
+				{"@type":"WebPage","name":"页面"},
+				{"@type":"ScholarlyArticle","author":[{"@type":"Person","name":"A. Author"}],"datePublished":"2024-03-05"}
+			]}`,
+			want: models.PageMetadata{ContentType: models.ContentTypePaper, Author: "A. Author", PublishedAt: timePtr(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)), SiteName: "示例站点", Language: "en-US"},
+		},
+		{
+			name: "顶层数组",
+			raw:  `[{"@type":"WebSite","name":"站点"},{"@type":"Product","name":"商品"}]`,
+			want: models.PageMetadata{ContentType: models.ContentTypeProduct, SiteName: "站点"},
+		},
+		{
+			name: "未知类型不提取作者",
+			raw:  `{"@type":"Event","author":"不应使用","name":"活动"}`,
+			want: models.PageMetadata{},
+		},
+		{
+			name: "无效 JSON",
+			raw:  `{"@type":"Article",`,
+			want: models.PageMetadata{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var metadata models.PageMetadata
+			wordCount := 0
+			applyJSONLD(tc.raw, &metadata, &wordCount)
+			if !equalMetadata(&metadata, &tc.want) {
+				t.Errorf("结果 = %s\n期望 %s", formatMetadata(&metadata), formatMetadata(&tc.want))
+			}
+			if wordCount != tc.wantWordCount {
+				t.Errorf("wordCount = %d，期望 %d", wordCount, tc.wantWordCount)
+			}
+		})
+	}
+
+	// 已有的字段不被覆盖
+	metadata := models.PageMetadata{Author: "已有作者", ContentType: models.ContentTypeVideo}
+	wordCount := 0
+	applyJSONLD(`{"@type":"Article","author":"新作者"}`, &metadata, &wordCount)
+	if metadata.Author != "已有作者" || metadata.ContentType != models.ContentTypeVideo {
+		t.Errorf("已有字段被覆盖: %s", formatMetadata(&metadata))
+	}
+}
+
+func TestParseISODurationSeconds(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantSeconds float64
+		wantMinutes int
+	}{
+		{"PT1H5M", 3900, 65},
+		{"PT12M30S", 750, 13},
+		{"P0DT12M30S", 750, 13},
+		{"P1D", 86400, 1440},
+		{"P1DT2H", 93600, 1560},
+		{"PT45S", 45, 1},
+		{"PT1.5S", 1.5, 1},
+		{"pt3m", 180, 3},
+		{" PT3M ", 180, 3},
+		{"PT0S", 0, 0},
+		{"", 0, 0},
+		{"P1Y", 0, 0},
+		{"3:33", 0, 0},
+		{"PT", 0, 0},
+	}
+	for _, tc := range tests {
+		if got := parseISODurationSeconds(tc.value); got != tc.wantSeconds {
+			t.Errorf("parseISODurationSeconds(%q) = %v，期望 %v", tc.value, got, tc.wantSeconds)
+		}
+		if got := parseISODurationMinutes(tc.value); got != tc.wantMinutes {
+			t.Errorf("parseISODurationMinutes(%q) = %d，期望 %d", tc.value, got, tc.wantMinutes)
+		}
+	}
+}
+
+func TestParseMetadataDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time // 零值表示无法解析
+	}{
+		{"2024-03-05T16:30:00+08:00", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"2024-03-05T08:30:00Z", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"2024-03-05T16:30:00+0800", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"2024-03-05T08:30:15", time.Date(2024, 3, 5, 8, 30, 15, 0, time.UTC)},
+		{"2024-03-05T08:30", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"2024-03-05 08:30:15", time.Date(2024, 3, 5, 8, 30, 15, 0, time.UTC)},
+		{" 2024-03-05 ", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"2024/03/05", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"2024/3/5", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"Tue, 05 Mar 2024 16:30:00 +0800", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"Tue, 05 Mar 2024 08:30:00 GMT", time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)},
+		{"", time.Time{}},
+		{"昨天", time.Time{}},
+		{"2024-13-05", time.Time{}},
+	}
+	for _, tc := range tests {
+		got := parseMetadataDate(tc.value)
+		switch {
+		case tc.want.IsZero() && got != nil:
+			t.Errorf("parseMetadataDate(%q) = %v，期望 nil", tc.value, got)
+		case !tc.want.IsZero() && (got == nil || !got.Equal(tc.want) || got.Location() != time.UTC):
+			t.Errorf("parseMetadataDate(%q) = %v，期望 %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"zh_CN":           "zh-CN",
+		"zh-cn":           "zh-CN",
+		"EN-us":           "en-US",
+		"en":              "en",
+		"zh-Hans-CN":      "zh-Hans-CN",
+		"fil":             "fil",
+		" ja ":            "ja",
+		"de-DE, en;q=0.8": "de-DE",
+		"":                "",
+		"x":               "",
+		"english":         "",
+		"en-419":          "en-419",
+	}
+	for lang, want := range tests {
+		if got := normalizeLanguage(lang); got != want {
+			t.Errorf("normalizeLanguage(%q) = %q，期望 %q", lang, got, want)
+		}
+	}
+}
+
+func TestApplyOEmbed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/video", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json+oembed; charset=utf-8")
+		fmt.Fprint(w, `{"type":"video","author_name":" 作者 ","provider_name":"视频网站","thumbnail_url":"/thumb.jpg"}`)
+	})
+	mux.HandleFunc("/photo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type":"photo","author_name":"摄影师"}`)
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `{"type":"video","author_name":"不应使用"}`)
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type":`)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	s := newTestScraper(2)
+
+	tests := []struct {
+		path     string
+		existing models.PageMetadata
+		want     models.PageMetadata
+	}{
+		{"/video", models.PageMetadata{}, models.PageMetadata{Author: "作者", SiteName: "视频网站", ContentType: models.ContentTypeVideo, Image: srv.URL + "/thumb.jpg"}},
+		{"/photo", models.PageMetadata{}, models.PageMetadata{Author: "摄影师", ContentType: models.ContentTypeImage}},
+		{"/video", models.PageMetadata{Author: "页面作者", ContentType: models.ContentTypeArticle, Image: "https://img.example/a.png"}, models.PageMetadata{Author: "页面作者", SiteName: "视频网站", ContentType: models.ContentTypeArticle, Image: "https://img.example/a.png"}},
+		{"/html", models.PageMetadata{}, models.PageMetadata{}},
+		{"/invalid", models.PageMetadata{}, models.PageMetadata{}},
+		{"/missing", models.PageMetadata{}, models.PageMetadata{}},
+	}
+	for _, tc := range tests {
+		metadata := tc.existing
+		s.applyOEmbed(context.Background(), srv.URL+tc.path, &metadata)
+		if !equalMetadata(&metadata, &tc.want) {
+			t.Errorf("%s: 结果 = %s\n期望 %s", tc.path, formatMetadata(&metadata), formatMetadata(&tc.want))
+		}
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// equalMetadata 比较结构化元数据字段
+func equalMetadata(a, b *models.PageMetadata) bool {
+	sameTime := (a.PublishedAt == nil) == (b.PublishedAt == nil) && (a.PublishedAt == nil || a.PublishedAt.Equal(*b.PublishedAt))
+	return sameTime && a.Author == b.Author && a.SiteName == b.SiteName && a.ContentType == b.ContentType &&
+		a.Language == b.Language && a.ReadingTime == b.ReadingTime && a.CanonicalURL == b.CanonicalURL && a.Image == b.Image
+}
+
+// formatMetadata 输出结构化元数据字段，用于错误信息
+func formatMetadata(m *models.PageMetadata) string {
+	return fmt.Sprintf("{Author:%q SiteName:%q ContentType:%q Language:%q ReadingTime:%d PublishedAt:%v CanonicalURL:%q Image:%q}",
+		m.Author, m.SiteName, m.ContentType, m.Language, m.ReadingTime, m.PublishedAt, m.CanonicalURL, m.Image)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// TrashService 回收站服务: 列出、恢复、彻底删除书签，以及按保留期自动清除
+type TrashService struct {
+	bookmarkRepo *db.BookmarkRepository
+	assetService *AssetService
+	retention    time.Duration // 0 表示不自动清除
+	stopChan     chan struct{}
+}
+
+// NewTrashService 创建回收站服务，retentionDays 为回收站保留天数
+func NewTrashService(bookmarkRepo *db.BookmarkRepository, assetService *AssetService, retentionDays int) *TrashService {
+	return &TrashService{
+		bookmarkRepo: bookmarkRepo,
+		assetService: assetService,
+		retention:    time.Duration(retentionDays) * 24 * time.Hour,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// List 分页列出回收站中的书签，返回书签和总数
+func (s *TrashService) List(limit, offset int, filters map[string]interface{}) ([]*models.Bookmark, int, error) {
+	filters["trashed"] = true
+	bookmarks, err := s.bookmarkRepo.List(limit, offset, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.bookmarkRepo.Count(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bookmarks, total, nil
+}
+
+// Restore 将书签移出回收站
+func (s *TrashService) Restore(id int) (*models.Bookmark, error) {
+	if err := s.bookmarkRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	log.Printf("♻️ 书签已从回收站恢复: ID=%d", id)
+	return s.bookmarkRepo.GetByID(id)
+}
+
+// Purge 彻底删除回收站中的书签及其附件文件
+func (s *TrashService) Purge(id int) error {
+	assets, err := s.assetService.List(id)
+	if err != nil {
+		return err
+	}
+	if err := s.bookmarkRepo.Purge(id); err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		s.assetService.RemoveFile(asset)
+	}
+	return nil
+}
+
+// PurgeBefore 彻底删除删除时间早于 before 的书签，before 为零值时清空回收站，返回删除数量
+func (s *TrashService) PurgeBefore(before time.Time) (int, error) {
+	ids, err := s.bookmarkRepo.ListTrashedIDs(before)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := s.Purge(id); err != nil {
+			log.Printf("⚠️ 清除回收站书签失败: ID=%d, 错误: %v", id, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Start 启动后台定时清除，每小时清除超过保留期的书签
+func (s *TrashService) Start() {
+	if s.retention <= 0 {
+		log.Printf("ℹ️ 回收站自动清除已关闭")
+		return
+	}
+	log.Printf("🗑️ 回收站自动清除启动: 保留 %d 天", int(s.retention.Hours()/24))
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			s.purgeExpired()
+			select {
+			case <-ticker.C:
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台定时清除
+func (s *TrashService) Stop() {
+	close(s.stopChan)
+}
+
+// purgeExpired 清除超过保留期的书签
+func (s *TrashService) purgeExpired() {
+	purged, err := s.PurgeBefore(time.Now().Add(-s.retention))
+	if err != nil {
+		log.Printf("⚠️ 回收站自动清除失败: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("🗑️ 回收站自动清除: %d 个书签", purged)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"ai-bookmark-service/db"
+)
+
+const (
+	// webArchiveMaxAttempts 单个书签提交到外部存档服务的最大尝试次数
+	webArchiveMaxAttempts = 5
+	// webArchiveRetryDelay 首次重试的等待时间，之后每次翻倍
+	webArchiveRetryDelay = time.Minute
+	// webArchiveTimeout 单次提交（含等待存档完成）的超时时间
+	webArchiveTimeout = 3 * time.Minute
+)
+
+// webArchiveJob 存档提交任务
+type webArchiveJob struct {
+	bookmarkID int
+	attempt    int
+}
+
+// WebArchiver 后台将书签提交到外部存档服务，并把快照地址写入 web_archive_snapshot_url
+// 提交失败时按指数退避重试
+type WebArchiver struct {
+	bookmarkRepo *db.BookmarkRepository
+	provider     ArchiveProvider
+	queue        chan webArchiveJob
+	wg           sync.WaitGroup
+	mu           sync.Mutex
+	started      bool
+	retryTimers  map[int]*time.Timer // 等待重试的书签
+	retryDelay   time.Duration       // 首次重试的等待时间
+}
+
+// NewWebArchiver 创建外部存档服务，provider 为 nil 时不提交
+func NewWebArchiver(bookmarkRepo *db.BookmarkRepository, provider ArchiveProvider) *WebArchiver {
+	return &WebArchiver{
+		bookmarkRepo: bookmarkRepo,
+		provider:     provider,
+		queue:        make(chan webArchiveJob, 1000),
+		retryTimers:  make(map[int]*time.Timer),
+		retryDelay:   webArchiveRetryDelay,
+	}
+}
+
+// Enabled 是否配置了外部存档服务
+func (a *WebArchiver) Enabled() bool {
+	return a.provider != nil
+}
+
+// Start 启动后台 worker，外部存档服务通常有频率限制，只使用一个 worker
+func (a *WebArchiver) Start() {
+	if a.provider == nil {
+		log.Printf("ℹ️ 外部存档服务未配置")
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started {
+		return
+	}
+	a.started = true
+	a.wg.Add(1)
+	go a.worker()
+	log.Printf("🏛️ 外部存档服务启动: %s", a.provider.Name())
+}
+
+// Stop 停止后台 worker，取消等待中的重试
+func (a *WebArchiver) Stop() {
+	a.mu.Lock()
+	if !a.started {
+		a.mu.Unlock()
+		return
+	}
+	a.started = false
+	for id, timer := range a.retryTimers {
+		timer.Stop()
+		delete(a.retryTimers, id)
+	}
+	close(a.queue)
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// Submit 提交书签，返回是否成功入队
+func (a *WebArchiver) Submit(bookmarkID int) bool {
+	return a.enqueue(webArchiveJob{bookmarkID: bookmarkID})
+}
+
+func (a *WebArchiver) enqueue(job webArchiveJob) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.started {
+		return false
+	}
+	select {
+	case a.queue <- job:
+		return true
+	default:
+		log.Printf("⚠️ 外部存档队列已满，忽略书签 ID: %d", job.bookmarkID)
+		return false
+	}
+}
+
+func (a *WebArchiver) worker() {
+	defer a.wg.Done()
+	for job := range a.queue {
+		err := a.archive(job.bookmarkID)
+		if err == nil {
+			continue
+		}
+
+		job.attempt++
+		if job.attempt >= webArchiveMaxAttempts {
+			log.Printf("❌ 提交外部存档失败 ID=%d，已放弃（%d 次）: %v", job.bookmarkID, job.attempt, err)
+			continue
+		}
+		delay := a.retryDelay << (job.attempt - 1)
+		log.Printf("⚠️ 提交外部存档失败 ID=%d，%v 后重试: %v", job.bookmarkID, delay, err)
+		a.scheduleRetry(job, delay)
+	}
+}
+
+// scheduleRetry 延迟后重新入队
+func (a *WebArchiver) scheduleRetry(job webArchiveJob, delay time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.started {
+		return
+	}
+	a.retryTimers[job.bookmarkID] = time.AfterFunc(delay, func() {
+		a.mu.Lock()
+		delete(a.retryTimers, job.bookmarkID)
+		a.mu.Unlock()
+		a.enqueue(job)
+	})
+}
+
+// archive 提交单个书签并保存快照地址，书签已有快照地址时跳过
+func (a *WebArchiver) archive(bookmarkID int) error {
+	bm, err := a.bookmarkRepo.GetByID(bookmarkID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bm.WebArchiveSnapshotURL != "" || bm.DateDeleted != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webArchiveTimeout)
+	defer cancel()
+
+	snapshotURL, err := a.provider.Submit(ctx, bm.URL)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🏛️ 外部存档完成: ID=%d, %s", bookmarkID, snapshotURL)
+	return a.bookmarkRepo.UpdateWebArchiveSnapshotURL(bookmarkID, snapshotURL)
+}
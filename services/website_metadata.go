@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"ai-bookmark-service/db"
+	"ai-bookmark-service/models"
+)
+
+// WebsiteMetadataLoader 后台抓取书签的网站元数据（标题、描述、favicon、预览图和作者、发布时间等结构化元数据）
+type WebsiteMetadataLoader struct {
+	bookmarkRepo *db.BookmarkRepository
+	scraper      *ScraperService
+	media        *MediaCache
+	defaultTags  bool // 是否添加特定网站提取器建议的默认标签
+	queue        chan int
+	workerCount  int
+	wg           sync.WaitGroup
+	started      bool
+}
+
+// NewWebsiteMetadataLoader 创建网站元数据加载器
+// media 不为 nil 时图标和预览图会缓存到本地，并保存本地地址
+func NewWebsiteMetadataLoader(bookmarkRepo *db.BookmarkRepository, scraper *ScraperService, media *MediaCache, defaultTags bool, workerCount int) *WebsiteMetadataLoader {
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	return &WebsiteMetadataLoader{
+		bookmarkRepo: bookmarkRepo,
+		scraper:      scraper,
+		media:        media,
+		defaultTags:  defaultTags,
+		queue:        make(chan int, 1000),
+		workerCount:  workerCount,
+	}
+}
+
+// Start 启动后台 worker
+func (l *WebsiteMetadataLoader) Start() {
+	if l.started {
+		return
+	}
+	l.started = true
+	for i := 0; i < l.workerCount; i++ {
+		l.wg.Add(1)
+		go l.worker()
+	}
+	log.Printf("🌐 网站元数据加载器启动: %d workers", l.workerCount)
+}
+
+// Stop 停止后台 worker，等待进行中的任务完成
+func (l *WebsiteMetadataLoader) Stop() {
+	if !l.started {
+		return
+	}
+	close(l.queue)
+	l.wg.Wait()
+}
+
+// Submit 提交书签，返回是否成功入队
+func (l *WebsiteMetadataLoader) Submit(bookmarkID int) bool {
+	if !l.started {
+		return false
+	}
+	select {
+	case l.queue <- bookmarkID:
+		return true
+	default:
+		log.Printf("⚠️ 网站元数据队列已满，忽略书签 ID: %d", bookmarkID)
+		return false
+	}
+}
+
+func (l *WebsiteMetadataLoader) worker() {
+	defer l.wg.Done()
+	for id := range l.queue {
+		if err := l.Load(id); err != nil {
+			log.Printf("⚠️ 加载网站元数据失败 ID=%d: %v", id, err)
+		}
+	}
+}
+
+// Load 抓取并保存单个书签的网站元数据
+func (l *WebsiteMetadataLoader) Load(bookmarkID int) error {
+	bm, err := l.bookmarkRepo.GetActiveByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := l.scraper.ScrapeWebPage(bm.URL)
+	if err != nil {
+		return err
+	}
+
+	title := metadata.OGTitle
+	if title == "" {
+		title = metadata.Title
+	}
+	description := metadata.OGDesc
+	if description == "" {
+		description = metadata.Description
+	}
+
+	favicon := l.cacheMedia(models.MediaKindFavicon, metadata.Favicon)
+	previewImage := l.cacheMedia(models.MediaKindPreview, metadata.Image)
+
+	if err := l.bookmarkRepo.UpdateWebsiteMetadata(bookmarkID, title, description, favicon, previewImage); err != nil {
+		return err
+	}
+	if err := l.bookmarkRepo.UpdateStructuredMetadata(bookmarkID, metadata); err != nil {
+		return err
+	}
+
+	// 特定网站的默认标签（如 github、论文、视频），已有的标签不会重复添加
+	if l.defaultTags && len(metadata.Tags) > 0 {
+		if _, err := l.bookmarkRepo.WithSource(models.RevisionSourceMetadata).Patch(bookmarkID, &models.BookmarkPatch{AddTags: metadata.Tags}); err != nil {
+			return fmt.Errorf("添加默认标签失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// cacheMedia 缓存图片并返回本地地址，缓存失败时返回原地址
+func (l *WebsiteMetadataLoader) cacheMedia(kind, sourceURL string) string {
+	if l.media == nil || sourceURL == "" {
+		return sourceURL
+	}
+	local, err := l.media.Cache(kind, sourceURL)
+	if err != nil {
+		log.Printf("⚠️ 缓存图片失败: %s, 错误: %v", sourceURL, err)
+		return sourceURL
+	}
+	return local
+}
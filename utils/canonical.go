@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// CanonicalRule 单个域名的规范化规则，作用于该域名及其子域名
+type CanonicalRule struct {
+	KeepParams        []string `json:"keep_params"`         // 非空时只保留这些查询参数
+	StripParams       []string `json:"strip_params"`        // 在通用跟踪参数之外额外去除的查询参数
+	DropQuery         bool     `json:"drop_query"`          // 去除全部查询参数
+	KeepFragment      bool     `json:"keep_fragment"`       // 保留 #fragment（如使用 hash 路由的单页应用）
+	KeepTrailingSlash bool     `json:"keep_trailing_slash"` // 保留路径末尾的斜杠
+	StripWWW          bool     `json:"strip_www"`           // 去除 www. 前缀
+}
+
+// trackingParams 通用跟踪参数
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "gclsrc": true, "dclid": true, "msclkid": true,
+	"yclid": true, "twclid": true, "ttclid": true, "igshid": true, "li_fat_id": true,
+	"mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true, "mkt_tok": true,
+	"_ga": true, "_gl": true, "spm": true, "scm": true, "ref_src": true, "ref_url": true,
+	"vero_id": true, "wickedid": true, "oly_anon_id": true, "oly_enc_id": true,
+}
+
+// trackingParamPrefixes 以这些前缀开头的参数均视为跟踪参数
+var trackingParamPrefixes = []string{"utm_"}
+
+var (
+	canonicalRulesMu sync.RWMutex
+	canonicalRules   = map[string]CanonicalRule{
+		"youtube.com":  {KeepParams: []string{"v", "list", "t"}},
+		"youtu.be":     {KeepParams: []string{"t"}},
+		"amazon.com":   {DropQuery: true},
+		"twitter.com":  {StripParams: []string{"s", "t"}},
+		"x.com":        {StripParams: []string{"s", "t"}},
+		"bilibili.com": {StripParams: []string{"spm_id_from", "vd_source", "from_spmid", "share_source", "share_medium", "share_plat", "share_session_id", "share_tag", "unique_k", "up_id"}},
+	}
+)
+
+// RegisterCanonicalRule 注册（或覆盖）域名的规范化规则
+func RegisterCanonicalRule(domain string, rule CanonicalRule) {
+	canonicalRulesMu.Lock()
+	defer canonicalRulesMu.Unlock()
+	canonicalRules[strings.ToLower(strings.TrimPrefix(domain, "www."))] = rule
+}
+
+// LoadCanonicalRules 从 JSON 注册域名规则（CANONICAL_RULES 配置），格式为 {"域名": 规则}，
+// 如 {"example.com": {"keep_params": ["id"]}}；与内置规则同名的域名覆盖内置规则
+func LoadCanonicalRules(data string) error {
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var rules map[string]CanonicalRule
+	if err := decoder.Decode(&rules); err != nil {
+		return fmt.Errorf("规范化规则格式错误: %w", err)
+	}
+	for domain, rule := range rules {
+		domain = strings.TrimSpace(domain)
+		if domain == "" || strings.ContainsAny(domain, "/:?#") {
+			return fmt.Errorf("规范化规则的域名无效: %q", domain)
+		}
+		RegisterCanonicalRule(domain, rule)
+	}
+	return nil
+}
+
+// canonicalRuleFor 查找主机名适用的规则，子域名继承父域名的规则
+func canonicalRuleFor(host string) CanonicalRule {
+	canonicalRulesMu.RLock()
+	defer canonicalRulesMu.RUnlock()
+	for h := host; h != ""; {
+		if rule, ok := canonicalRules[h]; ok {
+			return rule
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return CanonicalRule{}
+}
+
+// CanonicalizeURL 生成用于去重的规范 URL:
+// 主机名小写、去除默认端口、去除跟踪参数和 #fragment、查询参数排序、去除路径末尾斜杠，并应用域名规则
+func CanonicalizeURL(rawURL string) (string, error) {
+	normalized, err := NormalizeURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	rule := canonicalRuleFor(strings.TrimPrefix(host, "www."))
+	if rule.StripWWW {
+		host = strings.TrimPrefix(host, "www.")
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if !rule.KeepFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	if !rule.KeepTrailingSlash && len(u.Path) > 1 {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	u.RawQuery = canonicalQuery(u.Query(), rule)
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+// canonicalQuery 按规则过滤查询参数，结果按参数名排序
+func canonicalQuery(query url.Values, rule CanonicalRule) string {
+	if rule.DropQuery {
+		return ""
+	}
+
+	keep := map[string]bool{}
+	for _, p := range rule.KeepParams {
+		keep[p] = true
+	}
+	strip := map[string]bool{}
+	for _, p := range rule.StripParams {
+		strip[p] = true
+	}
+
+	for key := range query {
+		lower := strings.ToLower(key)
+		if len(keep) > 0 && !keep[key] {
+			query.Del(key)
+			continue
+		}
+		if strip[key] || trackingParams[lower] || hasTrackingPrefix(lower) {
+			query.Del(key)
+		}
+	}
+	return query.Encode()
+}
+
+// hasTrackingPrefix 参数名是否以跟踪参数前缀开头
+func hasTrackingPrefix(key string) bool {
+	for _, prefix := range trackingParamPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
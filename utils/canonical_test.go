@@ -0,0 +1,99 @@
+package utils
+
+import "testing"
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"示例", "https://Example.com/a/?utm_source=x#top", "https://example.com/a"},
+		{"主机名小写", "https://WWW.Example.COM/Path", "https://www.example.com/Path"},
+		{"去除 https 默认端口", "https://example.com:443/a", "https://example.com/a"},
+		{"去除 http 默认端口", "http://example.com:80/a", "http://example.com/a"},
+		{"保留非默认端口", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"http 的 443 端口不是默认端口", "http://example.com:443/a", "http://example.com:443/a"},
+		{"IPv6 主机", "http://[::1]:80/a", "http://[::1]/a"},
+		{"去除 utm_*", "https://example.com/a?utm_source=x&utm_medium=y&UTM_Campaign=z&id=1", "https://example.com/a?id=1"},
+		{"去除 fbclid", "https://example.com/a?fbclid=abc", "https://example.com/a"},
+		{"去除 gclid", "https://example.com/a?gclid=abc&q=go", "https://example.com/a?q=go"},
+		{"去除 spm", "https://item.example.cn/a?spm=a1.b2&id=9", "https://item.example.cn/a?id=9"},
+		{"查询参数排序", "https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"空查询", "https://example.com/a?", "https://example.com/a"},
+		{"去除 fragment", "https://example.com/a#section-2", "https://example.com/a"},
+		{"去除末尾斜杠", "https://example.com/a/b/", "https://example.com/a/b"},
+		{"去除多个末尾斜杠", "https://example.com/a//", "https://example.com/a"},
+		{"根路径", "https://example.com", "https://example.com/"},
+		{"根路径带斜杠", "https://example.com/?utm_source=x#top", "https://example.com/"},
+		{"YouTube 只保留视频参数", "https://www.youtube.com/watch?v=abc&feature=share&si=x", "https://www.youtube.com/watch?v=abc"},
+		{"YouTube 子域名继承规则", "https://m.youtube.com/watch?v=abc&t=10&pp=x", "https://m.youtube.com/watch?t=10&v=abc"},
+		{"Amazon 去除全部参数", "https://www.amazon.com/dp/B000?ref_=x&th=1", "https://www.amazon.com/dp/B000"},
+		{"Twitter 额外去除 s 和 t", "https://x.com/user/status/1?s=20&t=abc&lang=en", "https://x.com/user/status/1?lang=en"},
+		{"Bilibili 去除分享参数", "https://www.bilibili.com/video/BV1xx/?spm_id_from=333&vd_source=abc&p=2", "https://www.bilibili.com/video/BV1xx?p=2"},
+		{"规则不匹配相似域名", "https://notyoutube.com/watch?v=abc&feature=share", "https://notyoutube.com/watch?feature=share&v=abc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeURL(tc.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("CanonicalizeURL(%q) = %q，期望 %q", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeURLEquivalence(t *testing.T) {
+	same := []string{
+		"https://Example.com/a/?utm_source=x#top",
+		"https://example.com/a",
+		"https://example.com:443/a/",
+		"https://EXAMPLE.com/a?fbclid=1&gclid=2",
+	}
+	want, err := CanonicalizeURL(same[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range same[1:] {
+		if got, err := CanonicalizeURL(raw); err != nil || got != want {
+			t.Errorf("CanonicalizeURL(%q) = %q，期望与 %q 相同（%q）", raw, got, same[0], want)
+		}
+	}
+	for _, raw := range []string{"http://example.com/a", "https://example.com/A", "https://example.com/a?id=1", "https://www.example.com/a"} {
+		if got, _ := CanonicalizeURL(raw); got == want {
+			t.Errorf("CanonicalizeURL(%q) 不应与 %q 相同", raw, same[0])
+		}
+	}
+}
+
+func TestLoadCanonicalRules(t *testing.T) {
+	err := LoadCanonicalRules(`{"rules.example": {"keep_params": ["id"]}, "www.hash.example": {"keep_fragment": true}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for raw, want := range map[string]string{
+		"https://rules.example/item?id=1&page=2":   "https://rules.example/item?id=1",
+		"https://sub.rules.example/item?id=1&x=y":  "https://sub.rules.example/item?id=1",
+		"https://www.hash.example/#/route?a=1":     "https://www.hash.example/#/route?a=1",
+		"https://other.example/item?id=1&page=2#x": "https://other.example/item?id=1&page=2",
+	} {
+		got, err := CanonicalizeURL(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("CanonicalizeURL(%q) = %q，期望 %q", raw, got, want)
+		}
+	}
+
+	for _, invalid := range []string{`{"a.example": {"unknown": true}}`, `{"": {}}`, `{"https://a.example/": {}}`, `[1]`} {
+		if err := LoadCanonicalRules(invalid); err == nil {
+			t.Errorf("LoadCanonicalRules(%s) 应返回错误", invalid)
+		}
+	}
+}
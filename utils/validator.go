@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"ai-bookmark-service/models"
 )
@@ -54,6 +55,64 @@ func ValidateBookmarkCreate(bm *models.BookmarkCreate) error {
 	return nil
 }
 
+// ValidateBookmarkPatch 验证书签部分更新请求，只检查请求中提供的字段
+func ValidateBookmarkPatch(p *models.BookmarkPatch) error {
+	if p.URL != nil {
+		normalizedURL, err := NormalizeURL(*p.URL)
+		if err != nil {
+			return fmt.Errorf("无效的URL: %s", *p.URL)
+		}
+		p.URL = &normalizedURL
+	}
+
+	if p.Title != nil && len(*p.Title) > 200 {
+		return fmt.Errorf("标题过长（最多200字符）")
+	}
+
+	if p.Description != nil && len(*p.Description) > 1000 {
+		return fmt.Errorf("描述过长（最多1000字符）")
+	}
+
+	if p.Notes != nil && len(*p.Notes) > 2000 {
+		return fmt.Errorf("笔记过长（最多2000字符）")
+	}
+
+	if p.TagNames != nil {
+		if len(*p.TagNames) > 50 {
+			return fmt.Errorf("标签过多（最多50个）")
+		}
+		cleaned, err := cleanTagNames(*p.TagNames)
+		if err != nil {
+			return err
+		}
+		p.TagNames = &cleaned
+	}
+
+	var err error
+	if p.AddTags, err = cleanTagNames(p.AddTags); err != nil {
+		return err
+	}
+	if p.RemoveTags, err = cleanTagNames(p.RemoveTags); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// cleanTagNames 清理标签首尾空格并去掉空标签
+func cleanTagNames(tags []string) ([]string, error) {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if len(tag) > 100 {
+			return nil, fmt.Errorf("标签名过长: %s（最多100字符）", tag)
+		}
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return cleaned, nil
+}
+
 // ValidateFolderCreate 验证文件夹创建请求
 func ValidateFolderCreate(folder *models.FolderCreate) error {
 	if folder.Name == "" {
@@ -72,6 +131,64 @@ func ValidateFolderCreate(folder *models.FolderCreate) error {
 	return nil
 }
 
+// scraperProfileDomainPattern 抓取配置的域名格式
+var scraperProfileDomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// headerNamePattern 请求头名称（RFC 7230 token）
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// ValidateScraperProfile 验证抓取配置，并规范化域名（允许填写完整网址或 *.域名）
+func ValidateScraperProfile(p *models.ScraperProfile) error {
+	domain := strings.ToLower(strings.TrimSpace(p.Domain))
+	if strings.Contains(domain, "://") {
+		if u, err := url.Parse(domain); err == nil {
+			domain = u.Hostname()
+		}
+	}
+	domain = strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".")
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+	if len(domain) > 253 || !scraperProfileDomainPattern.MatchString(domain) {
+		return fmt.Errorf("无效的域名: %s", p.Domain)
+	}
+	p.Domain = domain
+
+	if len(p.Headers) > 50 {
+		return fmt.Errorf("请求头过多（最多50个）")
+	}
+	for name, value := range p.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("无效的请求头名称: %s", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("请求头 %s 的值不能包含换行", name)
+		}
+	}
+	if strings.ContainsAny(p.Cookies, "\r\n") {
+		return fmt.Errorf("Cookie 不能包含换行")
+	}
+
+	p.ProxyURL = strings.TrimSpace(p.ProxyURL)
+	if p.ProxyURL != "" {
+		u, err := url.Parse(p.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("无效的代理地址: %s", p.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("不支持的代理协议: %s（仅支持 http、https、socks5）", u.Scheme)
+		}
+	}
+
+	if p.Timeout < 0 || p.Timeout > 300 {
+		return fmt.Errorf("超时时间必须在 0-300 秒之间")
+	}
+
+	return nil
+}
+
 // NormalizeURL 规范化URL，自动添加协议前缀
 func NormalizeURL(urlStr string) (string, error) {
 	// 去除首尾空格
@@ -114,3 +231,15 @@ func isValidColor(color string) bool {
 	matched, _ := regexp.MatchString(`^#[0-9A-Fa-f]{6}$`, color)
 	return matched
 }
+
+// TruncateText 把超过 maxBytes 字节的文本截断并以 "..." 结尾，不会切断多字节字符
+func TruncateText(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes - len("...")
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}